@@ -0,0 +1,71 @@
+// Command boardimport imports a Trello JSON or Jira CSV export into an existing project.
+//
+//	go run ./backend/cmd/boardimport -format trello -project 10 -file board.json -dry-run
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/nais2008/hackanet2025/backend/pkg/boardimport"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+)
+
+func main() {
+	format := flag.String("format", "", "формат выгрузки: "+strings.Join(boardimport.Formats(), ", "))
+	projectID := flag.Int("project", 0, "ID проекта, в который импортируются задачи")
+	file := flag.String("file", "", "путь к файлу выгрузки")
+	dryRun := flag.Bool("dry-run", false, "только проверить импорт и вывести отчёт")
+	workspaceID := flag.Int("workspace", 0, "рабочее пространство проекта")
+	userID := flag.Int("user", 0, "пользователь, от имени которого пишется история")
+	flag.Parse()
+
+	if *format == "" || *projectID <= 0 || *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// .env необязателен: переменные могут быть заданы окружением
+	_ = godotenv.Load()
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Не удалось открыть файл выгрузки: %v", err)
+	}
+	defer f.Close()
+
+	board, err := boardimport.Parse(*format, f)
+	if err != nil {
+		log.Fatalf("Не удалось разобрать выгрузку: %v", err)
+	}
+
+	ctx := context.Background()
+	database, err := db.New(ctx)
+	if err != nil {
+		log.Fatalf("Не удалось инициализировать базу данных: %v", err)
+	}
+	defer database.Close()
+
+	if *workspaceID > 0 {
+		ctx = db.WithWorkspace(ctx, *workspaceID)
+	}
+	if *userID > 0 {
+		ctx = db.WithActor(ctx, *userID)
+	}
+
+	report, err := database.ImportBoard(ctx, *projectID, board, *dryRun)
+	if err != nil {
+		log.Fatalf("Импорт не выполнен: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatalf("Не удалось вывести отчёт: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// Activity handlers
+func (api *API) getProjectActivity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, id)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := db.ActivityFilter{}
+	if v := query.Get("actor_id"); v != "" {
+		if filter.ActorID, err = strconv.Atoi(v); err != nil {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid actor_id"))
+			return
+		}
+	}
+	if v := query.Get("type"); v != "" {
+		filter.Types = strings.Split(v, ",")
+	}
+	if v := query.Get("before"); v != "" {
+		if filter.Before, err = strconv.ParseInt(v, 10, 64); err != nil {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid before"))
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid limit"))
+			return
+		}
+	}
+	if query.Get("since_last_visit") == "true" {
+		filter.SinceLastVisit = true
+		filter.UserID = userID
+	}
+
+	entries, err := api.db.GetProjectActivity(r.Context(), id, filter)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	response := struct {
+		Items      []projectmodel.Activity `json:"items"`
+		NextBefore *int64                  `json:"next_before"`
+	}{Items: entries}
+	if len(entries) > 0 {
+		response.NextBefore = &entries[len(entries)-1].ID
+	}
+	api.sendSuccess(w, http.StatusOK, response)
+}
+
+func (api *API) getActivityVisit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, id)
+	if !ok {
+		return
+	}
+
+	visit, err := api.db.GetActivityVisit(r.Context(), userID, id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, visit)
+}
+
+func (api *API) markActivitySeen(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, id)
+	if !ok {
+		return
+	}
+
+	if err := api.db.MarkActivitySeen(r.Context(), userID, id); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "activity marked as seen"})
+}
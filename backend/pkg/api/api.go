@@ -1,16 +1,18 @@
 package api
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
 	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
 	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
 	usermodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/user_model"
@@ -18,9 +20,10 @@ import (
 )
 
 type API struct {
-	r       *mux.Router
-	db      *db.DB
-	usersDB *usersdb.DB
+	r         *mux.Router
+	db        *db.DB
+	usersDB   *usersdb.DB
+	mediaRoot string
 }
 
 func New(db *db.DB, usersDB *usersdb.DB) *API {
@@ -28,11 +31,18 @@ func New(db *db.DB, usersDB *usersdb.DB) *API {
 		return nil
 	}
 
+	mediaRoot := os.Getenv("MEDIA_ROOT")
+	if mediaRoot == "" {
+		mediaRoot = filepath.Join(".", "media")
+	}
+
 	api := &API{
-		db:      db,
-		usersDB: usersDB,
-		r:       mux.NewRouter(),
+		db:        db,
+		usersDB:   usersDB,
+		r:         mux.NewRouter(),
+		mediaRoot: mediaRoot,
 	}
+	api.r.Use(api.actorMiddleware, api.workspaceMiddleware)
 	api.setupEndpoints()
 	return api
 }
@@ -44,15 +54,136 @@ func (api *API) Router() *mux.Router {
 func (api *API) setupEndpoints() {
 	// Project endpoints
 	api.r.HandleFunc("/projects", api.createProject).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/import", api.importProject).Methods(http.MethodPost)
 	api.r.HandleFunc("/projects/{id}", api.getProject).Methods(http.MethodGet)
 	api.r.HandleFunc("/projects/{id}", api.updateProject).Methods(http.MethodPut)
 	api.r.HandleFunc("/projects/{id}", api.deleteProject).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{id}/key", api.renameProjectKey).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{id}/settings", api.getProjectSettings).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{id}/settings", api.updateProjectSettings).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{id}/clone", api.cloneProject).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{id}/export", api.exportProject).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{id}/import/{format}", api.importBoard).Methods(http.MethodPost)
+
+	// Stage endpoints
+	api.r.HandleFunc("/projects/{id}/stages", api.getStages).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{id}/stages", api.createStage).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{id}/stages/order", api.reorderStages).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{id}/stages/{stageId}", api.updateStage).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{id}/stages/{stageId}", api.deleteStage).Methods(http.MethodDelete)
+
+	// Label endpoints
+	api.r.HandleFunc("/projects/{id}/labels", api.getLabels).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{id}/labels", api.createLabel).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{id}/labels/attach", api.attachLabels).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{id}/labels/detach", api.detachLabels).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{id}/labels/{labelId}", api.updateLabel).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{id}/labels/{labelId}", api.deleteLabel).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{id}/labels/{labelId}/merge", api.mergeLabels).Methods(http.MethodPost)
+
+	// Stats endpoints
+	api.r.HandleFunc("/projects/{id}/stats", api.getProjectStats).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{id}/time-report", api.getProjectTimeReport).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{id}/sprints", api.getSprints).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{id}/sprints", api.createSprint).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{id}/velocity", api.getVelocity).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{projectId}/sprints/{sprintId}", api.getSprint).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{projectId}/sprints/{sprintId}", api.updateSprint).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{projectId}/sprints/{sprintId}", api.deleteSprint).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{projectId}/sprints/{sprintId}/start", api.startSprint).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/sprints/{sprintId}/complete", api.completeSprint).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/sprints/{sprintId}/burndown", api.getBurndown).Methods(http.MethodGet)
+
+	// Activity endpoints
+	api.r.HandleFunc("/projects/{id}/activity", api.getProjectActivity).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{id}/activity/visit", api.getActivityVisit).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{id}/activity/visit", api.markActivitySeen).Methods(http.MethodPost)
+
+	// Project member endpoints
+	api.r.HandleFunc("/projects/{id}/members", api.getProjectMembers).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{id}/members", api.setProjectMember).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{id}/members/{userId}", api.removeProjectMember).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{id}/transfer", api.getOwnershipTransfer).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{id}/transfer", api.requestOwnershipTransfer).Methods(http.MethodPost)
+	api.r.HandleFunc("/transfers/{id}/accept", api.acceptOwnershipTransfer).Methods(http.MethodPost)
+	api.r.HandleFunc("/transfers/{id}/decline", api.declineOwnershipTransfer).Methods(http.MethodPost)
+
+	// Favorite and recent project endpoints
+	api.r.HandleFunc("/favorites", api.getFavoriteProjects).Methods(http.MethodGet)
+	api.r.HandleFunc("/favorites", api.addFavoriteProject).Methods(http.MethodPost)
+	api.r.HandleFunc("/favorites/order", api.reorderFavoriteProjects).Methods(http.MethodPut)
+	api.r.HandleFunc("/favorites/{projectId}", api.removeFavoriteProject).Methods(http.MethodDelete)
+	api.r.HandleFunc("/recent", api.getRecentProjects).Methods(http.MethodGet)
+
+	// Notification endpoints
+	api.r.HandleFunc("/notifications", api.getNotifications).Methods(http.MethodGet)
+	api.r.HandleFunc("/notifications/read", api.readNotifications).Methods(http.MethodPost)
+	api.r.HandleFunc("/timer", api.getTimer).Methods(http.MethodGet)
+	api.r.HandleFunc("/timer", api.cancelTimer).Methods(http.MethodDelete)
+	api.r.HandleFunc("/timer/stop", api.stopTimer).Methods(http.MethodPost)
+	api.r.HandleFunc("/time-report", api.getTimeReport).Methods(http.MethodGet)
+	api.r.HandleFunc("/search", api.search).Methods(http.MethodGet)
+
+	// Template endpoints
+	api.r.HandleFunc("/templates", api.getTemplates).Methods(http.MethodGet)
+	api.r.HandleFunc("/templates", api.createTemplate).Methods(http.MethodPost)
+	api.r.HandleFunc("/templates/{id}", api.deleteTemplate).Methods(http.MethodDelete)
+
+	// Workspace endpoints
+	api.r.HandleFunc("/workspaces", api.createWorkspace).Methods(http.MethodPost)
+	api.r.HandleFunc("/workspaces", api.getWorkspaces).Methods(http.MethodGet)
+	api.r.HandleFunc("/workspaces/{id}", api.getWorkspace).Methods(http.MethodGet)
+	api.r.HandleFunc("/workspaces/{id}", api.updateWorkspace).Methods(http.MethodPut)
+	api.r.HandleFunc("/workspaces/{id}", api.deleteWorkspace).Methods(http.MethodDelete)
+	api.r.HandleFunc("/workspaces/{id}/members", api.getWorkspaceMembers).Methods(http.MethodGet)
+	api.r.HandleFunc("/workspaces/{id}/members", api.setWorkspaceMember).Methods(http.MethodPost)
+	api.r.HandleFunc("/workspaces/{id}/members/{userId}", api.removeWorkspaceMember).Methods(http.MethodDelete)
 
 	// Task endpoints
+	api.r.HandleFunc("/projects/{projectId}/tasks", api.getTasks).Methods(http.MethodGet)
 	api.r.HandleFunc("/projects/{projectId}/tasks", api.createTask).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/tasks/export", api.exportTasks).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{projectId}/tasks/bulk", api.bulkUpdateTasks).Methods(http.MethodPost)
 	api.r.HandleFunc("/projects/{projectId}/tasks/{id}", api.getTask).Methods(http.MethodGet)
 	api.r.HandleFunc("/projects/{projectId}/tasks/{id}", api.updateTask).Methods(http.MethodPut)
 	api.r.HandleFunc("/projects/{projectId}/tasks/{id}", api.deleteTask).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/stage", api.moveTaskToStage).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/subtasks", api.getSubtasks).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/parent", api.setTaskParent).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/checklist", api.getChecklist).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/checklist", api.addChecklistItem).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/checklist/order", api.reorderChecklist).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/checklist/{itemId}", api.updateChecklistItem).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/checklist/{itemId}", api.deleteChecklistItem).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/links", api.getTaskLinks).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/links", api.addTaskLink).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/links/{linkId}", api.deleteTaskLink).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/comments", api.getTaskComments).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/comments", api.addTaskComment).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/comments/{commentId}", api.editTaskComment).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/comments/{commentId}", api.deleteTaskComment).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/comments/{commentId}/history", api.getCommentHistory).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/comments/{commentId}/reactions", api.addCommentReaction).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/comments/{commentId}/reactions/{emoji}", api.removeCommentReaction).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/worklogs", api.getWorklogs).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/worklogs", api.addWorklog).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/worklogs/{worklogId}", api.updateWorklog).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/worklogs/{worklogId}", api.deleteWorklog).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/timer", api.startTimer).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/recurrence", api.getRecurrence).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/recurrence", api.setRecurrence).Methods(http.MethodPut)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/recurrence", api.deleteRecurrence).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/recurrence/occurrences", api.getOccurrences).Methods(http.MethodGet)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/recurrence/exceptions", api.addOccurrenceException).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/recurrence/exceptions/{exceptionId}", api.deleteOccurrenceException).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/assignees", api.assignTask).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/assignees/{userId}", api.unassignTask).Methods(http.MethodDelete)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/watch", api.watchTask).Methods(http.MethodPost)
+	api.r.HandleFunc("/projects/{projectId}/tasks/{id}/watch", api.unwatchTask).Methods(http.MethodDelete)
+	api.r.HandleFunc("/tasks/{ref}", api.getTaskByRef).Methods(http.MethodGet)
+	api.r.HandleFunc("/tasks/{id}/move", api.moveTask).Methods(http.MethodPost)
+	api.r.HandleFunc("/tasks/{id}/history", api.getTaskHistory).Methods(http.MethodGet)
+	api.r.HandleFunc("/tasks/{id}/history/{changeId}/restore", api.restoreTaskHistory).Methods(http.MethodPost)
 
 	// User endpoints
 	api.r.HandleFunc("/users", api.createUser).Methods(http.MethodPost)
@@ -95,11 +226,43 @@ func (api *API) sendSuccess(w http.ResponseWriter, status int, data interface{})
 	json.NewEncoder(w).Encode(data)
 }
 
+// errorStatus maps repository errors to HTTP status codes
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrNotOwner), errors.Is(err, db.ErrNotTransferParty), errors.Is(err, db.ErrNoTaskAccess),
+		errors.Is(err, db.ErrNotCommentAuthor), errors.Is(err, db.ErrNotWorklogOwner):
+		return http.StatusForbidden
+	case errors.Is(err, db.ErrTransferPending), errors.Is(err, db.ErrTransferResolved):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrKeyTaken), errors.Is(err, db.ErrWIPLimit),
+		errors.Is(err, db.ErrStageNotEmpty), errors.Is(err, db.ErrLastStage), errors.Is(err, db.ErrLabelExists),
+		errors.Is(err, db.ErrHasSubtasks), errors.Is(err, db.ErrLinkExists), errors.Is(err, db.ErrLinkCycle),
+		errors.Is(err, db.ErrTaskBlocked), errors.Is(err, db.ErrTimerRunning), errors.Is(err, db.ErrSprintState),
+		errors.Is(err, db.ErrSprintActive), errors.Is(err, db.ErrLastAdmin):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrNotTemplate), errors.Is(err, db.ErrOwnerRole), errors.Is(err, db.ErrInvalidKey),
+		errors.Is(err, db.ErrStageMismatch), errors.Is(err, db.ErrNeighbor), errors.Is(err, db.ErrInvalidTask),
+		errors.Is(err, db.ErrInvalidLabel), errors.Is(err, db.ErrNotMember), errors.Is(err, db.ErrInvalidSettings),
+		errors.Is(err, db.ErrSubtaskDepth), errors.Is(err, db.ErrSubtaskCycle), errors.Is(err, db.ErrInvalidLink),
+		errors.Is(err, db.ErrInvalidComment), errors.Is(err, db.ErrNotRestorable), errors.Is(err, db.ErrInvalidWorklog),
+		errors.Is(err, db.ErrInvalidReport), errors.Is(err, db.ErrInvalidSprint),
+		errors.Is(err, db.ErrInvalidRecurrence), errors.Is(err, db.ErrInvalidBulk),
+		errors.Is(err, db.ErrInvalidSearch):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // Project handlers
 func (api *API) createProject(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Title  string `json:"title"`
-		UserID int    `json:"user_id"`
+		Title      string `json:"title"`
+		Key        string `json:"key"`
+		UserID     int    `json:"user_id"`
+		TemplateID int    `json:"template_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
@@ -115,9 +278,22 @@ func (api *API) createProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := api.db.CreateProject(r.Context(), input.Title, input.UserID)
+	if input.TemplateID > 0 {
+		if _, ok := api.requireProjectRole(w, r, input.TemplateID); !ok {
+			return
+		}
+		id, err := api.db.CreateProjectFromTemplate(r.Context(), input.TemplateID, input.Title, input.UserID)
+		if err != nil {
+			api.sendError(w, errorStatus(err), err)
+			return
+		}
+		api.sendSuccess(w, http.StatusCreated, map[string]int{"id": id})
+		return
+	}
+
+	id, err := api.db.CreateProjectWithKey(r.Context(), input.Title, input.Key, input.UserID)
 	if err != nil {
-		api.sendError(w, http.StatusInternalServerError, err)
+		api.sendError(w, errorStatus(err), err)
 		return
 	}
 
@@ -145,6 +321,12 @@ func (api *API) getProject(w http.ResponseWriter, r *http.Request) {
 	}
 	project.Tasks = tasks
 
+	if userID, ok := currentUserID(r); ok {
+		if err := api.db.RecordProjectView(r.Context(), userID, id); err != nil {
+			log.Printf("Error recording project view: %v", err)
+		}
+	}
+
 	api.sendSuccess(w, http.StatusOK, project)
 }
 
@@ -155,6 +337,9 @@ func (api *API) updateProject(w http.ResponseWriter, r *http.Request) {
 		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
 		return
 	}
+	if _, ok := api.requireProjectRole(w, r, id, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
 
 	var input struct {
 		Title string `json:"title"`
@@ -184,6 +369,9 @@ func (api *API) deleteProject(w http.ResponseWriter, r *http.Request) {
 		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
 		return
 	}
+	if _, ok := api.requireProjectRole(w, r, id, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
 
 	if err := api.db.DeleteProject(r.Context(), id); err != nil {
 		api.sendError(w, http.StatusInternalServerError, err)
@@ -200,17 +388,44 @@ func (api *API) createTask(w http.ResponseWriter, r *http.Request) {
 		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
 		return
 	}
-	body, _ := io.ReadAll(r.Body)
-	r.Body = io.NopCloser(bytes.NewReader(body))
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
 
-	var input projectmodel.Task
+	var input struct {
+		Title           string     `json:"title"`
+		Description     string     `json:"description"`
+		FullDescription string     `json:"full_description"`
+		Status          string     `json:"status"`
+		Priority        string     `json:"priority"`
+		StartDate       *time.Time `json:"start_date"`
+		Deadline        *time.Time `json:"deadline"`
+		StageID         *int       `json:"stage_id"`
+		ParentID        *int       `json:"parent_id"`
+		AssigneeIDs     []int      `json:"assignee_ids"`
+		StoryPoints     *int       `json:"story_points"`
+		SprintID        *int       `json:"sprint_id"`
+	}
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Title == "" {
 		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request or title missing"))
 		return
 	}
-	id, err := api.db.CreateTask(r.Context(), projectID, input.Title, input.Description, input.Full_description)
+	id, err := api.db.CreateTaskFromInput(r.Context(), projectID, db.TaskInput{
+		Title:           input.Title,
+		Description:     input.Description,
+		FullDescription: input.FullDescription,
+		Status:          input.Status,
+		Priority:        input.Priority,
+		StartDate:       input.StartDate,
+		Deadline:        input.Deadline,
+		StageID:         input.StageID,
+		ParentID:        input.ParentID,
+		AssigneeIDs:     input.AssigneeIDs,
+		StoryPoints:     input.StoryPoints,
+		SprintID:        input.SprintID,
+	})
 	if err != nil {
-		api.sendError(w, http.StatusInternalServerError, err)
+		api.sendError(w, errorStatus(err), err)
 		return
 	}
 	api.sendSuccess(w, http.StatusCreated, map[string]int{"id": id})
@@ -222,50 +437,90 @@ func (api *API) getTasks(w http.ResponseWriter, r *http.Request) {
 		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
 		return
 	}
-	tasks, err := api.db.GetTasksByProjectID(r.Context(), projectID)
+	filter, err := parseTaskFilter(r)
 	if err != nil {
-		api.sendError(w, http.StatusInternalServerError, err)
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	tasks, err := api.db.GetTasks(r.Context(), projectID, filter)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
 		return
 	}
 	api.sendSuccess(w, http.StatusOK, tasks)
 }
 
-func (api *API) getTask(w http.ResponseWriter, r *http.Request) {
+// taskVars parses the project and task IDs of /projects/{projectId}/tasks/{id} routes
+func (api *API) taskVars(w http.ResponseWriter, r *http.Request) (projectID, id int, ok bool) {
 	vars := mux.Vars(r)
-	projectID, _ := strconv.Atoi(vars["projectId"])
-	taskName := vars["name"]
-	task, err := api.db.GetTaskByTitleAndProjectID(r.Context(), projectID, taskName)
+	projectID, err := strconv.Atoi(vars["projectId"])
 	if err != nil {
-		api.sendError(w, http.StatusNotFound, err)
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return 0, 0, false
+	}
+	id, err = strconv.Atoi(vars["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return 0, 0, false
+	}
+	return projectID, id, true
+}
+
+func (api *API) getTask(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	task, err := api.db.GetProjectTask(r.Context(), projectID, id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
 		return
 	}
 	api.sendSuccess(w, http.StatusOK, task)
 }
 
 func (api *API) updateTask(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectID, _ := strconv.Atoi(vars["projectId"])
-	taskName := vars["name"]
-	body, _ := io.ReadAll(r.Body)
-	r.Body = io.NopCloser(bytes.NewReader(body))
-	var input projectmodel.Task
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Title == "" {
-		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body or title missing"))
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
 		return
 	}
-	if err := api.db.UpdateTask(r.Context(), projectID, taskName, input.Title, input.Description, input.Full_description); err != nil {
-		api.sendError(w, http.StatusInternalServerError, err)
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
 		return
 	}
-	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "task updated"})
+	var patch db.TaskPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if err := api.db.PatchTask(r.Context(), projectID, id, patch); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	task, err := api.db.GetProjectTask(r.Context(), projectID, id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendSuccess(w, http.StatusOK, task)
 }
 
 func (api *API) deleteTask(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectID, _ := strconv.Atoi(vars["projectId"])
-	taskName := vars["name"]
-	if err := api.db.DeleteTask(r.Context(), projectID, taskName); err != nil {
-		api.sendError(w, http.StatusInternalServerError, err)
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	mode := db.SubtaskMode(r.URL.Query().Get("subtasks"))
+	if err := api.db.DeleteTaskWithSubtasks(r.Context(), projectID, id, mode); err != nil {
+		api.sendError(w, errorStatus(err), err)
 		return
 	}
 	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "task deleted"})
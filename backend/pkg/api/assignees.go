@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Task assignee and watcher handlers
+func (api *API) assignTask(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	var input struct {
+		UserIDs []int `json:"user_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || len(input.UserIDs) == 0 {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body or user_ids missing"))
+		return
+	}
+
+	if err := api.db.AssignTask(r.Context(), projectID, id, input.UserIDs); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendTask(w, r, projectID, id)
+}
+
+func (api *API) unassignTask(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.Atoi(mux.Vars(r)["userId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+
+	if err := api.db.UnassignTask(r.Context(), projectID, id, []int{userID}); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendTask(w, r, projectID, id)
+}
+
+func (api *API) watchTask(w http.ResponseWriter, r *http.Request) {
+	api.setWatching(w, r, true)
+}
+
+func (api *API) unwatchTask(w http.ResponseWriter, r *http.Request) {
+	api.setWatching(w, r, false)
+}
+
+// setWatching subscribes or unsubscribes the requester to a task
+func (api *API) setWatching(w http.ResponseWriter, r *http.Request, watch bool) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, projectID)
+	if !ok {
+		return
+	}
+
+	var err error
+	if watch {
+		err = api.db.WatchTask(r.Context(), projectID, id, userID)
+	} else {
+		err = api.db.UnwatchTask(r.Context(), projectID, id, userID)
+	}
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendTask(w, r, projectID, id)
+}
+
+// sendTask responds with the current state of a project task
+func (api *API) sendTask(w http.ResponseWriter, r *http.Request, projectID, id int) {
+	task, err := api.db.GetProjectTask(r.Context(), projectID, id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendSuccess(w, http.StatusOK, task)
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/nais2008/hackanet2025/backend/pkg/boardimport"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// Board import handlers
+func (api *API) importBoard(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	board, err := boardimport.Parse(vars["format"], http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := api.db.ImportBoard(r.Context(), projectID, board, dryRun)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	api.sendSuccess(w, status, report)
+}
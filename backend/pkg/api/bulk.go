@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+)
+
+// bulkUpdateTasks applies operations to the listed tasks or to the tasks matching a filter
+func (api *API) bulkUpdateTasks(w http.ResponseWriter, r *http.Request) {
+	projectID, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	var input struct {
+		TaskIDs []int `json:"task_ids"`
+		// Filter takes the same parameters as the task list, e.g. {"stage_id": "3", "priority": "low"}
+		Filter     map[string]string  `json:"filter"`
+		Operations []db.BulkOperation `json:"operations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if (len(input.TaskIDs) > 0) == (input.Filter != nil) {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("either task_ids or filter is required"))
+		return
+	}
+
+	taskIDs := input.TaskIDs
+	if input.Filter != nil {
+		query := url.Values{}
+		for key, value := range input.Filter {
+			query.Set(key, value)
+		}
+		fr := r.Clone(r.Context())
+		fr.URL.RawQuery = query.Encode()
+		filter, err := parseTaskFilter(fr)
+		if err != nil {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid filter: %w", err))
+			return
+		}
+		tasks, err := api.db.GetTasks(r.Context(), projectID, filter)
+		if err != nil {
+			api.sendError(w, errorStatus(err), err)
+			return
+		}
+		if len(tasks) == 0 {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("no tasks match the filter"))
+			return
+		}
+		for _, task := range tasks {
+			id, err := strconv.Atoi(task.ID)
+			if err != nil {
+				api.sendError(w, http.StatusInternalServerError, err)
+				return
+			}
+			taskIDs = append(taskIDs, id)
+		}
+	}
+
+	result, err := api.db.BulkUpdateTasks(r.Context(), projectID, taskIDs, input.Operations, dryRun)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	status := http.StatusOK
+	if !dryRun && !result.Applied {
+		status = http.StatusConflict
+	}
+	api.sendSuccess(w, status, result)
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	"github.com/nais2008/hackanet2025/backend/pkg/markdown"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// commentVars parses the IDs of /projects/{projectId}/tasks/{id}/comments/{commentId} routes
+func (api *API) commentVars(w http.ResponseWriter, r *http.Request) (projectID, id, commentID int, ok bool) {
+	projectID, id, ok = api.taskVars(w, r)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	commentID, err := strconv.Atoi(mux.Vars(r)["commentId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid comment ID"))
+		return 0, 0, 0, false
+	}
+	return projectID, id, commentID, true
+}
+
+// maxMentions limits the @mentions of one comment that are looked up, so a comment cannot trigger unbounded queries
+const maxMentions = 20
+
+// commentInput decodes a comment body and resolves its first maxMentions @mentions to users; unknown usernames are skipped
+func (api *API) commentInput(w http.ResponseWriter, r *http.Request) (db.CommentInput, bool) {
+	var input struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return db.CommentInput{}, false
+	}
+	in := db.CommentInput{Body: input.Body}
+	mentions := markdown.Mentions(input.Body)
+	for _, username := range mentions[:min(len(mentions), maxMentions)] {
+		u, err := api.usersDB.GetUserByUsername(r.Context(), username)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			api.sendError(w, http.StatusInternalServerError, err)
+			return db.CommentInput{}, false
+		}
+		in.MentionIDs = append(in.MentionIDs, u.ID)
+	}
+	return in, true
+}
+
+// sendComment responds with the current state of a comment
+func (api *API) sendComment(w http.ResponseWriter, r *http.Request, status, id, commentID int) {
+	comment, err := api.db.GetTaskComment(r.Context(), id, commentID)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendSuccess(w, status, comment)
+}
+
+// Task comment handlers
+func (api *API) getTaskComments(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := api.projectTask(w, r)
+	if !ok {
+		return
+	}
+
+	comments, err := api.db.GetTaskComments(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, comments)
+}
+
+func (api *API) addTaskComment(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, projectID)
+	if !ok {
+		return
+	}
+	in, ok := api.commentInput(w, r)
+	if !ok {
+		return
+	}
+
+	commentID, err := api.db.AddTaskComment(r.Context(), projectID, id, userID, in)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendComment(w, r, http.StatusCreated, id, commentID)
+}
+
+func (api *API) editTaskComment(w http.ResponseWriter, r *http.Request) {
+	projectID, id, commentID, ok := api.commentVars(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, projectID)
+	if !ok {
+		return
+	}
+	in, ok := api.commentInput(w, r)
+	if !ok {
+		return
+	}
+
+	if err := api.db.EditTaskComment(r.Context(), projectID, id, commentID, userID, in); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendComment(w, r, http.StatusOK, id, commentID)
+}
+
+func (api *API) deleteTaskComment(w http.ResponseWriter, r *http.Request) {
+	projectID, id, commentID, ok := api.commentVars(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, projectID)
+	if !ok {
+		return
+	}
+	role, err := api.db.ProjectRole(r.Context(), projectID, userID)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	moderate := role == projectmodel.RoleOwner || role == projectmodel.RoleMaintainer
+
+	if err := api.db.DeleteTaskComment(r.Context(), projectID, id, commentID, userID, moderate); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "comment deleted"})
+}
+
+func (api *API) getCommentHistory(w http.ResponseWriter, r *http.Request) {
+	projectID, id, commentID, ok := api.commentVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	if _, err := api.db.GetProjectTask(r.Context(), projectID, id); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	revisions, err := api.db.GetCommentHistory(r.Context(), id, commentID)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, revisions)
+}
+
+func (api *API) addCommentReaction(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Emoji string `json:"emoji"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	api.setCommentReaction(w, r, input.Emoji, true)
+}
+
+func (api *API) removeCommentReaction(w http.ResponseWriter, r *http.Request) {
+	api.setCommentReaction(w, r, mux.Vars(r)["emoji"], false)
+}
+
+// setCommentReaction adds or removes the requester's reaction and responds with the comment
+func (api *API) setCommentReaction(w http.ResponseWriter, r *http.Request, emoji string, on bool) {
+	projectID, id, commentID, ok := api.commentVars(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, projectID)
+	if !ok {
+		return
+	}
+
+	if err := api.db.SetCommentReaction(r.Context(), projectID, id, commentID, userID, emoji, on); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendComment(w, r, http.StatusOK, id, commentID)
+}
+
+// Notification handlers
+func (api *API) getNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+	query := r.URL.Query()
+	unread := query.Get("unread") == "true"
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	notifications, err := api.db.GetNotifications(r.Context(), userID, unread, limit)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, notifications)
+}
+
+func (api *API) readNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+	var input struct {
+		IDs []int64 `json:"ids"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+	}
+
+	n, err := api.db.MarkNotificationsRead(r.Context(), userID, input.IDs)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]int{"read": n})
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/nais2008/hackanet2025/backend/pkg/archive"
+)
+
+// maxImportSize limits the size of an uploaded project archive
+const maxImportSize = 200 << 20
+
+// Export and import handlers
+func (api *API) exportProject(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id); !ok {
+		return
+	}
+
+	exp, err := api.db.ExportProject(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%d.zip"`, id))
+	if err := archive.Write(w, exp, api.mediaRoot); err != nil {
+		// Заголовки уже отправлены, остаётся только залогировать ошибку
+		log.Printf("Error writing project archive: %v", err)
+	}
+}
+
+func (api *API) importProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	arc, err := archive.Read(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rename := func(projectID int, name string) string {
+		return path.Join("imports", strconv.Itoa(projectID), name)
+	}
+	report, err := api.db.ImportProject(r.Context(), &arc.Manifest, userID, rename, dryRun)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+	for _, name := range arc.Missing() {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("file %q is not included in the archive", name))
+	}
+
+	if !dryRun && report.ProjectID > 0 {
+		if err := arc.Extract(api.mediaRoot, path.Join("imports", strconv.Itoa(report.ProjectID))); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to store files: %v", err))
+		}
+	}
+
+	switch {
+	case dryRun:
+		api.sendSuccess(w, http.StatusOK, report)
+	case report.ProjectID == 0:
+		api.sendSuccess(w, http.StatusUnprocessableEntity, report)
+	default:
+		api.sendSuccess(w, http.StatusCreated, report)
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Favorite and recent project handlers
+func (api *API) getFavoriteProjects(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+
+	projects, err := api.db.GetFavoriteProjects(r.Context(), userID)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, projects)
+}
+
+func (api *API) addFavoriteProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+
+	var input struct {
+		ProjectID int `json:"project_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if input.ProjectID <= 0 {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("valid project_id is required"))
+		return
+	}
+
+	if err := api.db.AddFavoriteProject(r.Context(), userID, input.ProjectID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusCreated, map[string]string{"message": "project added to favorites"})
+}
+
+func (api *API) removeFavoriteProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+	projectID, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	if err := api.db.RemoveFavoriteProject(r.Context(), userID, projectID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "project removed from favorites"})
+}
+
+func (api *API) reorderFavoriteProjects(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+
+	var input struct {
+		ProjectIDs []int `json:"project_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if err := api.db.ReorderFavoriteProjects(r.Context(), userID, input.ProjectIDs); err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "favorites reordered"})
+}
+
+func (api *API) getRecentProjects(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	projects, err := api.db.GetRecentProjects(r.Context(), userID, limit)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, projects)
+}
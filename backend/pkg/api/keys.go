@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// Project key handlers
+func (api *API) renameProjectKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+
+	var input struct {
+		Key string `json:"key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if err := api.db.RenameProjectKey(r.Context(), id, input.Key); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	project, err := api.db.GetProjectByID(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, project)
+}
+
+// getTaskByRef resolves a task by its database ID or a reference like HACK-42
+func (api *API) getTaskByRef(w http.ResponseWriter, r *http.Request) {
+	ref := mux.Vars(r)["ref"]
+
+	var (
+		task *projectmodel.Task
+		err  error
+	)
+	if id, convErr := strconv.Atoi(ref); convErr == nil {
+		task, err = api.db.GetTaskByID(r.Context(), id)
+	} else {
+		task, err = api.db.GetTaskByRef(r.Context(), ref)
+	}
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, task.ProjectID); !ok {
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, task)
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// labelInput is the request body for creating and updating a label
+type labelInput struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// labelVars parses the project and label IDs of /projects/{id}/labels/{labelId} routes
+func (api *API) labelVars(w http.ResponseWriter, r *http.Request) (projectID, labelID int, ok bool) {
+	vars := mux.Vars(r)
+	projectID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return 0, 0, false
+	}
+	labelID, err = strconv.Atoi(vars["labelId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid label ID"))
+		return 0, 0, false
+	}
+	return projectID, labelID, true
+}
+
+// Label handlers
+func (api *API) getLabels(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id); !ok {
+		return
+	}
+
+	labels, err := api.db.GetLabels(r.Context(), id)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, labels)
+}
+
+func (api *API) createLabel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id); !ok {
+		return
+	}
+	var input labelInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	labelID, err := api.db.CreateLabel(r.Context(), id, db.LabelInput{Name: input.Name, Color: input.Color})
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusCreated, map[string]int{"id": labelID})
+}
+
+func (api *API) updateLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, labelID, ok := api.labelVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	var input labelInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if err := api.db.UpdateLabel(r.Context(), projectID, labelID, db.LabelInput{Name: input.Name, Color: input.Color}); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "label updated"})
+}
+
+func (api *API) deleteLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, labelID, ok := api.labelVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+
+	if err := api.db.DeleteLabel(r.Context(), projectID, labelID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "label deleted"})
+}
+
+func (api *API) mergeLabels(w http.ResponseWriter, r *http.Request) {
+	projectID, labelID, ok := api.labelVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+	var input struct {
+		Into int `json:"into"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Into <= 0 {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body or into missing"))
+		return
+	}
+
+	relinked, err := api.db.MergeLabels(r.Context(), projectID, labelID, input.Into)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]int{"id": input.Into, "relinked": relinked})
+}
+
+func (api *API) attachLabels(w http.ResponseWriter, r *http.Request) {
+	api.setTaskLabels(w, r, true)
+}
+
+func (api *API) detachLabels(w http.ResponseWriter, r *http.Request) {
+	api.setTaskLabels(w, r, false)
+}
+
+// setTaskLabels attaches or detaches every listed label on every listed task
+func (api *API) setTaskLabels(w http.ResponseWriter, r *http.Request, attach bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id); !ok {
+		return
+	}
+	var input struct {
+		TaskIDs  []int `json:"task_ids"`
+		LabelIDs []int `json:"label_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if len(input.TaskIDs) == 0 || len(input.LabelIDs) == 0 {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("task_ids and label_ids are required"))
+		return
+	}
+
+	changed, err := api.db.SetTaskLabels(r.Context(), id, input.TaskIDs, input.LabelIDs, attach)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]int{"changed": changed})
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// warnBlocked notes on a task that was just completed that it still has open blockers
+func warnBlocked(task *projectmodel.Task) {
+	if task.CompletedAt == nil || len(task.Blockers) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(task.Blockers))
+	for _, b := range task.Blockers {
+		if b.Hidden {
+			keys = append(keys, "a task of another project")
+			continue
+		}
+		keys = append(keys, b.Key)
+	}
+	task.Warnings = append(task.Warnings, fmt.Sprintf("task is done but still blocked by %s", strings.Join(keys, ", ")))
+}
+
+// Task link handlers
+func (api *API) getTaskLinks(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := api.projectTask(w, r)
+	if !ok {
+		return
+	}
+
+	links, err := api.db.GetTaskLinks(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, links)
+}
+
+func (api *API) addTaskLink(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	var input struct {
+		Type   string `json:"type"`
+		TaskID int    `json:"task_id"`
+		Task   string `json:"task"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	otherID := input.TaskID
+	if input.Task != "" {
+		other, err := api.db.GetTaskByRef(r.Context(), input.Task)
+		if err != nil {
+			api.sendError(w, errorStatus(err), err)
+			return
+		}
+		otherID, _ = strconv.Atoi(other.ID)
+	}
+	if otherID <= 0 {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("task_id or task is required"))
+		return
+	}
+
+	linkID, err := api.db.AddTaskLink(r.Context(), projectID, id, input.Type, otherID)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusCreated, map[string]int{"id": linkID})
+}
+
+func (api *API) deleteTaskLink(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	linkID, err := strconv.Atoi(mux.Vars(r)["linkId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid link ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+
+	if err := api.db.DeleteTaskLink(r.Context(), projectID, id, linkID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "link deleted"})
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// requireProjectRole checks that the caller is a project member with one of the given roles (any role if none given).
+// It writes the error response itself and returns the caller's ID when the handler may continue.
+func (api *API) requireProjectRole(w http.ResponseWriter, r *http.Request, projectID int, roles ...string) (int, bool) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return 0, false
+	}
+	role, err := api.db.ProjectRole(r.Context(), projectID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			api.sendError(w, http.StatusForbidden, fmt.Errorf("no access to project %d", projectID))
+			return 0, false
+		}
+		api.sendError(w, http.StatusInternalServerError, err)
+		return 0, false
+	}
+	if len(roles) > 0 && !slices.Contains(roles, role) {
+		api.sendError(w, http.StatusForbidden, fmt.Errorf("project role %q is not allowed to do this", role))
+		return 0, false
+	}
+	return userID, true
+}
+
+// Project member handlers
+func (api *API) getProjectMembers(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id); !ok {
+		return
+	}
+
+	members, err := api.db.GetProjectMembers(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, members)
+}
+
+func (api *API) setProjectMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	var input struct {
+		UserID int    `json:"user_id"`
+		Role   string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if input.UserID <= 0 {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("valid user_id is required"))
+		return
+	}
+	if input.Role == "" {
+		input.Role = projectmodel.RoleMember
+	}
+	if input.Role != projectmodel.RoleMember && input.Role != projectmodel.RoleMaintainer {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("role must be %q or %q", projectmodel.RoleMember, projectmodel.RoleMaintainer))
+		return
+	}
+
+	if _, ok := api.requireProjectRole(w, r, id, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+	if _, err := api.usersDB.GetUser(r.Context(), input.UserID); err != nil {
+		api.sendError(w, http.StatusNotFound, fmt.Errorf("user not found"))
+		return
+	}
+
+	if err := api.db.SetProjectMember(r.Context(), id, input.UserID, input.Role); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "member saved"})
+}
+
+func (api *API) removeProjectMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	userID, err := strconv.Atoi(vars["userId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	if _, ok := api.requireProjectRole(w, r, id, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+
+	if err := api.db.RemoveProjectMember(r.Context(), id, userID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "member removed"})
+}
+
+// Ownership transfer handlers
+func (api *API) requestOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+
+	var input struct {
+		ToUserID int `json:"to_user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if input.ToUserID <= 0 {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("valid to_user_id is required"))
+		return
+	}
+	if _, err := api.usersDB.GetUser(r.Context(), input.ToUserID); err != nil {
+		api.sendError(w, http.StatusNotFound, fmt.Errorf("user not found"))
+		return
+	}
+
+	transferID, err := api.db.RequestOwnershipTransfer(r.Context(), id, userID, input.ToUserID)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusCreated, map[string]int{"id": transferID})
+}
+
+func (api *API) getOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	transfer, err := api.db.GetPendingTransfer(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, transfer)
+}
+
+func (api *API) acceptOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid transfer ID"))
+		return
+	}
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+
+	if err := api.db.AcceptOwnershipTransfer(r.Context(), id, userID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "ownership transferred"})
+}
+
+func (api *API) declineOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid transfer ID"))
+		return
+	}
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+
+	if err := api.db.DeclineOwnershipTransfer(r.Context(), id, userID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "ownership transfer declined"})
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+)
+
+// Headers identifying the caller and the workspace the request operates in
+const (
+	userIDHeader      = "X-User-ID"
+	workspaceIDHeader = "X-Workspace-ID"
+)
+
+// currentUserID returns the ID of the user making the request
+func currentUserID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.Header.Get(userIDHeader))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// actorMiddleware attributes the request's mutations to the user from X-User-ID
+func (api *API) actorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userID, ok := currentUserID(r); ok {
+			r = r.WithContext(db.WithActor(r.Context(), userID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// workspaceMiddleware scopes the request context to the workspace from X-Workspace-ID
+// after checking that the caller is a member of it
+func (api *API) workspaceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get(workspaceIDHeader)
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		workspaceID, err := strconv.Atoi(header)
+		if err != nil {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid workspace ID"))
+			return
+		}
+		userID, ok := currentUserID(r)
+		if !ok {
+			api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required to access a workspace"))
+			return
+		}
+		if _, err := api.db.WorkspaceRole(r.Context(), workspaceID, userID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				api.sendError(w, http.StatusForbidden, fmt.Errorf("no access to workspace %d", workspaceID))
+				return
+			}
+			api.sendError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(db.WithWorkspace(r.Context(), workspaceID)))
+	})
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+)
+
+// memberTask parses the task route and checks that the requester is a project member
+func (api *API) memberTask(w http.ResponseWriter, r *http.Request) (projectID, id int, ok bool) {
+	projectID, id, ok = api.taskVars(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return 0, 0, false
+	}
+	return projectID, id, true
+}
+
+// sendRecurrence responds with the current recurrence of a task
+func (api *API) sendRecurrence(w http.ResponseWriter, r *http.Request, id int) {
+	recurrence, err := api.db.GetRecurrence(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendSuccess(w, http.StatusOK, recurrence)
+}
+
+// Recurrence handlers
+func (api *API) getRecurrence(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := api.projectTask(w, r)
+	if !ok {
+		return
+	}
+	api.sendRecurrence(w, r, id)
+}
+
+func (api *API) setRecurrence(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.memberTask(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		Rule     string    `json:"rule"`
+		StartsAt time.Time `json:"starts_at"`
+		Timezone string    `json:"timezone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if input.StartsAt.IsZero() {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("starts_at is required"))
+		return
+	}
+
+	err := api.db.SetRecurrence(r.Context(), projectID, id, db.RecurrenceInput{
+		Rule:     input.Rule,
+		StartsAt: input.StartsAt,
+		Timezone: input.Timezone,
+	})
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendRecurrence(w, r, id)
+}
+
+func (api *API) deleteRecurrence(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.memberTask(w, r)
+	if !ok {
+		return
+	}
+
+	if err := api.db.DeleteRecurrence(r.Context(), projectID, id); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "recurrence deleted"})
+}
+
+func (api *API) getOccurrences(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := api.projectTask(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 10
+	}
+
+	occurrences, err := api.db.GetUpcomingOccurrences(r.Context(), id, limit)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, occurrences)
+}
+
+func (api *API) addOccurrenceException(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.memberTask(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		Occurrence time.Time  `json:"occurrence"`
+		ShiftTo    *time.Time `json:"shift_to"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if input.Occurrence.IsZero() {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("occurrence is required"))
+		return
+	}
+
+	exceptionID, err := api.db.SetOccurrenceException(r.Context(), projectID, id, input.Occurrence, input.ShiftTo)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusCreated, map[string]int{"id": exceptionID})
+}
+
+func (api *API) deleteOccurrenceException(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.memberTask(w, r)
+	if !ok {
+		return
+	}
+	exceptionID, err := strconv.Atoi(mux.Vars(r)["exceptionId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid exception ID"))
+		return
+	}
+
+	if err := api.db.DeleteOccurrenceException(r.Context(), projectID, id, exceptionID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "occurrence exception deleted"})
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+)
+
+// search finds tasks, comments and projects visible to the requester
+func (api *API) search(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+	query := r.URL.Query()
+	f := db.SearchFilter{
+		Query:  query.Get("q"),
+		UserID: userID,
+		Types:  splitList(query.Get("types")),
+		Limit:  20,
+	}
+	if v := query.Get("project_id"); v != "" {
+		projectID, err := strconv.Atoi(v)
+		if err != nil {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project_id"))
+			return
+		}
+		f.ProjectID = &projectID
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid limit"))
+			return
+		}
+		f.Limit = limit
+	}
+
+	results, err := api.db.Search(r.Context(), f)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, results)
+}
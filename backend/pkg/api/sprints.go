@@ -0,0 +1,243 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// projectSprint parses the IDs of /projects/{projectId}/sprints/{sprintId} routes
+// and checks that the sprint belongs to the project
+func (api *API) projectSprint(w http.ResponseWriter, r *http.Request) (projectID, sprintID int, ok bool) {
+	vars := mux.Vars(r)
+	projectID, err := strconv.Atoi(vars["projectId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return 0, 0, false
+	}
+	sprintID, err = strconv.Atoi(vars["sprintId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid sprint ID"))
+		return 0, 0, false
+	}
+	if _, err := api.db.GetSprint(r.Context(), projectID, sprintID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return 0, 0, false
+	}
+	return projectID, sprintID, true
+}
+
+// sendSprint responds with the current state of a sprint
+func (api *API) sendSprint(w http.ResponseWriter, r *http.Request, status, projectID, sprintID int) {
+	sprint, err := api.db.GetSprint(r.Context(), projectID, sprintID)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendSuccess(w, status, sprint)
+}
+
+// Sprint handlers
+func (api *API) getSprints(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id); !ok {
+		return
+	}
+
+	sprints, err := api.db.GetSprints(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, sprints)
+}
+
+func (api *API) createSprint(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+	var input struct {
+		Name      string `json:"name"`
+		Goal      string `json:"goal"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	sprintID, err := api.db.CreateSprint(r.Context(), id, db.SprintInput{
+		Name:      input.Name,
+		Goal:      input.Goal,
+		StartDate: input.StartDate,
+		EndDate:   input.EndDate,
+	})
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendSprint(w, r, http.StatusCreated, id, sprintID)
+}
+
+func (api *API) getSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, sprintID, ok := api.projectSprint(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	api.sendSprint(w, r, http.StatusOK, projectID, sprintID)
+}
+
+func (api *API) updateSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, sprintID, ok := api.projectSprint(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+	var patch db.SprintPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if err := api.db.UpdateSprint(r.Context(), sprintID, patch); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendSprint(w, r, http.StatusOK, projectID, sprintID)
+}
+
+func (api *API) deleteSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, sprintID, ok := api.projectSprint(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+
+	if err := api.db.DeleteSprint(r.Context(), sprintID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "sprint deleted"})
+}
+
+func (api *API) startSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, sprintID, ok := api.projectSprint(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+
+	if err := api.db.StartSprint(r.Context(), sprintID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendSprint(w, r, http.StatusOK, projectID, sprintID)
+}
+
+func (api *API) completeSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, sprintID, ok := api.projectSprint(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+	var input struct {
+		CarryTo db.Optional[*int] `json:"carry_to"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+	}
+
+	result, err := api.db.CompleteSprint(r.Context(), sprintID, input.CarryTo)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	sprint, err := api.db.GetSprint(r.Context(), projectID, sprintID)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	result.Sprint = *sprint
+
+	api.sendSuccess(w, http.StatusOK, result)
+}
+
+func (api *API) getBurndown(w http.ResponseWriter, r *http.Request) {
+	projectID, sprintID, ok := api.projectSprint(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	loc, err := requestLocation(r)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	burndown, err := api.db.GetBurndown(r.Context(), projectID, sprintID, loc)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, burndown)
+}
+
+func (api *API) getVelocity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id); !ok {
+		return
+	}
+	var limit int
+	if v := r.URL.Query().Get("sprints"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid sprints"))
+			return
+		}
+	}
+
+	velocity, err := api.db.GetVelocity(r.Context(), id, limit)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, velocity)
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// stageInput is the request body for creating and updating a stage
+type stageInput struct {
+	Title    string `json:"title"`
+	WIPLimit *int   `json:"wip_limit"`
+	IsDone   bool   `json:"is_done"`
+}
+
+func decodeStageInput(r *http.Request) (db.StageInput, error) {
+	var input stageInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		return db.StageInput{}, fmt.Errorf("invalid request body: %w", err)
+	}
+	if strings.TrimSpace(input.Title) == "" {
+		return db.StageInput{}, fmt.Errorf("title is required")
+	}
+	if input.WIPLimit != nil && *input.WIPLimit <= 0 {
+		return db.StageInput{}, fmt.Errorf("wip_limit must be positive")
+	}
+	return db.StageInput{Title: input.Title, WIPLimit: input.WIPLimit, IsDone: input.IsDone}, nil
+}
+
+// stageVars parses the project and stage IDs of /projects/{id}/stages/{stageId} routes
+func (api *API) stageVars(w http.ResponseWriter, r *http.Request) (projectID, stageID int, ok bool) {
+	vars := mux.Vars(r)
+	projectID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return 0, 0, false
+	}
+	stageID, err = strconv.Atoi(vars["stageId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid stage ID"))
+		return 0, 0, false
+	}
+	return projectID, stageID, true
+}
+
+// Stage handlers
+func (api *API) getStages(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id); !ok {
+		return
+	}
+
+	stages, err := api.db.GetStages(r.Context(), id)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, stages)
+}
+
+func (api *API) createStage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+	input, err := decodeStageInput(r)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	stageID, err := api.db.CreateStage(r.Context(), id, input)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusCreated, map[string]int{"id": stageID})
+}
+
+func (api *API) updateStage(w http.ResponseWriter, r *http.Request) {
+	projectID, stageID, ok := api.stageVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+	input, err := decodeStageInput(r)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := api.db.UpdateStage(r.Context(), projectID, stageID, input); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "stage updated"})
+}
+
+func (api *API) reorderStages(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+
+	var input struct {
+		StageIDs []int `json:"stage_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if err := api.db.ReorderStages(r.Context(), id, input.StageIDs); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	stages, err := api.db.GetStages(r.Context(), id)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, stages)
+}
+
+func (api *API) deleteStage(w http.ResponseWriter, r *http.Request) {
+	projectID, stageID, ok := api.stageVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+	var moveTo int
+	if v := r.URL.Query().Get("move_to"); v != "" {
+		var err error
+		if moveTo, err = strconv.Atoi(v); err != nil {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid move_to"))
+			return
+		}
+	}
+
+	if err := api.db.DeleteStage(r.Context(), projectID, stageID, moveTo); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "stage deleted"})
+}
+
+func (api *API) moveTaskToStage(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	var input struct {
+		StageID int `json:"stage_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.StageID <= 0 {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body or stage_id missing"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+
+	if err := api.db.MoveTaskToStage(r.Context(), projectID, id, input.StageID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	task, err := api.db.GetProjectTask(r.Context(), projectID, id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	warnBlocked(task)
+	api.sendSuccess(w, http.StatusOK, task)
+}
+
+func (api *API) moveTask(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return
+	}
+	var input struct {
+		StageID  int `json:"stage_id"`
+		BeforeID int `json:"before_id"`
+		AfterID  int `json:"after_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if input.BeforeID > 0 && input.AfterID > 0 {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("use either before_id or after_id"))
+		return
+	}
+
+	task, err := api.db.GetTaskByID(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, task.ProjectID); !ok {
+		return
+	}
+
+	placement := db.TaskPlacement{StageID: input.StageID, BeforeID: input.BeforeID, AfterID: input.AfterID}
+	if err := api.db.MoveTask(r.Context(), task.ProjectID, id, placement); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	task, err = api.db.GetTaskByID(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	warnBlocked(task)
+	api.sendSuccess(w, http.StatusOK, task)
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+)
+
+// checklistVars parses the IDs of /projects/{projectId}/tasks/{id}/checklist/{itemId} routes
+func (api *API) checklistVars(w http.ResponseWriter, r *http.Request) (projectID, id, itemID int, ok bool) {
+	projectID, id, ok = api.taskVars(w, r)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	itemID, err := strconv.Atoi(mux.Vars(r)["itemId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid checklist item ID"))
+		return 0, 0, 0, false
+	}
+	return projectID, id, itemID, true
+}
+
+// projectTask parses the task route, checks that the user is a project member
+// and that the task belongs to the project
+func (api *API) projectTask(w http.ResponseWriter, r *http.Request) (projectID, id int, ok bool) {
+	projectID, id, ok = api.taskVars(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return 0, 0, false
+	}
+	if _, err := api.db.GetProjectTask(r.Context(), projectID, id); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return 0, 0, false
+	}
+	return projectID, id, true
+}
+
+// Subtask handlers
+func (api *API) getSubtasks(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := api.projectTask(w, r)
+	if !ok {
+		return
+	}
+
+	tasks, err := api.db.GetSubtasks(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, tasks)
+}
+
+func (api *API) setTaskParent(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	var input struct {
+		ParentID *int `json:"parent_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if err := api.db.SetTaskParent(r.Context(), projectID, id, input.ParentID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendTask(w, r, projectID, id)
+}
+
+// Checklist handlers
+func (api *API) getChecklist(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := api.projectTask(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := api.db.GetChecklist(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, items)
+}
+
+func (api *API) addChecklistItem(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	var input struct {
+		Text       string `json:"text"`
+		AssigneeID *int   `json:"assignee_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	itemID, err := api.db.AddChecklistItem(r.Context(), projectID, id, db.ChecklistInput{Text: input.Text, AssigneeID: input.AssigneeID})
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusCreated, map[string]int{"id": itemID})
+}
+
+func (api *API) updateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	projectID, id, itemID, ok := api.checklistVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	var patch db.ChecklistPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if err := api.db.UpdateChecklistItem(r.Context(), projectID, id, itemID, patch); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "checklist item updated"})
+}
+
+func (api *API) deleteChecklistItem(w http.ResponseWriter, r *http.Request) {
+	projectID, id, itemID, ok := api.checklistVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+
+	if err := api.db.DeleteChecklistItem(r.Context(), projectID, id, itemID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "checklist item deleted"})
+}
+
+func (api *API) reorderChecklist(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	var input struct {
+		ItemIDs []int `json:"item_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if err := api.db.ReorderChecklist(r.Context(), projectID, id, input.ItemIDs); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	items, err := api.db.GetChecklist(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendSuccess(w, http.StatusOK, items)
+}
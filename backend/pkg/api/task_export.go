@@ -0,0 +1,167 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+	"github.com/nais2008/hackanet2025/backend/pkg/spreadsheet"
+)
+
+// exportDateLayout is how dates are written to task spreadsheets
+const exportDateLayout = "2006-01-02 15:04"
+
+// taskColumn is one selectable column of the task export
+type taskColumn struct {
+	key    string
+	header string
+	value  func(t *projectmodel.Task, loc *time.Location) string
+}
+
+// taskColumns lists the exportable columns in their default order
+var taskColumns = []taskColumn{
+	{"id", "ID", func(t *projectmodel.Task, _ *time.Location) string { return t.ID }},
+	{"key", "Ключ", func(t *projectmodel.Task, _ *time.Location) string { return t.Key }},
+	{"title", "Название", func(t *projectmodel.Task, _ *time.Location) string { return t.Title }},
+	{"stage", "Колонка", func(t *projectmodel.Task, _ *time.Location) string { return t.Stage }},
+	{"description", "Описание", func(t *projectmodel.Task, _ *time.Location) string { return t.Description }},
+	{"full_description", "Полное описание", func(t *projectmodel.Task, _ *time.Location) string { return t.Full_description }},
+	{"status", "Статус", func(t *projectmodel.Task, _ *time.Location) string { return t.Status }},
+	{"priority", "Приоритет", func(t *projectmodel.Task, _ *time.Location) string { return priorityTitles[t.Priority] }},
+	{"start_date", "Начало", func(t *projectmodel.Task, loc *time.Location) string { return formatExportTime(t.StartDate, loc) }},
+	{"deadline", "Срок", func(t *projectmodel.Task, loc *time.Location) string { return formatExportTime(t.Deadline, loc) }},
+	{"labels", "Метки", func(t *projectmodel.Task, _ *time.Location) string { return labelNames(t.Labels) }},
+	{"assignees", "Исполнители", func(t *projectmodel.Task, _ *time.Location) string { return usernames(t.Assignees) }},
+	{"created_at", "Создана", func(t *projectmodel.Task, loc *time.Location) string { return formatExportTime(&t.CreatedAt, loc) }},
+	{"completed_at", "Завершена", func(t *projectmodel.Task, loc *time.Location) string { return formatExportTime(t.CompletedAt, loc) }},
+}
+
+// priorityTitles are the human-readable priorities used in exports
+var priorityTitles = map[string]string{
+	projectmodel.PriorityLow:      "Низкий",
+	projectmodel.PriorityMedium:   "Средний",
+	projectmodel.PriorityHigh:     "Высокий",
+	projectmodel.PriorityCritical: "Критический",
+}
+
+func labelNames(labels []projectmodel.Label) string {
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func usernames(users []projectmodel.UserRef) string {
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = u.Username
+	}
+	return strings.Join(names, ", ")
+}
+
+func formatExportTime(t *time.Time, loc *time.Location) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.In(loc).Format(exportDateLayout)
+}
+
+// selectTaskColumns resolves the comma-separated columns parameter; empty selects all columns
+func selectTaskColumns(param string) ([]taskColumn, error) {
+	if param == "" {
+		return taskColumns, nil
+	}
+	var selected []taskColumn
+	for _, key := range strings.Split(param, ",") {
+		key = strings.TrimSpace(key)
+		found := false
+		for _, c := range taskColumns {
+			if c.key == key {
+				selected = append(selected, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q", key)
+		}
+	}
+	return selected, nil
+}
+
+// Task export handlers
+func (api *API) exportTasks(w http.ResponseWriter, r *http.Request) {
+	projectID, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("format must be csv or xlsx"))
+		return
+	}
+	columns, err := selectTaskColumns(query.Get("columns"))
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+	loc, err := requestLocation(r)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := api.requireProjectRole(w, r, projectID); !ok {
+		return
+	}
+	tasks, err := api.db.GetTasks(r.Context(), projectID, filter)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", spreadsheet.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%d-tasks.%s"`, projectID, format))
+	sheet, err := spreadsheet.New(format, w, "Tasks")
+	if err != nil {
+		log.Printf("Error writing task export: %v", err)
+		return
+	}
+
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		row[i] = c.header
+	}
+	if err := sheet.WriteRow(row); err != nil {
+		log.Printf("Error writing task export: %v", err)
+		return
+	}
+	for i := range tasks {
+		for j, c := range columns {
+			row[j] = c.value(&tasks[i], loc)
+		}
+		if err := sheet.WriteRow(row); err != nil {
+			log.Printf("Error writing task export: %v", err)
+			return
+		}
+	}
+	if err := sheet.Close(); err != nil {
+		log.Printf("Error writing task export: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+)
+
+// parseTaskFilter reads the task list filters shared by the task list and the task export
+func parseTaskFilter(r *http.Request) (db.TaskFilter, error) {
+	query := r.URL.Query()
+	var f db.TaskFilter
+
+	if v := query.Get("completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid completed")
+		}
+		f.Completed = &completed
+	}
+	if v := query.Get("stage_id"); v != "" {
+		stageID, err := strconv.Atoi(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid stage_id")
+		}
+		f.StageID = &stageID
+	}
+	if v := query.Get("status"); v != "" {
+		f.Status = &v
+	}
+	if v := query.Get("priority"); v != "" {
+		f.Priorities = splitList(v)
+	}
+	f.MinPriority = query.Get("priority_min")
+	if v := query.Get("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid overdue")
+		}
+		f.Overdue = &overdue
+	}
+	if v := query.Get("labels"); v != "" {
+		for _, item := range splitList(v) {
+			labelID, err := strconv.Atoi(item)
+			if err != nil {
+				return f, fmt.Errorf("invalid labels")
+			}
+			f.Labels = append(f.Labels, labelID)
+		}
+	}
+	switch query.Get("labels_match") {
+	case "", "any":
+	case "all":
+		f.AllLabels = true
+	default:
+		return f, fmt.Errorf("invalid labels_match, expected any or all")
+	}
+	switch v := query.Get("assignee"); v {
+	case "":
+	case "none":
+		f.Unassigned = true
+	default:
+		userID, err := filterUserID(r, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid assignee: %w", err)
+		}
+		f.AssigneeID = &userID
+	}
+	if v := query.Get("watcher"); v != "" {
+		userID, err := filterUserID(r, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid watcher: %w", err)
+		}
+		f.WatcherID = &userID
+	}
+	switch v := query.Get("parent"); v {
+	case "":
+	case "none":
+		f.TopLevel = true
+	default:
+		parentID, err := strconv.Atoi(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid parent, expected a task ID or none")
+		}
+		f.ParentID = &parentID
+	}
+	switch v := query.Get("sprint"); v {
+	case "":
+	case "none":
+		f.Backlog = true
+	default:
+		sprintID, err := strconv.Atoi(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid sprint, expected a sprint ID or none")
+		}
+		f.SprintID = &sprintID
+	}
+	if v := query.Get("archived"); v != "" {
+		archived, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid archived")
+		}
+		f.Archived = archived
+	}
+	if v := query.Get("sort"); v != "" {
+		f.Sort = splitList(v)
+	}
+	for name, dst := range map[string]**time.Time{
+		"created_after":  &f.CreatedAfter,
+		"created_before": &f.CreatedBefore,
+		"due_after":      &f.DueAfter,
+		"due_before":     &f.DueBefore,
+	} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := parseTime(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid %s", name)
+		}
+		*dst = &t
+	}
+	if v := query.Get("due"); v != "" {
+		if err := applyDuePeriod(r, &f, v); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+// filterUserID resolves a user filter value: a user ID or "me" for the requester
+func filterUserID(r *http.Request, v string) (int, error) {
+	if v == "me" {
+		userID, ok := currentUserID(r)
+		if !ok {
+			return 0, fmt.Errorf("me requires the %s header", userIDHeader)
+		}
+		return userID, nil
+	}
+	userID, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("expected a user ID or me")
+	}
+	return userID, nil
+}
+
+// applyDuePeriod narrows the deadline range to today or the current week in the requester's time zone
+func applyDuePeriod(r *http.Request, f *db.TaskFilter, period string) error {
+	loc, err := requestLocation(r)
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	var end time.Time
+	switch period {
+	case "today":
+		end = start.AddDate(0, 0, 1)
+	case "week":
+		// Неделя начинается с понедельника
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		end = start.AddDate(0, 0, 7)
+	default:
+		return fmt.Errorf("invalid due, expected today or week")
+	}
+	f.DueAfter, f.DueBefore = &start, &end
+	return nil
+}
+
+// splitList splits a comma-separated query value, dropping empty items
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseTime accepts RFC 3339 timestamps and plain dates
+func parseTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
+
+// requestLocation returns the requester's time zone from the tz parameter or X-Timezone header, UTC by default
+func requestLocation(r *http.Request) (*time.Location, error) {
+	name := r.URL.Query().Get("tz")
+	if name == "" {
+		name = r.Header.Get("X-Timezone")
+	}
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q", name)
+	}
+	return loc, nil
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// historyFieldTitles name the task fields in the history timeline
+var historyFieldTitles = map[string]string{
+	"title":            "Название",
+	"description":      "Описание",
+	"full_description": "Полное описание",
+	"status":           "Статус",
+	"priority":         "Приоритет",
+	"start_date":       "Начало",
+	"deadline":         "Срок",
+	"stage":            "Колонка",
+	"parent_id":        "Родительская задача",
+	"story_points":     "Оценка",
+	"sprint_id":        "Спринт",
+}
+
+// describeChange renders a history entry as a sentence for the timeline
+func describeChange(c projectmodel.TaskChange, loc *time.Location) string {
+	value := func(v *string) string {
+		if v == nil || *v == "" {
+			return "—"
+		}
+		switch c.Field {
+		case "priority":
+			if title, ok := priorityTitles[*v]; ok {
+				return title
+			}
+		case "start_date", "deadline", "completed_at", "archived_at":
+			if t, err := time.Parse(time.RFC3339, *v); err == nil {
+				return formatExportTime(&t, loc)
+			}
+		case "parent_id", "sprint_id":
+			return "#" + *v
+		}
+		return *v
+	}
+
+	switch c.Field {
+	case "created":
+		return fmt.Sprintf("Задача создана: «%s»", value(c.NewValue))
+	case "completed_at":
+		if c.NewValue != nil {
+			return "Задача завершена"
+		}
+		return "Задача снова открыта"
+	case "archived_at":
+		if c.NewValue != nil {
+			return "Задача перенесена в архив"
+		}
+		return "Задача возвращена из архива"
+	case "assignee":
+		if c.NewValue != nil {
+			return "Назначен исполнитель " + value(c.NewValue)
+		}
+		return "Снят исполнитель " + value(c.OldValue)
+	case "label":
+		if c.NewValue != nil {
+			return "Добавлена метка " + value(c.NewValue)
+		}
+		return "Снята метка " + value(c.OldValue)
+	case "description", "full_description":
+		// Тексты описаний слишком длинные для ленты, они остаются в old_value и new_value
+		return historyFieldTitles[c.Field] + " изменено"
+	}
+	title, ok := historyFieldTitles[c.Field]
+	if !ok {
+		title = c.Field
+	}
+	return fmt.Sprintf("%s: %s → %s", title, value(c.OldValue), value(c.NewValue))
+}
+
+// Task history handlers
+func (api *API) getTaskHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return
+	}
+	task, err := api.db.GetTaskByID(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, task.ProjectID); !ok {
+		return
+	}
+	query := r.URL.Query()
+	var before int64
+	if v := query.Get("before"); v != "" {
+		if before, err = strconv.ParseInt(v, 10, 64); err != nil {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid before"))
+			return
+		}
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	loc, err := requestLocation(r)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	changes, err := api.db.GetTaskHistory(r.Context(), id, before, limit)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	for i := range changes {
+		changes[i].Text = describeChange(changes[i], loc)
+	}
+
+	response := struct {
+		Items      []projectmodel.TaskChange `json:"items"`
+		NextBefore *int64                    `json:"next_before"`
+	}{Items: changes}
+	if len(changes) > 0 {
+		response.NextBefore = &changes[len(changes)-1].ID
+	}
+	api.sendSuccess(w, http.StatusOK, response)
+}
+
+func (api *API) restoreTaskHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return
+	}
+	changeID, err := strconv.ParseInt(mux.Vars(r)["changeId"], 10, 64)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid history entry ID"))
+		return
+	}
+	task, err := api.db.GetTaskByID(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, task.ProjectID); !ok {
+		return
+	}
+
+	if err := api.db.RestoreFullDescription(r.Context(), task.ProjectID, id, changeID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.sendTask(w, r, task.ProjectID, id)
+}
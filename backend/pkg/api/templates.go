@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// Clone and template handlers
+func (api *API) cloneProject(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, id)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Title        string `json:"title"`
+		IncludeTasks bool   `json:"include_tasks"`
+		IncludeFiles bool   `json:"include_files"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	newID, err := api.db.CloneProject(r.Context(), id, db.CloneOptions{
+		Title:        input.Title,
+		UserID:       userID,
+		IncludeTasks: input.IncludeTasks,
+		IncludeFiles: input.IncludeFiles,
+	})
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusCreated, map[string]int{"id": newID})
+}
+
+func (api *API) getTemplates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+	templates, err := api.db.ListTemplates(r.Context(), userID)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+	api.sendSuccess(w, http.StatusOK, templates)
+}
+
+func (api *API) createTemplate(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		ProjectID int    `json:"project_id"`
+		Title     string `json:"title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if input.ProjectID <= 0 {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("valid project_id is required"))
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, input.ProjectID, projectmodel.RoleOwner, projectmodel.RoleMaintainer)
+	if !ok {
+		return
+	}
+
+	id, err := api.db.CloneProject(r.Context(), input.ProjectID, db.CloneOptions{
+		Title:        input.Title,
+		UserID:       userID,
+		IncludeTasks: true,
+		IncludeFiles: true,
+		AsTemplate:   true,
+	})
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusCreated, map[string]int{"id": id})
+}
+
+func (api *API) deleteTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid template ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id, projectmodel.RoleOwner, projectmodel.RoleMaintainer); !ok {
+		return
+	}
+
+	if err := api.db.DeleteTemplate(r.Context(), id); err != nil {
+		api.sendError(w, http.StatusNotFound, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "template deleted"})
+}
@@ -0,0 +1,379 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+	"github.com/nais2008/hackanet2025/backend/pkg/spreadsheet"
+)
+
+// worklogVars parses the IDs of /projects/{projectId}/tasks/{id}/worklogs/{worklogId} routes
+func (api *API) worklogVars(w http.ResponseWriter, r *http.Request) (projectID, id, worklogID int, ok bool) {
+	projectID, id, ok = api.taskVars(w, r)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	worklogID, err := strconv.Atoi(mux.Vars(r)["worklogId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid worklog ID"))
+		return 0, 0, 0, false
+	}
+	return projectID, id, worklogID, true
+}
+
+// worklogInput decodes a worklog; the date defaults to today in the requester's time zone
+func worklogInput(r *http.Request) (db.WorklogInput, error) {
+	var input struct {
+		Minutes int    `json:"minutes"`
+		Date    string `json:"date"`
+		Note    string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		return db.WorklogInput{}, fmt.Errorf("invalid request body: %w", err)
+	}
+	loc, err := requestLocation(r)
+	if err != nil {
+		return db.WorklogInput{}, err
+	}
+	in := db.WorklogInput{Minutes: input.Minutes, Date: time.Now().In(loc), Note: input.Note}
+	if input.Date != "" {
+		if in.Date, err = time.Parse(time.DateOnly, input.Date); err != nil {
+			return db.WorklogInput{}, fmt.Errorf("invalid date %q", input.Date)
+		}
+	}
+	return in, nil
+}
+
+// canModerate reports whether the user may change other members' records in the project
+func (api *API) canModerate(r *http.Request, projectID, userID int) (bool, error) {
+	role, err := api.db.ProjectRole(r.Context(), projectID, userID)
+	if err != nil {
+		return false, err
+	}
+	return role == projectmodel.RoleOwner || role == projectmodel.RoleMaintainer, nil
+}
+
+// Worklog handlers
+func (api *API) getWorklogs(w http.ResponseWriter, r *http.Request) {
+	_, id, ok := api.projectTask(w, r)
+	if !ok {
+		return
+	}
+
+	worklogs, err := api.db.GetWorklogs(r.Context(), id)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, worklogs)
+}
+
+func (api *API) addWorklog(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, projectID)
+	if !ok {
+		return
+	}
+	in, err := worklogInput(r)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	worklogID, err := api.db.AddWorklog(r.Context(), projectID, id, userID, in)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusCreated, map[string]int{"id": worklogID})
+}
+
+func (api *API) updateWorklog(w http.ResponseWriter, r *http.Request) {
+	projectID, id, worklogID, ok := api.worklogVars(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, projectID)
+	if !ok {
+		return
+	}
+	in, err := worklogInput(r)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+	moderate, err := api.canModerate(r, projectID, userID)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	if err := api.db.UpdateWorklog(r.Context(), projectID, id, worklogID, userID, moderate, in); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "worklog updated"})
+}
+
+func (api *API) deleteWorklog(w http.ResponseWriter, r *http.Request) {
+	projectID, id, worklogID, ok := api.worklogVars(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, projectID)
+	if !ok {
+		return
+	}
+	moderate, err := api.canModerate(r, projectID, userID)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	if err := api.db.DeleteWorklog(r.Context(), projectID, id, worklogID, userID, moderate); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "worklog deleted"})
+}
+
+// Timer handlers
+func (api *API) startTimer(w http.ResponseWriter, r *http.Request) {
+	projectID, id, ok := api.taskVars(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.requireProjectRole(w, r, projectID)
+	if !ok {
+		return
+	}
+
+	if err := api.db.StartTimer(r.Context(), projectID, id, userID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	api.getTimer(w, r)
+}
+
+func (api *API) getTimer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+
+	timer, err := api.db.GetTimer(r.Context(), userID)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, timer)
+}
+
+func (api *API) stopTimer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+	var input struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+	}
+	loc, err := requestLocation(r)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	taskID, worklogID, err := api.db.StopTimer(r.Context(), userID, input.Note, loc)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]int{"task_id": taskID, "worklog_id": worklogID})
+}
+
+func (api *API) cancelTimer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+
+	if err := api.db.CancelTimer(r.Context(), userID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "timer cancelled"})
+}
+
+// reportColumn is one column of an exported time report
+type reportColumn struct {
+	header string
+	value  func(row *projectmodel.TimeReportRow) string
+}
+
+// reportColumns lists the exported columns of each report grouping
+var reportColumns = map[string][]reportColumn{
+	"user":    {{"Пользователь", func(row *projectmodel.TimeReportRow) string { return row.Username }}},
+	"project": {{"Проект", func(row *projectmodel.TimeReportRow) string { return row.ProjectTitle }}},
+	"task": {
+		{"Ключ", func(row *projectmodel.TimeReportRow) string { return row.TaskKey }},
+		{"Задача", func(row *projectmodel.TimeReportRow) string { return row.TaskTitle }},
+	},
+	"week": {{"Неделя", func(row *projectmodel.TimeReportRow) string { return row.Week }}},
+}
+
+// parseTimeReport reads the grouping, period and user of a time report request
+func parseTimeReport(r *http.Request) (db.TimeReportFilter, error) {
+	query := r.URL.Query()
+	f := db.TimeReportFilter{GroupBy: []string{"user"}}
+	if v := query.Get("group"); v != "" {
+		f.GroupBy = strings.Split(v, ",")
+	}
+	for _, g := range f.GroupBy {
+		if _, ok := reportColumns[g]; !ok {
+			return f, fmt.Errorf("%w: unknown grouping %q", db.ErrInvalidReport, g)
+		}
+	}
+	for param, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		if v := query.Get(param); v != "" {
+			t, err := time.Parse(time.DateOnly, v)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s date %q", param, v)
+			}
+			*dst = &t
+		}
+	}
+	if v := query.Get("user_id"); v != "" {
+		userID, err := strconv.Atoi(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid user ID")
+		}
+		f.UserID = &userID
+	}
+	return f, nil
+}
+
+// sendTimeReport responds with the report as JSON or, for format=csv|xlsx, as a spreadsheet
+func (api *API) sendTimeReport(w http.ResponseWriter, r *http.Request, f db.TimeReportFilter, filename string) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" && format != "xlsx" {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("format must be json, csv or xlsx"))
+		return
+	}
+
+	report, err := api.db.TimeReport(r.Context(), f)
+	if err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+	if format == "" || format == "json" {
+		api.sendSuccess(w, http.StatusOK, report)
+		return
+	}
+
+	var columns []reportColumn
+	seen := map[string]bool{}
+	for _, g := range f.GroupBy {
+		if !seen[g] {
+			seen[g] = true
+			columns = append(columns, reportColumns[g]...)
+		}
+	}
+	columns = append(columns,
+		reportColumn{"Минуты", func(row *projectmodel.TimeReportRow) string { return strconv.Itoa(row.Minutes) }},
+		reportColumn{"Часы", func(row *projectmodel.TimeReportRow) string {
+			return strconv.FormatFloat(float64(row.Minutes)/60, 'f', 2, 64)
+		}},
+	)
+
+	w.Header().Set("Content-Type", spreadsheet.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+	sheet, err := spreadsheet.New(format, w, "Time")
+	if err != nil {
+		log.Printf("Error writing time report: %v", err)
+		return
+	}
+
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		row[i] = c.header
+	}
+	if err := sheet.WriteRow(row); err != nil {
+		log.Printf("Error writing time report: %v", err)
+		return
+	}
+	for i := range report {
+		for j, c := range columns {
+			row[j] = c.value(&report[i])
+		}
+		if err := sheet.WriteRow(row); err != nil {
+			log.Printf("Error writing time report: %v", err)
+			return
+		}
+	}
+	if err := sheet.Close(); err != nil {
+		log.Printf("Error writing time report: %v", err)
+	}
+}
+
+// Time report handlers
+func (api *API) getProjectTimeReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if _, ok := api.requireProjectRole(w, r, id); !ok {
+		return
+	}
+	f, err := parseTimeReport(r)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+	f.ProjectID = &id
+
+	api.sendTimeReport(w, r, f, fmt.Sprintf("project-%d-time", id))
+}
+
+func (api *API) getTimeReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+	f, err := parseTimeReport(r)
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, err)
+		return
+	}
+	f.MemberID = &userID
+
+	api.sendTimeReport(w, r, f, "time-report")
+}
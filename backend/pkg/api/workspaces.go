@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+	workspacemodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/workspace_model"
+)
+
+// requireWorkspaceRole checks that the caller belongs to the workspace and, when adminOnly is set, administers it.
+// It writes the error response itself and reports whether the handler may continue.
+func (api *API) requireWorkspaceRole(w http.ResponseWriter, r *http.Request, workspaceID int, adminOnly bool) bool {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return false
+	}
+	role, err := api.db.WorkspaceRole(r.Context(), workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			api.sendError(w, http.StatusForbidden, fmt.Errorf("no access to workspace %d", workspaceID))
+			return false
+		}
+		api.sendError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	if adminOnly && role != workspacemodel.RoleAdmin {
+		api.sendError(w, http.StatusForbidden, fmt.Errorf("workspace admin role is required"))
+		return false
+	}
+	return true
+}
+
+// Workspace handlers
+func (api *API) createWorkspace(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+
+	var input struct {
+		Title string `json:"title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if input.Title == "" {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("title is required"))
+		return
+	}
+
+	id, err := api.db.CreateWorkspace(r.Context(), input.Title, userID)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusCreated, map[string]int{"id": id})
+}
+
+func (api *API) getWorkspaces(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		api.sendError(w, http.StatusUnauthorized, fmt.Errorf("user is required"))
+		return
+	}
+
+	workspaces, err := api.db.GetUserWorkspaces(r.Context(), userID)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, workspaces)
+}
+
+func (api *API) getWorkspace(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid workspace ID"))
+		return
+	}
+	if !api.requireWorkspaceRole(w, r, id, false) {
+		return
+	}
+
+	ws, err := api.db.GetWorkspace(r.Context(), id)
+	if err != nil {
+		api.sendError(w, http.StatusNotFound, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, ws)
+}
+
+func (api *API) updateWorkspace(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid workspace ID"))
+		return
+	}
+
+	var input struct {
+		Title string `json:"title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if input.Title == "" {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("title is required"))
+		return
+	}
+	if !api.requireWorkspaceRole(w, r, id, true) {
+		return
+	}
+
+	if err := api.db.UpdateWorkspaceTitle(r.Context(), id, input.Title); err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "workspace updated"})
+}
+
+func (api *API) deleteWorkspace(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid workspace ID"))
+		return
+	}
+	if !api.requireWorkspaceRole(w, r, id, true) {
+		return
+	}
+
+	if err := api.db.DeleteWorkspace(r.Context(), id); err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "workspace deleted"})
+}
+
+func (api *API) getWorkspaceMembers(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid workspace ID"))
+		return
+	}
+	if !api.requireWorkspaceRole(w, r, id, false) {
+		return
+	}
+
+	members, err := api.db.GetWorkspaceMembers(r.Context(), id)
+	if err != nil {
+		api.sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, members)
+}
+
+func (api *API) setWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid workspace ID"))
+		return
+	}
+
+	var input struct {
+		UserID int    `json:"user_id"`
+		Role   string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if input.UserID <= 0 {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("valid user_id is required"))
+		return
+	}
+	if input.Role == "" {
+		input.Role = workspacemodel.RoleMember
+	}
+	if input.Role != workspacemodel.RoleMember && input.Role != workspacemodel.RoleAdmin {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("role must be %q or %q", workspacemodel.RoleMember, workspacemodel.RoleAdmin))
+		return
+	}
+	if !api.requireWorkspaceRole(w, r, id, true) {
+		return
+	}
+
+	if _, err := api.usersDB.GetUser(r.Context(), input.UserID); err != nil {
+		api.sendError(w, http.StatusNotFound, fmt.Errorf("user not found"))
+		return
+	}
+	if err := api.db.SetWorkspaceMember(r.Context(), id, input.UserID, input.Role); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "member saved"})
+}
+
+func (api *API) removeWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid workspace ID"))
+		return
+	}
+	userID, err := strconv.Atoi(vars["userId"])
+	if err != nil {
+		api.sendError(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
+		return
+	}
+	if !api.requireWorkspaceRole(w, r, id, true) {
+		return
+	}
+
+	if err := api.db.RemoveWorkspaceMember(r.Context(), id, userID); err != nil {
+		api.sendError(w, errorStatus(err), err)
+		return
+	}
+
+	api.sendSuccess(w, http.StatusOK, map[string]string{"message": "member removed"})
+}
@@ -0,0 +1,193 @@
+// Package archive reads and writes portable project archives:
+// a zip file with a JSON manifest and the attachment blobs under files/.
+package archive
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+const (
+	manifestName = "manifest.json"
+	blobDir      = "files/"
+)
+
+// ErrUnsafePath is returned for file paths that would escape the media root
+var ErrUnsafePath = errors.New("unsafe file path")
+
+// Archive is an opened project archive
+type Archive struct {
+	Manifest projectmodel.ProjectExport
+	blobs    map[string]*zip.File
+}
+
+// Write streams exp and the referenced files under mediaRoot as a zip archive.
+// Files that cannot be read are listed in the manifest's missing_files.
+func Write(w io.Writer, exp *projectmodel.ProjectExport, mediaRoot string) error {
+	zw := zip.NewWriter(w)
+
+	exp.MissingFiles = nil
+	for _, name := range referencedFiles(exp) {
+		if err := writeBlob(zw, mediaRoot, name); err != nil {
+			if errors.Is(err, os.ErrNotExist) || errors.Is(err, ErrUnsafePath) {
+				exp.MissingFiles = append(exp.MissingFiles, name)
+				continue
+			}
+			return err
+		}
+	}
+
+	mw, err := zw.Create(manifestName)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(exp); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return zw.Close()
+}
+
+// Read opens an archive and decodes its manifest
+func Read(r io.ReaderAt, size int64) (*Archive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	a := &Archive{blobs: map[string]*zip.File{}}
+	var manifest *zip.File
+	for _, f := range zr.File {
+		switch {
+		case f.Name == manifestName:
+			manifest = f
+		case strings.HasPrefix(f.Name, blobDir) && !f.FileInfo().IsDir():
+			name := strings.TrimPrefix(f.Name, blobDir)
+			if _, err := CleanPath(name); err != nil {
+				return nil, fmt.Errorf("archive entry %s: %w", f.Name, err)
+			}
+			a.blobs[name] = f
+		}
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("archive has no %s", manifestName)
+	}
+
+	mr, err := manifest.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer mr.Close()
+	if err := json.NewDecoder(mr).Decode(&a.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	return a, nil
+}
+
+// Missing returns the files referenced by the manifest that have no blob in the archive
+func (a *Archive) Missing() []string {
+	var missing []string
+	for _, name := range referencedFiles(&a.Manifest) {
+		if _, ok := a.blobs[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// Extract writes every blob of the archive to mediaRoot/prefix/<file path>
+func (a *Archive) Extract(mediaRoot, prefix string) error {
+	for name, f := range a.blobs {
+		clean, err := CleanPath(path.Join(prefix, name))
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		dst := filepath.Join(mediaRoot, filepath.FromSlash(clean))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := extractFile(f, dst); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// CleanPath normalizes a stored file path and rejects absolute paths and paths leaving the media root
+func CleanPath(name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if clean == "." || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", ErrUnsafePath
+	}
+	return clean, nil
+}
+
+func writeBlob(zw *zip.Writer, mediaRoot, name string) error {
+	clean, err := CleanPath(name)
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(filepath.Join(mediaRoot, filepath.FromSlash(clean)))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zw.Create(blobDir + name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	return nil
+}
+
+func extractFile(f *zip.File, dst string) error {
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// referencedFiles lists project and task files of the manifest without duplicates
+func referencedFiles(exp *projectmodel.ProjectExport) []string {
+	seen := map[string]bool{}
+	var files []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	for _, name := range exp.Files {
+		add(name)
+	}
+	for _, t := range exp.Tasks {
+		for _, name := range t.Files {
+			add(name)
+		}
+	}
+	return files
+}
@@ -0,0 +1,58 @@
+package archive_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nais2008/hackanet2025/backend/pkg/archive"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	media := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(media, "project"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(media, "project", "spec.md"), []byte("# Spec"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(media, "task"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(media, "task", "log.txt"), []byte("log"), 0o644))
+
+	exp := &projectmodel.ProjectExport{
+		Version:    1,
+		ExportedAt: time.Now().UTC(),
+		Project:    projectmodel.ExportedProject{ID: 7, Title: "Export Project"},
+		Tasks: []projectmodel.ExportedTask{
+			{ID: 1, Title: "Task", Files: []string{"task/log.txt", "task/gone.txt"}},
+		},
+		Files: []string{"project/spec.md", "../etc/passwd"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, archive.Write(&buf, exp, media))
+	require.ElementsMatch(t, []string{"task/gone.txt", "../etc/passwd"}, exp.MissingFiles)
+
+	arc, err := archive.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, "Export Project", arc.Manifest.Project.Title)
+	require.Len(t, arc.Manifest.Tasks, 1)
+	require.ElementsMatch(t, []string{"task/gone.txt", "../etc/passwd"}, arc.Missing())
+
+	dst := t.TempDir()
+	require.NoError(t, arc.Extract(dst, "imports/3"))
+	data, err := os.ReadFile(filepath.Join(dst, "imports", "3", "project", "spec.md"))
+	require.NoError(t, err)
+	require.Equal(t, "# Spec", string(data))
+}
+
+func TestCleanPath(t *testing.T) {
+	clean, err := archive.CleanPath("task/./a/../file.txt")
+	require.NoError(t, err)
+	require.Equal(t, "task/file.txt", clean)
+
+	for _, name := range []string{"", "/etc/passwd", "../x", `..\x`, "a/../../x"} {
+		_, err := archive.CleanPath(name)
+		require.ErrorIs(t, err, archive.ErrUnsafePath, name)
+	}
+}
@@ -0,0 +1,65 @@
+// Package boardimport converts board exports of other tools into projectmodels.ImportedBoard.
+package boardimport
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// Parser reads one export format
+type Parser interface {
+	Parse(r io.Reader) (*projectmodel.ImportedBoard, error)
+}
+
+var parsers = map[string]Parser{
+	"trello": TrelloParser{},
+	"jira":   JiraParser{},
+}
+
+// Formats lists the supported export formats
+func Formats() []string {
+	formats := make([]string, 0, len(parsers))
+	for name := range parsers {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Parse reads an export in the given format
+func Parse(format string, r io.Reader) (*projectmodel.ImportedBoard, error) {
+	p, ok := parsers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported import format %q, expected one of %s", format, strings.Join(Formats(), ", "))
+	}
+	return p.Parse(r)
+}
+
+func newBoard(source string) *projectmodel.ImportedBoard {
+	return &projectmodel.ImportedBoard{
+		Source:   source,
+		Stages:   []string{},
+		Cards:    []projectmodel.ImportedCard{},
+		Skipped:  []string{},
+		Unmapped: map[string]int{},
+
+		LabelColors: map[string]string{},
+	}
+}
+
+// addStage appends a stage keeping the first-seen order
+func addStage(b *projectmodel.ImportedBoard, name string) {
+	if name == "" {
+		return
+	}
+	for _, s := range b.Stages {
+		if s == name {
+			return
+		}
+	}
+	b.Stages = append(b.Stages, name)
+}
@@ -0,0 +1,96 @@
+package boardimport_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nais2008/hackanet2025/backend/pkg/boardimport"
+	"github.com/stretchr/testify/require"
+)
+
+const trelloExport = `{
+  "name": "Sprint board",
+  "lists": [
+    {"id": "l1", "name": "To Do", "closed": false},
+    {"id": "l2", "name": "Done", "closed": false},
+    {"id": "l3", "name": "Old", "closed": true}
+  ],
+  "cards": [
+    {"id": "5f5a1c000000000000000001", "name": "Write spec", "desc": "First line\nmore", "idList": "l1",
+     "labels": [{"name": "Docs", "color": "blue"}, {"name": "", "color": "red"}],
+     "due": "2025-04-20T12:00:00.000Z", "idMembers": ["m1"], "idChecklists": ["c1", "c2"]},
+    {"id": "c2", "name": "Ship", "idList": "l2", "due": "2025-04-18T09:00:00.000Z", "dueComplete": true},
+    {"id": "c3", "name": "Archived", "idList": "l1", "closed": true},
+    {"id": "c4", "name": "In old list", "idList": "l3"}
+  ],
+  "actions": [
+    {"type": "commentCard", "date": "2025-04-19T10:00:00.000Z",
+     "data": {"text": "Looks good", "card": {"id": "5f5a1c000000000000000001"}},
+     "memberCreator": {"username": "anna"}},
+    {"type": "createCard", "date": "2025-04-17T08:00:00.000Z", "data": {"card": {"id": "c2"}}}
+  ]
+}`
+
+func TestTrelloParser(t *testing.T) {
+	board, err := boardimport.Parse("trello", strings.NewReader(trelloExport))
+	require.NoError(t, err)
+
+	require.Equal(t, "Sprint board", board.Name)
+	require.Equal(t, []string{"To Do", "Done"}, board.Stages)
+	require.Len(t, board.Cards, 2)
+	require.Len(t, board.Skipped, 2)
+
+	spec := board.Cards[0]
+	require.Equal(t, "To Do", spec.Stage)
+	require.Equal(t, []string{"Docs", "red"}, spec.Labels)
+	require.Equal(t, map[string]string{"Docs": "#0079bf", "red": "#eb5a46"}, board.LabelColors)
+	require.NotNil(t, spec.Due)
+	require.NotNil(t, spec.CreatedAt)
+	require.Equal(t, 2020, spec.CreatedAt.Year())
+	require.Nil(t, spec.CompletedAt)
+	require.Len(t, spec.Comments, 1)
+	require.Equal(t, "anna", spec.Comments[0].Author)
+
+	ship := board.Cards[1]
+	require.NotNil(t, ship.CompletedAt)
+	require.Equal(t, 17, ship.CreatedAt.Day())
+
+	require.Equal(t, 1, board.Unmapped["members"])
+	require.Equal(t, 2, board.Unmapped["checklists"])
+}
+
+const jiraExport = "Summary,Issue key,Issue id,Status,Priority,Labels,Labels,Created,Resolved,Comment,Comment\n" +
+	"Login page,HACK-1,10001,Done,High,ui,auth,01/Apr/25 9:15 AM,03/Apr/25 5:00 PM,\"02/Apr/25 10:00 AM;557058:abc;Needs review; later\",\n" +
+	"API,HACK-2,10002,In Progress,Low,,,2025-04-02 10:00,,,\n" +
+	",HACK-3,10003,To Do,,,,,,,\n"
+
+func TestJiraParser(t *testing.T) {
+	board, err := boardimport.Parse("jira", strings.NewReader(jiraExport))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"Done", "In Progress"}, board.Stages)
+	require.Len(t, board.Cards, 2)
+	require.Len(t, board.Skipped, 1)
+
+	login := board.Cards[0]
+	require.Equal(t, "HACK-1", login.Key)
+	require.Equal(t, "high", login.Priority)
+	require.Equal(t, []string{"ui", "auth"}, login.Labels)
+	require.NotNil(t, login.CreatedAt)
+	require.NotNil(t, login.CompletedAt)
+	require.Equal(t, 3, login.CompletedAt.Day())
+	require.Len(t, login.Comments, 1)
+	require.Equal(t, "Needs review; later", login.Comments[0].Text)
+
+	api := board.Cards[1]
+	require.Nil(t, api.CompletedAt)
+	require.Equal(t, 2, api.CreatedAt.Day())
+
+	require.Equal(t, "low", api.Priority)
+	require.Zero(t, board.Unmapped["Priority"])
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	_, err := boardimport.Parse("asana", strings.NewReader("{}"))
+	require.Error(t, err)
+}
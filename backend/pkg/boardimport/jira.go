@@ -0,0 +1,149 @@
+package boardimport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// JiraParser reads the CSV export of a Jira issue search
+type JiraParser struct{}
+
+// jiraDateLayouts are the date formats Jira uses in CSV exports depending on the instance settings
+var jiraDateLayouts = []string{
+	"02/Jan/06 3:04 PM",
+	"02/Jan/06 15:04",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05.000-0700",
+	"2006-01-02",
+}
+
+// jiraDoneStatuses are treated as completed when the Resolved column is empty
+var jiraDoneStatuses = map[string]bool{"done": true, "closed": true, "resolved": true}
+
+// jiraPriorities maps Jira's default priority schemes to task priorities
+var jiraPriorities = map[string]string{
+	"highest":  projectmodel.PriorityCritical,
+	"blocker":  projectmodel.PriorityCritical,
+	"critical": projectmodel.PriorityCritical,
+	"high":     projectmodel.PriorityHigh,
+	"major":    projectmodel.PriorityHigh,
+	"medium":   projectmodel.PriorityMedium,
+	"low":      projectmodel.PriorityLow,
+	"lowest":   projectmodel.PriorityLow,
+	"minor":    projectmodel.PriorityLow,
+	"trivial":  projectmodel.PriorityLow,
+}
+
+// Parse maps statuses to stages, issues to tasks, labels to tags and comments to comments.
+// Jira repeats columns such as Labels and Comment once per value, so columns are read by position.
+func (JiraParser) Parse(r io.Reader) (*projectmodel.ImportedBoard, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid Jira export: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	b := newBoard("jira")
+	line := 1
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("invalid Jira export at line %d: %w", line, err)
+		}
+
+		card := projectmodel.ImportedCard{Labels: []string{}}
+		var resolved *time.Time
+		for i, value := range record {
+			if i >= len(header) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			switch strings.ToLower(header[i]) {
+			case "summary":
+				card.Title = value
+			case "issue key":
+				card.Key = value
+			case "status":
+				card.Stage = value
+			case "description":
+				card.Description = value
+			case "priority":
+				if p, ok := jiraPriorities[strings.ToLower(value)]; ok {
+					card.Priority = p
+				} else {
+					b.Unmapped[header[i]]++
+				}
+			case "labels":
+				card.Labels = append(card.Labels, value)
+			case "due date", "due":
+				card.Due = parseJiraDate(value)
+			case "created":
+				card.CreatedAt = parseJiraDate(value)
+			case "resolved":
+				resolved = parseJiraDate(value)
+			case "comment":
+				card.Comments = append(card.Comments, parseJiraComment(value))
+			case "issue id", "project name", "project key":
+				// Идентификаторы источника, не переносятся
+			default:
+				b.Unmapped[header[i]]++
+			}
+		}
+
+		if card.Title == "" {
+			b.Skipped = append(b.Skipped, fmt.Sprintf("line %d has no summary", line))
+			continue
+		}
+		if card.Key == "" {
+			card.Key = fmt.Sprintf("line-%d", line)
+		}
+		if resolved != nil {
+			card.CompletedAt = resolved
+		} else if jiraDoneStatuses[strings.ToLower(card.Stage)] {
+			card.CompletedAt = card.CreatedAt
+		}
+		addStage(b, card.Stage)
+		b.Cards = append(b.Cards, card)
+	}
+	return b, nil
+}
+
+func parseJiraDate(value string) *time.Time {
+	for _, layout := range jiraDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// parseJiraComment splits a "date;author;text" comment cell; the text may itself contain semicolons
+func parseJiraComment(value string) projectmodel.ImportedComment {
+	parts := strings.SplitN(value, ";", 3)
+	if len(parts) < 3 {
+		return projectmodel.ImportedComment{Text: value}
+	}
+	return projectmodel.ImportedComment{
+		Author:    parts[1],
+		Text:      parts[2],
+		CreatedAt: parseJiraDate(parts[0]),
+	}
+}
@@ -0,0 +1,175 @@
+package boardimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// TrelloParser reads the JSON export of a Trello board
+type TrelloParser struct{}
+
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID          string            `json:"id"`
+		Name        string            `json:"name"`
+		Desc        string            `json:"desc"`
+		IDList      string            `json:"idList"`
+		Closed      bool              `json:"closed"`
+		Due         *time.Time        `json:"due"`
+		DueComplete bool              `json:"dueComplete"`
+		Labels      []trelloLabel     `json:"labels"`
+		IDMembers   []string          `json:"idMembers"`
+		IDChecklist []string          `json:"idChecklists"`
+		Attachments []json.RawMessage `json:"attachments"`
+	} `json:"cards"`
+	Actions []struct {
+		Type string    `json:"type"`
+		Date time.Time `json:"date"`
+		Data struct {
+			Text string `json:"text"`
+			Card struct {
+				ID string `json:"id"`
+			} `json:"card"`
+		} `json:"data"`
+		MemberCreator struct {
+			Username string `json:"username"`
+		} `json:"memberCreator"`
+	} `json:"actions"`
+}
+
+type trelloLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// trelloColors maps Trello's named label colors to hex values
+var trelloColors = map[string]string{
+	"green":  "#61bd4f",
+	"yellow": "#f2d600",
+	"orange": "#ff9f1a",
+	"red":    "#eb5a46",
+	"purple": "#c377e0",
+	"blue":   "#0079bf",
+	"sky":    "#00c2e0",
+	"lime":   "#51e898",
+	"pink":   "#ff78cb",
+	"black":  "#344563",
+}
+
+// Parse maps lists to stages, cards to tasks, labels to tags and comment actions to comments
+func (TrelloParser) Parse(r io.Reader) (*projectmodel.ImportedBoard, error) {
+	var tb trelloBoard
+	if err := json.NewDecoder(r).Decode(&tb); err != nil {
+		return nil, fmt.Errorf("invalid Trello export: %w", err)
+	}
+
+	b := newBoard("trello")
+	b.Name = tb.Name
+
+	lists := map[string]string{}
+	closedLists := map[string]bool{}
+	for _, l := range tb.Lists {
+		lists[l.ID] = l.Name
+		if l.Closed {
+			closedLists[l.ID] = true
+			continue
+		}
+		addStage(b, l.Name)
+	}
+
+	created := map[string]time.Time{}
+	comments := map[string][]projectmodel.ImportedComment{}
+	for _, a := range tb.Actions {
+		switch a.Type {
+		case "createCard":
+			created[a.Data.Card.ID] = a.Date
+		case "commentCard":
+			date := a.Date
+			comments[a.Data.Card.ID] = append(comments[a.Data.Card.ID], projectmodel.ImportedComment{
+				Author:    a.MemberCreator.Username,
+				Text:      a.Data.Text,
+				CreatedAt: &date,
+			})
+		}
+	}
+
+	for _, c := range tb.Cards {
+		switch {
+		case c.Closed:
+			b.Skipped = append(b.Skipped, fmt.Sprintf("card %q is archived", c.Name))
+			continue
+		case closedLists[c.IDList]:
+			b.Skipped = append(b.Skipped, fmt.Sprintf("card %q is in archived list %q", c.Name, lists[c.IDList]))
+			continue
+		case c.Name == "":
+			b.Skipped = append(b.Skipped, fmt.Sprintf("card %s has no name", c.ID))
+			continue
+		}
+
+		card := projectmodel.ImportedCard{
+			Key:         c.ID,
+			Title:       c.Name,
+			Description: c.Desc,
+			Stage:       lists[c.IDList],
+			Labels:      []string{},
+			Due:         c.Due,
+			Comments:    comments[c.ID],
+		}
+		for _, l := range c.Labels {
+			name := l.Name
+			if name == "" {
+				name = l.Color
+			}
+			if name == "" {
+				continue
+			}
+			card.Labels = append(card.Labels, name)
+			if color, ok := trelloColors[l.Color]; ok {
+				b.LabelColors[name] = color
+			}
+		}
+		if t, ok := created[c.ID]; ok {
+			card.CreatedAt = &t
+		} else if t, ok := objectIDTime(c.ID); ok {
+			card.CreatedAt = &t
+		}
+		if c.DueComplete && c.Due != nil {
+			card.CompletedAt = c.Due
+		}
+
+		if len(c.IDMembers) > 0 {
+			b.Unmapped["members"] += len(c.IDMembers)
+		}
+		if len(c.IDChecklist) > 0 {
+			b.Unmapped["checklists"] += len(c.IDChecklist)
+		}
+		if len(c.Attachments) > 0 {
+			b.Unmapped["attachments"] += len(c.Attachments)
+		}
+		b.Cards = append(b.Cards, card)
+	}
+	return b, nil
+}
+
+// objectIDTime extracts the creation time embedded in the first 4 bytes of a Trello object ID
+func objectIDTime(id string) (time.Time, bool) {
+	if len(id) != 24 {
+		return time.Time{}, false
+	}
+	sec, err := strconv.ParseInt(id[:8], 16, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0).UTC(), true
+}
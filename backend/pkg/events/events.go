@@ -0,0 +1,101 @@
+// Package events is an in-process publish/subscribe bus for changes made through the repository.
+// Events are published after the change is committed, so subscribers always see it in the database.
+package events
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Типы событий
+const (
+	TaskAssigned   = "task.assigned"
+	TaskUnassigned = "task.unassigned"
+	TaskWatched    = "task.watched"
+	TaskUnwatched  = "task.unwatched"
+	// CommentMentioned is published for every user mentioned in a new or edited comment
+	CommentMentioned = "comment.mentioned"
+)
+
+// Event describes one committed change
+type Event struct {
+	Type      string
+	ProjectID int
+	TaskID    int
+	UserID    int  // user the event is about, e.g. the assignee
+	ActorID   *int // user who made the change, nil for system changes
+	At        time.Time
+}
+
+// Handler reacts to an event. Handlers run synchronously in the publisher's goroutine
+// and should hand slow work off to their own goroutine.
+type Handler func(ctx context.Context, e Event)
+
+type subscription struct {
+	id      int
+	types   []string
+	handler Handler
+}
+
+// Bus delivers published events to the handlers subscribed to their type.
+// A nil *Bus is valid and drops every event.
+type Bus struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   []subscription
+}
+
+// NewBus creates an empty bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers h for the given event types, or for every event when no types are given.
+// The returned function removes the subscription.
+func (b *Bus) Subscribe(h Handler, types ...string) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, subscription{id: id, types: types, handler: h})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.subs = slices.DeleteFunc(b.subs, func(s subscription) bool { return s.id == id })
+	}
+}
+
+// Publish calls the matching handlers in subscription order.
+// A panicking handler is logged and does not stop delivery to the others.
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	if b == nil {
+		return
+	}
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	b.mu.RLock()
+	var handlers []Handler
+	for _, s := range b.subs {
+		if len(s.types) == 0 || slices.Contains(s.types, e.Type) {
+			handlers = append(handlers, s.handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		deliver(ctx, h, e)
+	}
+}
+
+func deliver(ctx context.Context, h Handler, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Обработчик события %s завершился с паникой: %v", e.Type, r)
+		}
+	}()
+	h(ctx, e)
+}
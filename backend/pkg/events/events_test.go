@@ -0,0 +1,55 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nais2008/hackanet2025/backend/pkg/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusDeliversByType(t *testing.T) {
+	bus := events.NewBus()
+	var assigned, all []events.Event
+	bus.Subscribe(func(_ context.Context, e events.Event) { assigned = append(assigned, e) }, events.TaskAssigned)
+	bus.Subscribe(func(_ context.Context, e events.Event) { all = append(all, e) })
+
+	bus.Publish(context.Background(), events.Event{Type: events.TaskAssigned, TaskID: 1, UserID: 2})
+	bus.Publish(context.Background(), events.Event{Type: events.TaskWatched, TaskID: 1, UserID: 3})
+
+	require.Len(t, assigned, 1)
+	require.Equal(t, 2, assigned[0].UserID)
+	require.False(t, assigned[0].At.IsZero())
+	require.Len(t, all, 2)
+}
+
+func TestBusUnsubscribe(t *testing.T) {
+	bus := events.NewBus()
+	calls := 0
+	unsubscribe := bus.Subscribe(func(context.Context, events.Event) { calls++ })
+
+	bus.Publish(context.Background(), events.Event{Type: events.TaskAssigned})
+	unsubscribe()
+	bus.Publish(context.Background(), events.Event{Type: events.TaskAssigned})
+
+	require.Equal(t, 1, calls)
+}
+
+func TestBusRecoversFromPanics(t *testing.T) {
+	bus := events.NewBus()
+	delivered := false
+	bus.Subscribe(func(context.Context, events.Event) { panic("boom") })
+	bus.Subscribe(func(context.Context, events.Event) { delivered = true })
+
+	require.NotPanics(t, func() {
+		bus.Publish(context.Background(), events.Event{Type: events.TaskUnassigned})
+	})
+	require.True(t, delivered)
+}
+
+func TestNilBus(t *testing.T) {
+	var bus *events.Bus
+	require.NotPanics(t, func() {
+		bus.Publish(context.Background(), events.Event{Type: events.TaskAssigned})
+	})
+}
@@ -0,0 +1,217 @@
+// Package markdown renders the Markdown subset used in task comments to safe HTML.
+//
+// Raw HTML in the source is never passed through: the text is escaped before any
+// markup is added, and links are only kept for http, https, mailto and
+// site-relative targets. Supported syntax: paragraphs, headings, fenced code
+// blocks, block quotes, bullet and numbered lists, horizontal rules, inline code,
+// bold, italic, strikethrough, links and @mentions.
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingRe   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletRe    = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedRe   = regexp.MustCompile(`^\s*\d{1,9}[.)]\s+(.*)$`)
+	fenceRe     = regexp.MustCompile("^\\s*(```|~~~)\\s*([A-Za-z0-9_+-]*)\\s*$")
+	codeSpanRe  = regexp.MustCompile("`([^`]+)`")
+	linkRe      = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	strongRe    = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	emRe        = regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`)
+	underEmRe   = regexp.MustCompile(`(^|[^\w])_([^_\s](?:[^_]*[^_\s])?)_($|[^\w])`)
+	strikeRe    = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	mentionRe   = regexp.MustCompile(`(^|[^\w@/.])@([A-Za-z0-9_](?:[A-Za-z0-9_.-]*[A-Za-z0-9_])?)`)
+	placeholder = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// Render converts Markdown to sanitized HTML
+func Render(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\x00", "")
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(src, "\n"))
+	return b.String()
+}
+
+// Mentions returns the distinct usernames mentioned as @username outside of code, in order of appearance
+func Mentions(src string) []string {
+	var (
+		names []string
+		seen  = map[string]bool{}
+		fence string
+	)
+	for _, line := range strings.Split(src, "\n") {
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case fence == m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		line = codeSpanRe.ReplaceAllString(line, " ")
+		for _, m := range mentionRe.FindAllStringSubmatch(line, -1) {
+			if !seen[m[2]] {
+				seen[m[2]] = true
+				names = append(names, m[2])
+			}
+		}
+	}
+	return names
+}
+
+// renderBlocks writes the block structure of the lines
+func renderBlocks(b *strings.Builder, lines []string) {
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			b.WriteString("<p>")
+			for i, l := range para {
+				if i > 0 {
+					b.WriteString("<br>\n")
+				}
+				b.WriteString(inline(strings.TrimSpace(l)))
+			}
+			b.WriteString("</p>\n")
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case fenceRe.MatchString(line):
+			flush()
+			m := fenceRe.FindStringSubmatch(line)
+			var code []string
+			for i++; i < len(lines); i++ {
+				if c := fenceRe.FindStringSubmatch(lines[i]); c != nil && c[1] == m[1] && c[2] == "" {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			if m[2] != "" {
+				fmt.Fprintf(b, `<pre><code class="language-%s">`, strings.ToLower(m[2]))
+			} else {
+				b.WriteString("<pre><code>")
+			}
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+		case headingRe.MatchString(line):
+			flush()
+			m := headingRe.FindStringSubmatch(line)
+			fmt.Fprintf(b, "<h%d>%s</h%d>\n", len(m[1]), inline(m[2]), len(m[1]))
+		case isRule(line):
+			flush()
+			b.WriteString("<hr>\n")
+		case strings.HasPrefix(strings.TrimLeft(line, " "), ">"):
+			flush()
+			var quoted []string
+			for ; i < len(lines); i++ {
+				l := strings.TrimLeft(lines[i], " ")
+				if !strings.HasPrefix(l, ">") {
+					break
+				}
+				quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(l, ">"), " "))
+			}
+			i--
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+		case bulletRe.MatchString(line), orderedRe.MatchString(line):
+			flush()
+			re, tag := bulletRe, "ul"
+			if !bulletRe.MatchString(line) {
+				re, tag = orderedRe, "ol"
+			}
+			fmt.Fprintf(b, "<%s>\n", tag)
+			for ; i < len(lines); i++ {
+				m := re.FindStringSubmatch(lines[i])
+				if m == nil {
+					break
+				}
+				fmt.Fprintf(b, "<li>%s</li>\n", inline(m[1]))
+			}
+			i--
+			fmt.Fprintf(b, "</%s>\n", tag)
+		default:
+			para = append(para, line)
+		}
+	}
+	flush()
+}
+
+// isRule reports whether the line is a horizontal rule: three or more of the same -, * or _
+func isRule(line string) bool {
+	marks := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, line)
+	return len(marks) >= 3 && strings.Trim(marks, marks[:1]) == "" && strings.ContainsAny(marks[:1], "-*_")
+}
+
+// inline renders the inline markup of one line. Code spans and links are swapped for
+// placeholders first, so emphasis and mentions never apply inside them.
+func inline(text string) string {
+	var parts []string
+	hold := func(s string) string {
+		parts = append(parts, s)
+		return fmt.Sprintf("\x00%d\x00", len(parts)-1)
+	}
+
+	text = codeSpanRe.ReplaceAllStringFunc(text, func(s string) string {
+		return hold("<code>" + html.EscapeString(codeSpanRe.FindStringSubmatch(s)[1]) + "</code>")
+	})
+	text = html.EscapeString(text)
+	text = linkRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkRe.FindStringSubmatch(s)
+		label := emphasis(m[1])
+		if !safeURL(html.UnescapeString(m[2])) {
+			return hold(label)
+		}
+		return hold(fmt.Sprintf(`<a href="%s" rel="nofollow noopener noreferrer">%s</a>`, m[2], label))
+	})
+	text = emphasis(text)
+	text = mentionRe.ReplaceAllString(text, `$1<span class="mention">@$2</span>`)
+
+	// Ссылки могут содержать код, поэтому подстановка повторяется до полного раскрытия
+	for placeholder.MatchString(text) {
+		text = placeholder.ReplaceAllStringFunc(text, func(s string) string {
+			var n int
+			fmt.Sscanf(placeholder.FindStringSubmatch(s)[1], "%d", &n)
+			return parts[n]
+		})
+	}
+	return text
+}
+
+func emphasis(text string) string {
+	text = strongRe.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = emRe.ReplaceAllString(text, "<em>$1</em>")
+	text = underEmRe.ReplaceAllString(text, "$1<em>$2</em>$3")
+	return strikeRe.ReplaceAllString(text, "<del>$1</del>")
+}
+
+// safeURL allows web and mail links and links within the site
+func safeURL(u string) bool {
+	u = strings.ToLower(strings.TrimSpace(u))
+	for _, prefix := range []string{"http://", "https://", "mailto:", "/", "#"} {
+		if strings.HasPrefix(u, prefix) {
+			return true
+		}
+	}
+	return false
+}
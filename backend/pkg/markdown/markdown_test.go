@@ -0,0 +1,48 @@
+package markdown_test
+
+import (
+	"testing"
+
+	"github.com/nais2008/hackanet2025/backend/pkg/markdown"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	cases := []struct{ src, want string }{
+		{"hello", "<p>hello</p>\n"},
+		{"one\ntwo\n\nthree", "<p>one<br>\ntwo</p>\n<p>three</p>\n"},
+		{"## Title ##", "<h2>Title</h2>\n"},
+		{"**bold**, *em*, _em_ and ~~gone~~", "<p><strong>bold</strong>, <em>em</em>, <em>em</em> and <del>gone</del></p>\n"},
+		{"snake_case_name", "<p>snake_case_name</p>\n"},
+		{"run `a *b* <c>`", "<p>run <code>a *b* &lt;c&gt;</code></p>\n"},
+		{"- a\n- b\n\n1. x\n2. y", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n<ol>\n<li>x</li>\n<li>y</li>\n</ol>\n"},
+		{"> quoted **text**\n> more", "<blockquote>\n<p>quoted <strong>text</strong><br>\nmore</p>\n</blockquote>\n"},
+		{"---", "<hr>\n"},
+		{"```go\nif a < b {\n```", "<pre><code class=\"language-go\">if a &lt; b {</code></pre>\n"},
+		{"see [docs](https://example.com/a_b_c?x=1&y=2)", `<p>see <a href="https://example.com/a_b_c?x=1&amp;y=2" rel="nofollow noopener noreferrer">docs</a></p>` + "\n"},
+		{"ping @ivan.petrov, mail a@b.c", `<p>ping <span class="mention">@ivan.petrov</span>, mail a@b.c</p>` + "\n"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, markdown.Render(c.src), c.src)
+	}
+}
+
+func TestRenderSanitizes(t *testing.T) {
+	cases := []struct{ src, want string }{
+		{`<script>alert(1)</script>`, "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{`<img src=x onerror="alert(1)">`, "<p>&lt;img src=x onerror=&#34;alert(1)&#34;&gt;</p>\n"},
+		{`[click](javascript:alert(1))`, "<p>click)</p>\n"},
+		{`[click](JavaScript:alert`, "<p>[click](JavaScript:alert</p>\n"},
+		{`[x](data:text/html;base64,PHNjcmlwdD4=)`, "<p>x</p>\n"},
+		{`[x](https://e.com/"onmouseover="alert(1))`, `<p><a href="https://e.com/&#34;onmouseover=&#34;alert(1" rel="nofollow noopener noreferrer">x</a>)</p>` + "\n"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, markdown.Render(c.src), c.src)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	src := "@anna and @ivan.petrov, again @anna.\n`@code` me@mail.ru\n```\n@fenced\n```\n> @quoted"
+	require.Equal(t, []string{"anna", "ivan.petrov", "quoted"}, markdown.Mentions(src))
+	require.Empty(t, markdown.Mentions("no mentions here"))
+}
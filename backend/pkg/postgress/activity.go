@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// defaultActivityLimit is the page size of the activity feed when none is requested
+const defaultActivityLimit = 50
+
+type actorCtxKey struct{}
+
+// WithActor returns a context whose mutations are recorded in the activity feed as made by userID
+func WithActor(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, actorCtxKey{}, userID)
+}
+
+// ActorFromContext returns the user stored by WithActor
+func ActorFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(actorCtxKey{}).(int)
+	return id, ok
+}
+
+// beginFunc runs fn in a transaction attributed to the context actor,
+// so that the task history triggers record who made the change
+func (db *DB) beginFunc(ctx context.Context, fn func(pgx.Tx) error) error {
+	return pgx.BeginFunc(ctx, db.Pool, func(tx pgx.Tx) error {
+		if id, ok := ActorFromContext(ctx); ok {
+			if _, err := tx.Exec(ctx, `SELECT set_config('app.actor_id', $1, TRUE)`, strconv.Itoa(id)); err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+}
+
+// ActivityFilter selects a page of the activity feed
+type ActivityFilter struct {
+	ActorID        int      // only entries made by this user
+	Types          []string // only entries of these types
+	Before         int64    // only entries older than this entry ID, for paging
+	SinceLastVisit bool     // only entries newer than the user's last visit marker
+	UserID         int      // user whose last visit marker is used
+	Limit          int
+}
+
+// recordActivity appends a typed entry made by the context actor to the project's history
+func recordActivity(ctx context.Context, q querier, projectID int, kind string, payload any) error {
+	if payload == nil {
+		payload = map[string]any{}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode activity payload: %w", err)
+	}
+	var actor *int
+	if id, ok := ActorFromContext(ctx); ok {
+		actor = &id
+	}
+	_, err = q.Exec(ctx, `
+		INSERT INTO project_activity (project_id, actor_id, type, payload)
+		VALUES ($1, $2, $3, $4)`, projectID, actor, kind, data)
+	if err != nil {
+		return fmt.Errorf("failed to record activity: %w", err)
+	}
+	return nil
+}
+
+// GetProjectActivity returns a page of a project's activity feed, newest first
+func (db *DB) GetProjectActivity(ctx context.Context, projectID int, f ActivityFilter) ([]projectmodel.Activity, error) {
+	if f.Limit <= 0 || f.Limit > 200 {
+		f.Limit = defaultActivityLimit
+	}
+	var actor *int
+	if f.ActorID > 0 {
+		actor = &f.ActorID
+	}
+	var before *int64
+	if f.Before > 0 {
+		before = &f.Before
+	}
+	var types []string
+	if len(f.Types) > 0 {
+		types = f.Types
+	}
+	var after int64
+	if f.SinceLastVisit {
+		marker, err := db.activityMarker(ctx, f.UserID, projectID)
+		if err != nil {
+			return nil, err
+		}
+		after = marker
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT a.id, a.project_id, a.actor_id, a.type, a.payload, a.created_at
+		FROM project_activity a
+		JOIN project_project p ON p.id = a.project_id
+		WHERE a.project_id = $1
+		  AND p.workspace_id IS NOT DISTINCT FROM $2
+		  AND ($3::int IS NULL OR a.actor_id = $3)
+		  AND ($4::text[] IS NULL OR a.type = ANY($4))
+		  AND ($5::bigint IS NULL OR a.id < $5)
+		  AND a.id > $6
+		ORDER BY a.id DESC
+		LIMIT $7`, projectID, workspaceArg(ctx), actor, types, before, after, f.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activity: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []projectmodel.Activity{}
+	for rows.Next() {
+		var a projectmodel.Activity
+		if err := rows.Scan(&a.ID, &a.ProjectID, &a.ActorID, &a.Type, &a.Payload, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		entries = append(entries, a)
+	}
+	return entries, rows.Err()
+}
+
+// GetActivityVisit returns the user's last visit marker and the number of entries made after it
+func (db *DB) GetActivityVisit(ctx context.Context, userID, projectID int) (*projectmodel.ActivityVisit, error) {
+	v := projectmodel.ActivityVisit{ProjectID: projectID}
+	err := db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(v.last_seen_id, 0), v.visited_at,
+		       (SELECT COUNT(*) FROM project_activity a
+		        WHERE a.project_id = p.id AND a.id > COALESCE(v.last_seen_id, 0))
+		FROM project_project p
+		LEFT JOIN project_activity_visits v ON v.project_id = p.id AND v.user_id = $2
+		WHERE p.id = $1 AND p.workspace_id IS NOT DISTINCT FROM $3`,
+		projectID, userID, workspaceArg(ctx)).Scan(&v.LastSeenID, &v.VisitedAt, &v.Unread)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("project not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to query activity visit: %w", err)
+	}
+	return &v, nil
+}
+
+// MarkActivitySeen moves the user's last visit marker to the newest entry of the project
+func (db *DB) MarkActivitySeen(ctx context.Context, userID, projectID int) error {
+	tag, err := db.Pool.Exec(ctx, `
+		INSERT INTO project_activity_visits (user_id, project_id, last_seen_id, visited_at)
+		SELECT $1, p.id, COALESCE((SELECT MAX(id) FROM project_activity WHERE project_id = p.id), 0), NOW()
+		FROM project_project p
+		WHERE p.id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3
+		ON CONFLICT (user_id, project_id) DO UPDATE
+		SET last_seen_id = GREATEST(project_activity_visits.last_seen_id, EXCLUDED.last_seen_id),
+		    visited_at = EXCLUDED.visited_at`, userID, projectID, workspaceArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to mark activity seen: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("project not found: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+func (db *DB) activityMarker(ctx context.Context, userID, projectID int) (int64, error) {
+	var marker int64
+	err := db.Pool.QueryRow(ctx, `
+		SELECT last_seen_id FROM project_activity_visits
+		WHERE user_id = $1 AND project_id = $2`, userID, projectID).Scan(&marker)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("failed to query activity marker: %w", err)
+	}
+	return marker, nil
+}
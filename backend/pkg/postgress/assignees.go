@@ -0,0 +1,274 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nais2008/hackanet2025/backend/pkg/events"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// ErrNotMember is returned when a user who is not a project member is assigned to or watches a task
+var ErrNotMember = errors.New("user is not a member of the project")
+
+// lockTask locks a task of the project in the active workspace
+func lockTask(ctx context.Context, tx pgx.Tx, projectID, taskID int) error {
+	tag, err := tx.Exec(ctx, `
+		SELECT 1 FROM task_task t
+		JOIN project_project p ON p.id = t.project_id
+		WHERE t.id = $1 AND t.project_id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3
+		FOR UPDATE OF t`, taskID, projectID, workspaceArg(ctx))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("task %d not found in project %d: %w", taskID, projectID, pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// checkMembers returns ErrNotMember for the first user that is not a member of the project
+func checkMembers(ctx context.Context, q querier, projectID int, userIDs []int) error {
+	rows, err := q.Query(ctx, `SELECT user_id FROM project_members WHERE project_id = $1 AND user_id = ANY($2)`, projectID, userIDs)
+	if err != nil {
+		return err
+	}
+	members, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return err
+	}
+	for _, id := range userIDs {
+		if !slices.Contains(members, id) {
+			return fmt.Errorf("%w: user %d, project %d", ErrNotMember, id, projectID)
+		}
+	}
+	return nil
+}
+
+// newEvent builds an event made by the context actor
+func newEvent(ctx context.Context, kind string, projectID, taskID, userID int) events.Event {
+	e := events.Event{Type: kind, ProjectID: projectID, TaskID: taskID, UserID: userID}
+	if id, ok := ActorFromContext(ctx); ok {
+		e.ActorID = &id
+	}
+	return e
+}
+
+// publish delivers events collected inside a transaction once it has committed
+func (db *DB) publish(ctx context.Context, evs []events.Event) {
+	for _, e := range evs {
+		db.Events.Publish(ctx, e)
+	}
+}
+
+// addAssignees assigns the users to the task and makes them watchers, returning the events of the changes
+func addAssignees(ctx context.Context, tx pgx.Tx, projectID, taskID int, userIDs []int) ([]events.Event, error) {
+	if err := checkMembers(ctx, tx, projectID, userIDs); err != nil {
+		return nil, err
+	}
+	var actor *int
+	if id, ok := ActorFromContext(ctx); ok {
+		actor = &id
+	}
+	rows, err := tx.Query(ctx, `
+		INSERT INTO task_assignees (task_id, user_id, assigned_by)
+		SELECT $1, u, $3 FROM unnest($2::int[]) u
+		ON CONFLICT DO NOTHING
+		RETURNING user_id`, taskID, userIDs, actor)
+	if err != nil {
+		return nil, err
+	}
+	assigned, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+	evs, err := addWatchers(ctx, tx, projectID, taskID, assigned)
+	if err != nil {
+		return nil, err
+	}
+	if len(assigned) == 0 {
+		return evs, nil
+	}
+	slices.Sort(assigned)
+	err = recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskUpdated, map[string]any{
+		"task_id":  taskID,
+		"fields":   []string{"assignees"},
+		"assigned": assigned,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range assigned {
+		evs = append(evs, newEvent(ctx, events.TaskAssigned, projectID, taskID, userID))
+	}
+	return evs, nil
+}
+
+// addWatchers subscribes the users to the task; the caller checks membership
+func addWatchers(ctx context.Context, tx pgx.Tx, projectID, taskID int, userIDs []int) ([]events.Event, error) {
+	rows, err := tx.Query(ctx, `
+		INSERT INTO task_watchers (task_id, user_id)
+		SELECT $1, u FROM unnest($2::int[]) u
+		ON CONFLICT DO NOTHING
+		RETURNING user_id`, taskID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	added, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+	var evs []events.Event
+	for _, userID := range added {
+		evs = append(evs, newEvent(ctx, events.TaskWatched, projectID, taskID, userID))
+	}
+	return evs, nil
+}
+
+// AssignTask adds assignees to a task; assignees also start watching it.
+// Every user must be a member of the task's project.
+func (db *DB) AssignTask(ctx context.Context, projectID, taskID int, userIDs []int) error {
+	var evs []events.Event
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := lockTask(ctx, tx, projectID, taskID)
+		if err != nil {
+			return err
+		}
+		evs, err = addAssignees(ctx, tx, projectID, taskID, userIDs)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to assign task: %w", err)
+	}
+	db.publish(ctx, evs)
+	return nil
+}
+
+// UnassignTask removes assignees from a task; they keep watching it
+func (db *DB) UnassignTask(ctx context.Context, projectID, taskID int, userIDs []int) error {
+	var evs []events.Event
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := lockTask(ctx, tx, projectID, taskID)
+		if err != nil {
+			return err
+		}
+		evs, err = removeAssignees(ctx, tx, projectID, taskID, userIDs)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unassign task: %w", err)
+	}
+	db.publish(ctx, evs)
+	return nil
+}
+
+// removeAssignees unassigns the users from the task, returning the events of the changes
+func removeAssignees(ctx context.Context, tx pgx.Tx, projectID, taskID int, userIDs []int) ([]events.Event, error) {
+	rows, err := tx.Query(ctx, `
+		DELETE FROM task_assignees WHERE task_id = $1 AND user_id = ANY($2)
+		RETURNING user_id`, taskID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil || len(removed) == 0 {
+		return nil, err
+	}
+	slices.Sort(removed)
+	err = recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskUpdated, map[string]any{
+		"task_id":    taskID,
+		"fields":     []string{"assignees"},
+		"unassigned": removed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var evs []events.Event
+	for _, userID := range removed {
+		evs = append(evs, newEvent(ctx, events.TaskUnassigned, projectID, taskID, userID))
+	}
+	return evs, nil
+}
+
+// WatchTask subscribes a project member to a task's changes
+func (db *DB) WatchTask(ctx context.Context, projectID, taskID, userID int) error {
+	var evs []events.Event
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := lockTask(ctx, tx, projectID, taskID)
+		if err != nil {
+			return err
+		}
+		if err := checkMembers(ctx, tx, projectID, []int{userID}); err != nil {
+			return err
+		}
+		evs, err = addWatchers(ctx, tx, projectID, taskID, []int{userID})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch task: %w", err)
+	}
+	db.publish(ctx, evs)
+	return nil
+}
+
+// UnwatchTask unsubscribes a user from a task's changes
+func (db *DB) UnwatchTask(ctx context.Context, projectID, taskID, userID int) error {
+	var evs []events.Event
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := lockTask(ctx, tx, projectID, taskID)
+		if err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `DELETE FROM task_watchers WHERE task_id = $1 AND user_id = $2`, taskID, userID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() > 0 {
+			evs = append(evs, newEvent(ctx, events.TaskUnwatched, projectID, taskID, userID))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unwatch task: %w", err)
+	}
+	db.publish(ctx, evs)
+	return nil
+}
+
+// removeFromProjectTasks unassigns a former member from every task of the project and stops their watching
+func removeFromProjectTasks(ctx context.Context, tx pgx.Tx, projectID, userID int) ([]events.Event, error) {
+	rows, err := tx.Query(ctx, `
+		DELETE FROM task_assignees a USING task_task t
+		WHERE t.id = a.task_id AND t.project_id = $1 AND a.user_id = $2
+		RETURNING a.task_id`, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	unassigned, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+	rows, err = tx.Query(ctx, `
+		DELETE FROM task_watchers w USING task_task t
+		WHERE t.id = w.task_id AND t.project_id = $1 AND w.user_id = $2
+		RETURNING w.task_id`, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	unwatched, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+
+	var evs []events.Event
+	for _, taskID := range unassigned {
+		evs = append(evs, newEvent(ctx, events.TaskUnassigned, projectID, taskID, userID))
+	}
+	for _, taskID := range unwatched {
+		evs = append(evs, newEvent(ctx, events.TaskUnwatched, projectID, taskID, userID))
+	}
+	return evs, nil
+}
@@ -0,0 +1,331 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nais2008/hackanet2025/backend/pkg/markdown"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// shortDescriptionLen limits the short description derived from an imported card's text
+const shortDescriptionLen = 200
+
+// ImportBoard creates tasks in a project from a parsed board.
+// Comment authors are matched by username; comments of unknown authors are attributed to the context actor.
+// Parts of the board the schema cannot store are counted in the report's unmapped fields.
+// Stage WIP limits and the blocked done policy apply as for tasks moved on the board.
+// With dryRun the import runs inside a transaction that is rolled back.
+func (db *DB) ImportBoard(ctx context.Context, projectID int, board *projectmodel.ImportedBoard, dryRun bool) (*projectmodel.BoardImportReport, error) {
+	report := &projectmodel.BoardImportReport{
+		DryRun:   dryRun,
+		Source:   board.Source,
+		TaskIDs:  map[string]int{},
+		Skipped:  append([]string{}, board.Skipped...),
+		Unmapped: map[string]int{},
+
+		StagesCreated: []string{},
+		LabelsCreated: []string{},
+	}
+	for field, n := range board.Unmapped {
+		report.Unmapped[field] = n
+	}
+
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := lockProject(ctx, tx, projectID); err != nil {
+			return err
+		}
+
+		stages, err := importStages(ctx, tx, projectID, board, report)
+		if err != nil {
+			return err
+		}
+		labels, err := importLabels(ctx, tx, projectID, board, report)
+		if err != nil {
+			return err
+		}
+		authors, err := commentAuthors(ctx, tx, board)
+		if err != nil {
+			return err
+		}
+		targets, err := reserveImportStages(ctx, tx, projectID, board, stages)
+		if err != nil {
+			return err
+		}
+		settings, err := projectSettings(ctx, tx, projectID)
+		if err != nil {
+			return err
+		}
+
+		for i, card := range board.Cards {
+			stageID := targets[i]
+			priority := card.Priority
+			if priority == "" {
+				priority = projectmodel.PriorityMedium
+			}
+			var (
+				id   int
+				done bool
+			)
+			err := tx.QueryRow(ctx, `
+				INSERT INTO task_task (project_id, stage_id, title, description, full_description, priority, deadline, created_at, completed_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, NOW()), $9)
+				RETURNING id, completed_at IS NOT NULL`,
+				projectID, stageID, card.Title, shortDescription(card.Description), card.Description,
+				priority, card.Due, card.CreatedAt, card.CompletedAt,
+			).Scan(&id, &done)
+			if err != nil {
+				return fmt.Errorf("card %s: %w", card.Key, err)
+			}
+			// Как и при переносе, задача с открытыми блокерами не может попасть в колонку «готово»
+			if done {
+				blockers, err := unresolvedBlockers(ctx, tx, id)
+				if err != nil {
+					return err
+				}
+				if _, err := applyBlockedDonePolicy(settings.BlockedDonePolicy, blockers); err != nil {
+					return fmt.Errorf("card %s: %w", card.Key, err)
+				}
+			}
+			report.TaskIDs[card.Key] = id
+			report.Created++
+
+			for _, name := range card.Labels {
+				labelID, ok := labels[strings.ToLower(name)]
+				if !ok {
+					continue
+				}
+				_, err := tx.Exec(ctx, `INSERT INTO task_labels (task_id, label_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, id, labelID)
+				if err != nil {
+					return fmt.Errorf("card %s: %w", card.Key, err)
+				}
+			}
+			if err := importComments(ctx, tx, id, card, authors, report); err != nil {
+				return fmt.Errorf("card %s: %w", card.Key, err)
+			}
+
+			err = recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskCreated, map[string]any{
+				"task_id":    id,
+				"title":      card.Title,
+				"source":     board.Source,
+				"source_key": card.Key,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, fmt.Errorf("failed to import board: %w", err)
+	}
+	return report, nil
+}
+
+// importStages matches board columns to the project's stages by title, creating the missing ones.
+// A created column counts as done when every card in it is completed.
+// The result maps lower-cased column names to stage IDs.
+func importStages(ctx context.Context, tx pgx.Tx, projectID int, board *projectmodel.ImportedBoard, report *projectmodel.BoardImportReport) (map[string]int, error) {
+	rows, err := tx.Query(ctx, `SELECT id, title FROM project_stages WHERE project_id = $1 ORDER BY position, id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	stages := map[string]int{}
+	for rows.Next() {
+		var (
+			id    int
+			title string
+		)
+		if err := rows.Scan(&id, &title); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if _, ok := stages[strings.ToLower(title)]; !ok {
+			stages[strings.ToLower(title)] = id
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cards, completed := map[string]int{}, map[string]int{}
+	for _, card := range board.Cards {
+		cards[card.Stage]++
+		if card.CompletedAt != nil {
+			completed[card.Stage]++
+		}
+	}
+
+	for _, name := range board.Stages {
+		if _, ok := stages[strings.ToLower(name)]; ok || name == "" {
+			continue
+		}
+		input := StageInput{Title: name, IsDone: cards[name] > 0 && completed[name] == cards[name]}
+		ids, err := createStages(ctx, tx, projectID, []StageInput{input})
+		if err != nil {
+			return nil, err
+		}
+		stages[strings.ToLower(name)] = ids[0]
+		report.StagesCreated = append(report.StagesCreated, name)
+	}
+	return stages, nil
+}
+
+// importLabels matches card labels to the project's labels by name, creating the missing ones.
+// The result maps lower-cased label names to label IDs; invalid names are reported as skipped.
+func importLabels(ctx context.Context, tx pgx.Tx, projectID int, board *projectmodel.ImportedBoard, report *projectmodel.BoardImportReport) (map[string]int, error) {
+	rows, err := tx.Query(ctx, `SELECT id, lower(name) FROM project_labels WHERE project_id = $1`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]int{}
+	for rows.Next() {
+		var (
+			id   int
+			name string
+		)
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		labels[name] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	skipped := map[string]bool{}
+	for _, card := range board.Cards {
+		for _, name := range card.Labels {
+			key := strings.ToLower(strings.TrimSpace(name))
+			if _, ok := labels[key]; ok || skipped[key] {
+				continue
+			}
+			id, err := createLabel(ctx, tx, projectID, LabelInput{Name: name, Color: board.LabelColors[name]})
+			if errors.Is(err, ErrInvalidLabel) {
+				skipped[key] = true
+				report.Skipped = append(report.Skipped, fmt.Sprintf("label %q: %v", name, err))
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			labels[key] = id
+			report.LabelsCreated = append(report.LabelsCreated, name)
+		}
+	}
+	return labels, nil
+}
+
+// reserveImportStages resolves the stage of every card, the way the stage trigger does for cards of unknown
+// columns, and checks that each stage's WIP limit fits all of its incoming cards.
+// Stages are locked in ID order so that concurrent imports and moves cannot deadlock.
+func reserveImportStages(ctx context.Context, tx pgx.Tx, projectID int, board *projectmodel.ImportedBoard, stages map[string]int) ([]*int, error) {
+	targets := make([]*int, len(board.Cards))
+	incoming := map[int]int{}
+	for i, card := range board.Cards {
+		id, ok := stages[strings.ToLower(card.Stage)]
+		if !ok {
+			err := tx.QueryRow(ctx, `
+				SELECT id FROM project_stages WHERE project_id = $1
+				ORDER BY is_done = $2 DESC, position, id
+				LIMIT 1`, projectID, card.CompletedAt != nil).Scan(&id)
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		targets[i] = &id
+		incoming[id]++
+	}
+	for _, id := range slices.Sorted(maps.Keys(incoming)) {
+		if err := reserveStage(ctx, tx, id, incoming[id]); err != nil {
+			return nil, err
+		}
+	}
+	return targets, nil
+}
+
+// commentAuthors maps the usernames of the board's comment authors to user IDs; unknown users are left out
+func commentAuthors(ctx context.Context, tx pgx.Tx, board *projectmodel.ImportedBoard) (map[string]int, error) {
+	var usernames []string
+	for _, card := range board.Cards {
+		for _, c := range card.Comments {
+			if c.Author != "" {
+				usernames = append(usernames, c.Author)
+			}
+		}
+	}
+	authors := map[string]int{}
+	if len(usernames) == 0 {
+		return authors, nil
+	}
+	rows, err := tx.Query(ctx, `SELECT id, username FROM user_user WHERE username = ANY($1)`, usernames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id       int
+			username string
+		)
+		if err := rows.Scan(&id, &username); err != nil {
+			return nil, err
+		}
+		authors[username] = id
+	}
+	return authors, rows.Err()
+}
+
+// importComments adds a card's comments to its task, keeping their dates.
+// A comment whose author is not a known user is attributed to the importer, the context actor.
+func importComments(ctx context.Context, tx pgx.Tx, taskID int, card projectmodel.ImportedCard, authors map[string]int, report *projectmodel.BoardImportReport) error {
+	var importer *int
+	if uid, ok := ActorFromContext(ctx); ok {
+		importer = &uid
+	}
+	for i, c := range card.Comments {
+		body, err := validateComment(c.Text)
+		if err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("card %s comment %d: %v", card.Key, i+1, err))
+			continue
+		}
+		authorID := importer
+		if id, ok := authors[c.Author]; ok {
+			authorID = &id
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO task_comments (task_id, author_id, body, body_html, created_at)
+			VALUES ($1, $2, $3, $4, COALESCE($5, NOW()))`, taskID, authorID, body, markdown.Render(body), c.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shortDescription returns the first line of text cut to shortDescriptionLen runes
+func shortDescription(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	line = strings.TrimSpace(line)
+	if utf8.RuneCountInString(line) <= shortDescriptionLen {
+		return line
+	}
+	runes := []rune(line)
+	return string(runes[:shortDescriptionLen-1]) + "…"
+}
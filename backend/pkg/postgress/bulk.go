@@ -0,0 +1,255 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nais2008/hackanet2025/backend/pkg/events"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// Bulk operation kinds accepted in BulkOperation.Op
+const (
+	BulkSetStage     = "set_stage"
+	BulkSetPriority  = "set_priority"
+	BulkSetAssignee  = "set_assignee"
+	BulkAddLabels    = "add_labels"
+	BulkRemoveLabels = "remove_labels"
+	BulkArchive      = "archive"
+	BulkUnarchive    = "unarchive"
+	BulkDelete       = "delete"
+)
+
+const (
+	// maxBulkTasks limits the tasks changed by one bulk update
+	maxBulkTasks = 500
+	// maxBulkOperations limits the operations of one bulk update
+	maxBulkOperations = 20
+)
+
+// ErrInvalidBulk is returned for a malformed bulk update
+var ErrInvalidBulk = errors.New("invalid bulk update")
+
+// errBulkFailed rolls back a bulk update in which some task failed
+var errBulkFailed = errors.New("bulk update failed")
+
+// errTaskGone reports a task deleted earlier in the same bulk update
+var errTaskGone = errors.New("task was deleted with its parent")
+
+// BulkOperation is one change applied to every task of a bulk update
+type BulkOperation struct {
+	Op       string      `json:"op"`
+	StageID  int         `json:"stage_id"`  // set_stage
+	Priority string      `json:"priority"`  // set_priority
+	UserID   *int        `json:"user_id"`   // set_assignee: the only assignee, none when nil
+	LabelIDs []int       `json:"label_ids"` // add_labels, remove_labels
+	Subtasks SubtaskMode `json:"subtasks"`  // delete: what happens to subtasks, as in DeleteTaskWithSubtasks
+}
+
+func (op BulkOperation) validate() error {
+	switch op.Op {
+	case BulkSetStage:
+		if op.StageID <= 0 {
+			return fmt.Errorf("%w: %s requires stage_id", ErrInvalidBulk, op.Op)
+		}
+	case BulkSetPriority:
+		if !slices.Contains(projectmodel.Priorities, op.Priority) {
+			return fmt.Errorf("%w: priority must be one of %s", ErrInvalidBulk, strings.Join(projectmodel.Priorities, ", "))
+		}
+	case BulkAddLabels, BulkRemoveLabels:
+		if len(op.LabelIDs) == 0 {
+			return fmt.Errorf("%w: %s requires label_ids", ErrInvalidBulk, op.Op)
+		}
+	case BulkDelete:
+		switch op.Subtasks {
+		case SubtasksRefuse, SubtasksCascade, SubtasksPromote:
+		default:
+			return fmt.Errorf("%w: unknown subtask mode %q", ErrInvalidBulk, op.Subtasks)
+		}
+	case BulkSetAssignee, BulkArchive, BulkUnarchive:
+	default:
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidBulk, op.Op)
+	}
+	return nil
+}
+
+// BulkUpdateTasks applies the operations, in order, to each of the project's tasks in one transaction.
+// Every task gets its own result; the changes are committed only when no task failed, and with dryRun
+// they are always rolled back, so the result shows what would happen.
+func (db *DB) BulkUpdateTasks(ctx context.Context, projectID int, taskIDs []int, ops []BulkOperation, dryRun bool) (*projectmodel.BulkResult, error) {
+	if len(ops) == 0 || len(ops) > maxBulkOperations {
+		return nil, fmt.Errorf("%w: 1-%d operations are required", ErrInvalidBulk, maxBulkOperations)
+	}
+	for i, op := range ops {
+		if err := op.validate(); err != nil {
+			return nil, err
+		}
+		if op.Op == BulkDelete && i != len(ops)-1 {
+			return nil, fmt.Errorf("%w: delete must be the last operation", ErrInvalidBulk)
+		}
+	}
+	var ids []int
+	for _, id := range taskIDs {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || len(ids) > maxBulkTasks {
+		return nil, fmt.Errorf("%w: 1-%d tasks are required", ErrInvalidBulk, maxBulkTasks)
+	}
+
+	result := &projectmodel.BulkResult{DryRun: dryRun, Tasks: make([]projectmodel.BulkTaskResult, 0, len(ids))}
+	var evs []events.Event
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT t.id FROM task_task t
+			JOIN project_project p ON p.id = t.project_id
+			WHERE t.project_id = $1 AND t.id = ANY($2) AND p.workspace_id IS NOT DISTINCT FROM $3`,
+			projectID, ids, workspaceArg(ctx))
+		if err != nil {
+			return err
+		}
+		found, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			res := projectmodel.BulkTaskResult{TaskID: id, Status: projectmodel.BulkTaskOK}
+			var taskEvs []events.Event
+			err := fmt.Errorf("task %d not found in project %d: %w", id, projectID, pgx.ErrNoRows)
+			if slices.Contains(found, id) {
+				// Каждая задача меняется в своей точке сохранения, чтобы ошибка одной не мешала проверить остальные
+				err = pgx.BeginFunc(ctx, tx, func(sp pgx.Tx) error {
+					var err error
+					taskEvs, err = applyBulk(ctx, sp, projectID, id, ops)
+					return err
+				})
+			}
+			switch {
+			case errors.Is(err, errTaskGone):
+				res.Status = projectmodel.BulkTaskSkipped
+			case err != nil:
+				res.Status, res.Error = projectmodel.BulkTaskFailed, err.Error()
+				result.Failed++
+			default:
+				result.Succeeded++
+				evs = append(evs, taskEvs...)
+			}
+			result.Tasks = append(result.Tasks, res)
+		}
+		if result.Failed > 0 {
+			return errBulkFailed
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkFailed) && !errors.Is(err, errDryRun) {
+		return nil, fmt.Errorf("failed to update tasks: %w", err)
+	}
+	result.Applied = err == nil
+	if result.Applied {
+		db.publish(ctx, evs)
+	}
+	return result, nil
+}
+
+// applyBulk applies the operations to one task, returning the events of the changes
+func applyBulk(ctx context.Context, tx pgx.Tx, projectID, taskID int, ops []BulkOperation) ([]events.Event, error) {
+	var (
+		stageID  *int
+		priority string
+	)
+	err := tx.QueryRow(ctx, `SELECT stage_id, priority FROM task_task WHERE id = $1 FOR UPDATE`, taskID).Scan(&stageID, &priority)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errTaskGone
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var evs []events.Event
+	for _, op := range ops {
+		var (
+			opEvs []events.Event
+			err   error
+		)
+		switch op.Op {
+		case BulkSetStage:
+			if stageID == nil || *stageID != op.StageID {
+				err = moveTask(ctx, tx, projectID, taskID, TaskPlacement{StageID: op.StageID})
+				stageID = &op.StageID
+			}
+		case BulkSetPriority:
+			if priority != op.Priority {
+				err = patchTask(ctx, tx, projectID, taskID, TaskPatch{Priority: Set(op.Priority)})
+				priority = op.Priority
+			}
+		case BulkSetAssignee:
+			opEvs, err = setAssignee(ctx, tx, projectID, taskID, op.UserID)
+		case BulkAddLabels, BulkRemoveLabels:
+			_, err = setTaskLabels(ctx, tx, projectID, []int{taskID}, op.LabelIDs, op.Op == BulkAddLabels)
+		case BulkArchive, BulkUnarchive:
+			err = archiveTask(ctx, tx, projectID, taskID, op.Op == BulkArchive)
+		case BulkDelete:
+			err = deleteTask(ctx, tx, projectID, taskID, op.Subtasks)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op.Op, err)
+		}
+		evs = append(evs, opEvs...)
+	}
+	return evs, nil
+}
+
+// setAssignee makes the user the only assignee of the task; with a nil user it removes all assignees
+func setAssignee(ctx context.Context, tx pgx.Tx, projectID, taskID int, userID *int) ([]events.Event, error) {
+	rows, err := tx.Query(ctx, `SELECT user_id FROM task_assignees WHERE task_id = $1`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+	var others []int
+	for _, id := range current {
+		if userID == nil || id != *userID {
+			others = append(others, id)
+		}
+	}
+	var evs []events.Event
+	if len(others) > 0 {
+		if evs, err = removeAssignees(ctx, tx, projectID, taskID, others); err != nil {
+			return nil, err
+		}
+	}
+	if userID == nil || slices.Contains(current, *userID) {
+		return evs, nil
+	}
+	added, err := addAssignees(ctx, tx, projectID, taskID, []int{*userID})
+	return append(evs, added...), err
+}
+
+// archiveTask archives or restores a task; a task already in that state is left as it is
+func archiveTask(ctx context.Context, tx pgx.Tx, projectID, taskID int, archive bool) error {
+	query := `UPDATE task_task SET archived_at = NOW() WHERE id = $1 AND archived_at IS NULL`
+	if !archive {
+		query = `UPDATE task_task SET archived_at = NULL WHERE id = $1 AND archived_at IS NOT NULL`
+	}
+	tag, err := tx.Exec(ctx, query, taskID)
+	if err != nil || tag.RowsAffected() == 0 {
+		return err
+	}
+	return recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskUpdated, map[string]any{
+		"task_id":  taskID,
+		"fields":   []string{"archived"},
+		"archived": archive,
+	})
+}
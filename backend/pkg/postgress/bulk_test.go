@@ -0,0 +1,45 @@
+package db_test
+
+import (
+	"testing"
+
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkOperationValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		op    db.BulkOperation
+		valid bool
+	}{
+		{"set stage", db.BulkOperation{Op: db.BulkSetStage, StageID: 3}, true},
+		{"set stage without stage", db.BulkOperation{Op: db.BulkSetStage}, false},
+		{"set priority", db.BulkOperation{Op: db.BulkSetPriority, Priority: projectmodel.PriorityCritical}, true},
+		{"unknown priority", db.BulkOperation{Op: db.BulkSetPriority, Priority: "urgent"}, false},
+		{"set assignee", db.BulkOperation{Op: db.BulkSetAssignee, UserID: ptr(2)}, true},
+		{"clear assignee", db.BulkOperation{Op: db.BulkSetAssignee}, true},
+		{"add labels", db.BulkOperation{Op: db.BulkAddLabels, LabelIDs: []int{1, 2}}, true},
+		{"add no labels", db.BulkOperation{Op: db.BulkAddLabels}, false},
+		{"remove no labels", db.BulkOperation{Op: db.BulkRemoveLabels, LabelIDs: []int{}}, false},
+		{"archive", db.BulkOperation{Op: db.BulkArchive}, true},
+		{"unarchive", db.BulkOperation{Op: db.BulkUnarchive}, true},
+		{"delete refusing subtasks", db.BulkOperation{Op: db.BulkDelete}, true},
+		{"delete with cascade", db.BulkOperation{Op: db.BulkDelete, Subtasks: db.SubtasksCascade}, true},
+		{"delete promoting subtasks", db.BulkOperation{Op: db.BulkDelete, Subtasks: db.SubtasksPromote}, true},
+		{"delete with unknown mode", db.BulkOperation{Op: db.BulkDelete, Subtasks: "orphan"}, false},
+		{"unknown operation", db.BulkOperation{Op: "rename"}, false},
+		{"empty operation", db.BulkOperation{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.ValidateBulkOperation(tt.op)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, db.ErrInvalidBulk)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// maxChecklistTextLen limits the text of a checklist item
+const maxChecklistTextLen = 500
+
+// ChecklistInput holds the fields of a new checklist item
+type ChecklistInput struct {
+	Text       string
+	AssigneeID *int // must be a project member
+}
+
+// ChecklistPatch lists checklist item changes; unset fields are left as they are
+type ChecklistPatch struct {
+	Text       Optional[string] `json:"text"`
+	Done       Optional[bool]   `json:"done"`
+	AssigneeID Optional[*int]   `json:"assignee_id"`
+}
+
+func validateChecklistText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || utf8.RuneCountInString(text) > maxChecklistTextLen {
+		return "", fmt.Errorf("%w: checklist text must be 1-%d characters", ErrInvalidTask, maxChecklistTextLen)
+	}
+	return text, nil
+}
+
+// GetChecklist returns the checklist of a task in order
+func (db *DB) GetChecklist(ctx context.Context, taskID int) ([]projectmodel.ChecklistItem, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT i.id, i.task_id, i.text, i.done, i.position, i.assignee_id, i.created_at, i.done_at
+		FROM task_checklist_items i
+		JOIN task_task t ON t.id = i.task_id
+		JOIN project_project p ON p.id = t.project_id
+		WHERE i.task_id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2
+		ORDER BY i.position, i.id`, taskID, workspaceArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checklist: %w", err)
+	}
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.ChecklistItem, error) {
+		var i projectmodel.ChecklistItem
+		err := row.Scan(&i.ID, &i.TaskID, &i.Text, &i.Done, &i.Position, &i.AssigneeID, &i.CreatedAt, &i.DoneAt)
+		return i, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan checklist: %w", err)
+	}
+	return items, nil
+}
+
+// AddChecklistItem appends an item to the task's checklist
+func (db *DB) AddChecklistItem(ctx context.Context, projectID, taskID int, in ChecklistInput) (int, error) {
+	text, err := validateChecklistText(in.Text)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	err = db.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := lockTask(ctx, tx, projectID, taskID)
+		if err != nil {
+			return err
+		}
+		if in.AssigneeID != nil {
+			if err := checkMembers(ctx, tx, projectID, []int{*in.AssigneeID}); err != nil {
+				return err
+			}
+		}
+		err = tx.QueryRow(ctx, `
+			INSERT INTO task_checklist_items (task_id, text, position, assignee_id)
+			SELECT $1, $2, COALESCE(MAX(position) + 1, 0), $3
+			FROM task_checklist_items WHERE task_id = $1
+			RETURNING id`, taskID, text, in.AssigneeID).Scan(&id)
+		if err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskUpdated, map[string]any{
+			"task_id":        taskID,
+			"fields":         []string{"checklist"},
+			"checklist_item": id,
+		})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to add checklist item: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateChecklistItem applies the patch to an item of the task's checklist
+func (db *DB) UpdateChecklistItem(ctx context.Context, projectID, taskID, itemID int, patch ChecklistPatch) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := lockTask(ctx, tx, projectID, taskID)
+		if err != nil {
+			return err
+		}
+		var item projectmodel.ChecklistItem
+		err = tx.QueryRow(ctx, `
+			SELECT text, done, assignee_id FROM task_checklist_items
+			WHERE id = $1 AND task_id = $2`, itemID, taskID).Scan(&item.Text, &item.Done, &item.AssigneeID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("checklist item %d not found: %w", itemID, err)
+		}
+		if err != nil {
+			return err
+		}
+
+		if patch.Text.Set {
+			if item.Text, err = validateChecklistText(patch.Text.Value); err != nil {
+				return err
+			}
+		}
+		if patch.Done.Set {
+			item.Done = patch.Done.Value
+		}
+		if patch.AssigneeID.Set {
+			item.AssigneeID = patch.AssigneeID.Value
+			if item.AssigneeID != nil {
+				if err := checkMembers(ctx, tx, projectID, []int{*item.AssigneeID}); err != nil {
+					return err
+				}
+			}
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE task_checklist_items
+			SET text = $2, done = $3, assignee_id = $4,
+			    done_at = CASE WHEN NOT $3 THEN NULL WHEN done THEN done_at ELSE NOW() END
+			WHERE id = $1`, itemID, item.Text, item.Done, item.AssigneeID)
+		if err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskUpdated, map[string]any{
+			"task_id":        taskID,
+			"fields":         []string{"checklist"},
+			"checklist_item": itemID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update checklist item: %w", err)
+	}
+	return nil
+}
+
+// DeleteChecklistItem removes an item from the task's checklist
+func (db *DB) DeleteChecklistItem(ctx context.Context, projectID, taskID, itemID int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := lockTask(ctx, tx, projectID, taskID); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `DELETE FROM task_checklist_items WHERE id = $1 AND task_id = $2`, itemID, taskID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("checklist item %d not found: %w", itemID, pgx.ErrNoRows)
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskUpdated, map[string]any{
+			"task_id":        taskID,
+			"fields":         []string{"checklist"},
+			"checklist_item": itemID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete checklist item: %w", err)
+	}
+	return nil
+}
+
+// ReorderChecklist sets the checklist order to the order of itemIDs.
+// Items missing from itemIDs keep their relative order after the listed ones.
+func (db *DB) ReorderChecklist(ctx context.Context, projectID, taskID int, itemIDs []int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := lockTask(ctx, tx, projectID, taskID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			WITH listed AS (
+				SELECT item_id, ord FROM unnest($2::int[]) WITH ORDINALITY AS u(item_id, ord)
+			), ordered AS (
+				SELECT i.id,
+				       ROW_NUMBER() OVER (ORDER BY l.ord NULLS LAST, i.position, i.id) - 1 AS position
+				FROM task_checklist_items i
+				LEFT JOIN listed l ON l.item_id = i.id
+				WHERE i.task_id = $1
+			)
+			UPDATE task_checklist_items i SET position = o.position
+			FROM ordered o
+			WHERE i.id = o.id`, taskID, itemIDs)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reorder checklist: %w", err)
+	}
+	return nil
+}
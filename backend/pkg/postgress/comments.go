@@ -0,0 +1,371 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nais2008/hackanet2025/backend/pkg/events"
+	"github.com/nais2008/hackanet2025/backend/pkg/markdown"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+const (
+	// maxCommentLen limits the Markdown body of a comment
+	maxCommentLen = 10000
+	// maxReactionLen limits a reaction, which is an emoji or a short name like "+1"
+	maxReactionLen = 32
+	// notificationsLimit caps the number of notifications returned at once
+	notificationsLimit = 100
+)
+
+var (
+	// ErrInvalidComment is returned for an empty or too long comment or a malformed reaction
+	ErrInvalidComment = errors.New("invalid comment")
+	// ErrNotCommentAuthor is returned when someone other than the author edits or deletes a comment
+	ErrNotCommentAuthor = errors.New("only the author can change the comment")
+)
+
+// CommentInput holds a comment body and the users it mentions.
+// Mentions of users who are not project members are ignored.
+type CommentInput struct {
+	Body       string
+	MentionIDs []int
+}
+
+func validateComment(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || utf8.RuneCountInString(body) > maxCommentLen {
+		return "", fmt.Errorf("%w: body must be 1-%d characters", ErrInvalidComment, maxCommentLen)
+	}
+	return body, nil
+}
+
+func validateReaction(emoji string) (string, error) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" || len(emoji) > maxReactionLen || strings.IndexFunc(emoji, unicode.IsSpace) >= 0 {
+		return "", fmt.Errorf("%w: reaction must be 1-%d bytes without spaces", ErrInvalidComment, maxReactionLen)
+	}
+	return emoji, nil
+}
+
+// commentColumns lists the comment fields read by scanComment; queries alias task_comments as c
+const commentColumns = `c.id, c.task_id,
+	(SELECT json_build_object('id', u.id, 'username', u.username) FROM user_user u WHERE u.id = c.author_id),
+	c.body, c.body_html, c.created_at, c.edited_at,
+	COALESCE((SELECT json_agg(json_build_object('id', u.id, 'username', u.username) ORDER BY u.username)
+	          FROM task_comment_mentions m JOIN user_user u ON u.id = m.user_id
+	          WHERE m.comment_id = c.id), '[]'::json),
+	COALESCE((SELECT json_agg(json_build_object('emoji', r.emoji, 'count', r.n, 'user_ids', r.user_ids) ORDER BY r.first_at)
+	          FROM (SELECT emoji, COUNT(*) AS n, array_agg(user_id ORDER BY created_at) AS user_ids, MIN(created_at) AS first_at
+	                FROM task_comment_reactions WHERE comment_id = c.id GROUP BY emoji) r), '[]'::json)`
+
+func scanComment(row pgx.Row) (projectmodel.TaskComment, error) {
+	var c projectmodel.TaskComment
+	err := row.Scan(&c.ID, &c.TaskID, &c.Author, &c.Body, &c.HTML, &c.CreatedAt, &c.EditedAt, &c.Mentions, &c.Reactions)
+	return c, err
+}
+
+// GetTaskComments returns the comments of a task, oldest first
+func (db *DB) GetTaskComments(ctx context.Context, taskID int) ([]projectmodel.TaskComment, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+commentColumns+`
+		FROM task_comments c
+		WHERE c.task_id = $1
+		ORDER BY c.created_at, c.id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	comments, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.TaskComment, error) {
+		return scanComment(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan comments: %w", err)
+	}
+	return comments, nil
+}
+
+// GetTaskComment returns one comment of a task
+func (db *DB) GetTaskComment(ctx context.Context, taskID, commentID int) (*projectmodel.TaskComment, error) {
+	c, err := scanComment(db.Pool.QueryRow(ctx, `
+		SELECT `+commentColumns+`
+		FROM task_comments c
+		WHERE c.id = $2 AND c.task_id = $1`, taskID, commentID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("comment %d not found: %w", commentID, err)
+		}
+		return nil, fmt.Errorf("failed to query comment: %w", err)
+	}
+	return &c, nil
+}
+
+// mentionUsers records the mentions of a comment, dropping the author and non-members,
+// and notifies the users who were not mentioned in it before
+func mentionUsers(ctx context.Context, tx pgx.Tx, projectID, taskID, commentID, authorID int, userIDs []int) ([]events.Event, error) {
+	if userIDs == nil {
+		userIDs = []int{}
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM task_comment_mentions WHERE comment_id = $1 AND user_id <> ALL($2)`, commentID, userIDs); err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx, `
+		INSERT INTO task_comment_mentions (comment_id, user_id)
+		SELECT $1, m.user_id FROM project_members m
+		WHERE m.project_id = $2 AND m.user_id = ANY($3) AND m.user_id <> $4
+		ON CONFLICT DO NOTHING
+		RETURNING user_id`, commentID, projectID, userIDs, authorID)
+	if err != nil {
+		return nil, err
+	}
+	added, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO notifications (user_id, type, project_id, task_id, comment_id, actor_id)
+		SELECT u, $2, $3, $4, $5, $6 FROM unnest($1::int[]) u`,
+		added, projectmodel.NotificationMentioned, projectID, taskID, commentID, authorID); err != nil {
+		return nil, err
+	}
+	evs := make([]events.Event, 0, len(added))
+	for _, userID := range added {
+		evs = append(evs, newEvent(ctx, events.CommentMentioned, projectID, taskID, userID))
+	}
+	return evs, nil
+}
+
+// AddTaskComment adds a comment by the author, renders its Markdown and notifies the mentioned members
+func (db *DB) AddTaskComment(ctx context.Context, projectID, taskID, authorID int, in CommentInput) (int, error) {
+	body, err := validateComment(in.Body)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		id  int
+		evs []events.Event
+	)
+	err = db.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := checkTask(ctx, tx, projectID, taskID)
+		if err != nil {
+			return err
+		}
+		err = tx.QueryRow(ctx, `
+			INSERT INTO task_comments (task_id, author_id, body, body_html)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id`, taskID, authorID, body, markdown.Render(body)).Scan(&id)
+		if err != nil {
+			return err
+		}
+		if evs, err = mentionUsers(ctx, tx, projectID, taskID, id, authorID, in.MentionIDs); err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityCommentAdded, map[string]any{
+			"task_id":    taskID,
+			"comment_id": id,
+		})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to add comment: %w", err)
+	}
+	db.publish(ctx, evs)
+	return id, nil
+}
+
+// lockComment locks a comment of the task and returns its author
+func lockComment(ctx context.Context, tx pgx.Tx, taskID, commentID int) (*int, error) {
+	var authorID *int
+	err := tx.QueryRow(ctx, `
+		SELECT author_id FROM task_comments WHERE id = $2 AND task_id = $1
+		FOR UPDATE`, taskID, commentID).Scan(&authorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("comment %d not found: %w", commentID, err)
+	}
+	return authorID, err
+}
+
+// checkComment returns pgx.ErrNoRows unless the comment belongs to the task of the project in the active workspace
+func checkComment(ctx context.Context, q querier, projectID, taskID, commentID int) error {
+	var found bool
+	err := q.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM task_comments c
+		               JOIN task_task t ON t.id = c.task_id
+		               JOIN project_project p ON p.id = t.project_id
+		               WHERE c.id = $1 AND c.task_id = $2 AND t.project_id = $3
+		                 AND p.workspace_id IS NOT DISTINCT FROM $4)`,
+		commentID, taskID, projectID, workspaceArg(ctx)).Scan(&found)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("comment %d not found: %w", commentID, pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// EditTaskComment replaces the body of the user's own comment, keeping the previous body in its history.
+// Newly mentioned members are notified.
+func (db *DB) EditTaskComment(ctx context.Context, projectID, taskID, commentID, userID int, in CommentInput) error {
+	body, err := validateComment(in.Body)
+	if err != nil {
+		return err
+	}
+	var evs []events.Event
+	err = db.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := checkTask(ctx, tx, projectID, taskID)
+		if err != nil {
+			return err
+		}
+		authorID, err := lockComment(ctx, tx, taskID, commentID)
+		if err != nil {
+			return err
+		}
+		if authorID == nil || *authorID != userID {
+			return ErrNotCommentAuthor
+		}
+		tag, err := tx.Exec(ctx, `
+			WITH prev AS (
+				INSERT INTO task_comment_revisions (comment_id, body, edited_by)
+				SELECT id, body, $3 FROM task_comments WHERE id = $1 AND body <> $2
+				RETURNING comment_id
+			)
+			UPDATE task_comments SET body = $2, body_html = $4, edited_at = NOW()
+			WHERE id IN (SELECT comment_id FROM prev)`, commentID, body, userID, markdown.Render(body))
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+		if evs, err = mentionUsers(ctx, tx, projectID, taskID, commentID, userID, in.MentionIDs); err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityCommentEdited, map[string]any{
+			"task_id":    taskID,
+			"comment_id": commentID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to edit comment: %w", err)
+	}
+	db.publish(ctx, evs)
+	return nil
+}
+
+// DeleteTaskComment deletes a comment; unless moderate is set only its author may do that
+func (db *DB) DeleteTaskComment(ctx context.Context, projectID, taskID, commentID, userID int, moderate bool) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := checkTask(ctx, tx, projectID, taskID)
+		if err != nil {
+			return err
+		}
+		authorID, err := lockComment(ctx, tx, taskID, commentID)
+		if err != nil {
+			return err
+		}
+		if !moderate && (authorID == nil || *authorID != userID) {
+			return ErrNotCommentAuthor
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM task_comments WHERE id = $1`, commentID); err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityCommentDeleted, map[string]any{
+			"task_id":    taskID,
+			"comment_id": commentID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	return nil
+}
+
+// GetCommentHistory returns the previous versions of a comment, oldest first
+func (db *DB) GetCommentHistory(ctx context.Context, taskID, commentID int) ([]projectmodel.CommentRevision, error) {
+	if _, err := db.GetTaskComment(ctx, taskID, commentID); err != nil {
+		return nil, err
+	}
+	rows, err := db.Pool.Query(ctx, `
+		SELECT body, edited_by, edited_at FROM task_comment_revisions
+		WHERE comment_id = $1
+		ORDER BY edited_at, id`, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comment history: %w", err)
+	}
+	revisions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.CommentRevision, error) {
+		var r projectmodel.CommentRevision
+		err := row.Scan(&r.Body, &r.EditedBy, &r.EditedAt)
+		return r, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan comment history: %w", err)
+	}
+	return revisions, nil
+}
+
+// SetCommentReaction adds or removes the user's reaction to a comment; repeating either is a no-op
+func (db *DB) SetCommentReaction(ctx context.Context, projectID, taskID, commentID, userID int, emoji string, on bool) error {
+	emoji, err := validateReaction(emoji)
+	if err != nil {
+		return err
+	}
+	if err := checkComment(ctx, db.Pool, projectID, taskID, commentID); err != nil {
+		return err
+	}
+	if on {
+		_, err = db.Pool.Exec(ctx, `
+			INSERT INTO task_comment_reactions (comment_id, user_id, emoji)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`, commentID, userID, emoji)
+	} else {
+		_, err = db.Pool.Exec(ctx, `
+			DELETE FROM task_comment_reactions
+			WHERE comment_id = $1 AND user_id = $2 AND emoji = $3`, commentID, userID, emoji)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set reaction: %w", err)
+	}
+	return nil
+}
+
+// GetNotifications returns the user's latest notifications, newest first
+func (db *DB) GetNotifications(ctx context.Context, userID int, unreadOnly bool, limit int) ([]projectmodel.Notification, error) {
+	if limit <= 0 || limit > notificationsLimit {
+		limit = notificationsLimit
+	}
+	rows, err := db.Pool.Query(ctx, `
+		SELECT id, type, project_id, task_id, comment_id, actor_id, created_at, read_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY id DESC
+		LIMIT $3`, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	notifications, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.Notification, error) {
+		var n projectmodel.Notification
+		err := row.Scan(&n.ID, &n.Type, &n.ProjectID, &n.TaskID, &n.CommentID, &n.ActorID, &n.CreatedAt, &n.ReadAt)
+		return n, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+// MarkNotificationsRead marks the user's notifications as read, all of them when ids is empty,
+// and returns how many changed
+func (db *DB) MarkNotificationsRead(ctx context.Context, userID int, ids []int64) (int, error) {
+	tag, err := db.Pool.Exec(ctx, `
+		UPDATE notifications SET read_at = NOW()
+		WHERE user_id = $1 AND read_at IS NULL AND (COALESCE(cardinality($2::bigint[]), 0) = 0 OR id = ANY($2))`, userID, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
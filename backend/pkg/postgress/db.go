@@ -5,12 +5,25 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nais2008/hackanet2025/backend/pkg/events"
 )
 
 // DB holds the database connection pool
 type DB struct {
 	Pool *pgxpool.Pool
+	// Events receives the changes other parts of the system can subscribe to
+	Events *events.Bus
+}
+
+// querier is implemented by both the pool and a transaction,
+// so helpers can run either standalone or as part of a larger transaction
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 // New creates a new database connection pool
@@ -42,7 +55,7 @@ func New(ctx context.Context) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &DB{Pool: pool}, nil
+	return &DB{Pool: pool, Events: events.NewBus()}, nil
 }
 
 // Close closes the database connection pool
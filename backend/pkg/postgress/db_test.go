@@ -2,21 +2,25 @@ package db_test
 
 import (
 	"context"
-	"os"
 	"testing"
 
 	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
 	"github.com/stretchr/testify/require"
 )
 
+// setupDB connects to the database configured by the DB_* variables and skips the test when it is unavailable
+func setupDB(t *testing.T) *db.DB {
+	database, err := db.New(context.Background())
+	if err != nil {
+		t.Skipf("database is not available: %v", err)
+	}
+	t.Cleanup(database.Close)
+	return database
+}
+
 func TestProjectFunction(t *testing.T) {
 	ctx := context.Background()
-	errCh := make(chan error, 1)
-	os.Setenv("dbpass", "123456")
-
-	database := db.New(ctx, errCh)
-	require.NotNil(t, database)
-	defer database.Close()
+	database := setupDB(t)
 
 	const testProjID = 9999
 	// Очистка зависимостей
@@ -63,12 +67,7 @@ func TestProjectFunction(t *testing.T) {
 
 func TestTaskAndProjectCRUD(t *testing.T) {
 	ctx := context.Background()
-	errCh := make(chan error, 1)
-	os.Setenv("dbpass", "123456")
-
-	database := db.New(ctx, errCh)
-	require.NotNil(t, database)
-	defer database.Close()
+	database := setupDB(t)
 
 	t.Run("CreateAndDeleteProject", func(t *testing.T) {
 		const pid = 8888
@@ -107,13 +106,13 @@ func TestTaskAndProjectCRUD(t *testing.T) {
 		require.NoError(t, database.Pool.QueryRow(ctx, `SELECT title, description, full_description FROM task_task WHERE id=$1`, taskID).Scan(&ttitle, &tdesc, &tfull))
 		require.Equal(t, "New Task", ttitle)
 
-		err = database.UpdateTask(ctx, taskID, "Updated Task", "New Desc", "Updated Full Desc")
+		err = database.UpdateTask(ctx, projectID, taskID, "Updated Task", "New Desc", "Updated Full Desc")
 		require.NoError(t, err)
 
 		require.NoError(t, database.Pool.QueryRow(ctx, `SELECT title FROM task_task WHERE id=$1`, taskID).Scan(&ttitle))
 		require.Equal(t, "Updated Task", ttitle)
 
-		err = database.DeleteTask(ctx, taskID)
+		err = database.DeleteTask(ctx, projectID, taskID)
 		require.NoError(t, err)
 
 		err = database.Pool.QueryRow(ctx, `SELECT id FROM task_task WHERE id=$1`, taskID).Scan(new(int))
@@ -0,0 +1,488 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nais2008/hackanet2025/backend/pkg/markdown"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// ExportVersion is the manifest version written by ExportProject and accepted by ImportProject
+const ExportVersion = 1
+
+// errDryRun rolls back the import transaction after a successful dry run
+var errDryRun = errors.New("dry run")
+
+// errImportConflicts rolls back an import that found conflicts
+var errImportConflicts = errors.New("import has conflicts")
+
+// ExportProject collects the project, its members, tasks with their comments, project comments and file references into a snapshot
+func (db *DB) ExportProject(ctx context.Context, projectID int) (*projectmodel.ProjectExport, error) {
+	exp := projectmodel.ProjectExport{
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Members:    []projectmodel.ExportedUser{},
+		Stages:     []projectmodel.ExportedStage{},
+		Labels:     []projectmodel.ExportedLabel{},
+		Tasks:      []projectmodel.ExportedTask{},
+		Comments:   []projectmodel.ExportedNote{},
+		Files:      []string{},
+	}
+
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY`); err != nil {
+			return err
+		}
+
+		err := tx.QueryRow(ctx, `
+			SELECT id, title, COALESCE(key, '') FROM project_project
+			WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $2`, projectID, workspaceArg(ctx)).Scan(
+			&exp.Project.ID, &exp.Project.Title, &exp.Project.Key,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("project not found: %w", err)
+			}
+			return err
+		}
+
+		rows, err := tx.Query(ctx, `
+			SELECT m.user_id, u.username, m.role
+			FROM project_members m JOIN user_user u ON u.id = m.user_id
+			WHERE m.project_id = $1 ORDER BY m.user_id`, projectID)
+		if err != nil {
+			return err
+		}
+		exp.Members, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.ExportedUser, error) {
+			var m projectmodel.ExportedUser
+			err := row.Scan(&m.UserID, &m.Username, &m.Role)
+			return m, err
+		})
+		if err != nil {
+			return err
+		}
+
+		rows, err = tx.Query(ctx, `
+			SELECT id, title, wip_limit, is_done
+			FROM project_stages WHERE project_id = $1 ORDER BY position, id`, projectID)
+		if err != nil {
+			return err
+		}
+		exp.Stages, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.ExportedStage, error) {
+			var s projectmodel.ExportedStage
+			err := row.Scan(&s.ID, &s.Title, &s.WIPLimit, &s.IsDone)
+			return s, err
+		})
+		if err != nil {
+			return err
+		}
+
+		rows, err = tx.Query(ctx, `
+			SELECT id, name, color FROM project_labels
+			WHERE project_id = $1 ORDER BY id`, projectID)
+		if err != nil {
+			return err
+		}
+		exp.Labels, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.ExportedLabel, error) {
+			var l projectmodel.ExportedLabel
+			err := row.Scan(&l.ID, &l.Name, &l.Color)
+			return l, err
+		})
+		if err != nil {
+			return err
+		}
+
+		rows, err = tx.Query(ctx, `
+			SELECT t.id, t.number, t.stage_id, t.status, t.priority, t.start_date, t.deadline,
+			       t.title, t.description, t.full_description, t.created_at, t.completed_at,
+			       COALESCE(ARRAY_AGG(f.file ORDER BY f.file) FILTER (WHERE f.file IS NOT NULL), '{}'),
+			       ARRAY(SELECT label_id FROM task_labels WHERE task_id = t.id ORDER BY label_id),
+			       ARRAY(SELECT u.username FROM task_assignees a JOIN user_user u ON u.id = a.user_id
+			             WHERE a.task_id = t.id ORDER BY a.assigned_at, u.id),
+			       ARRAY(SELECT u.username FROM task_watchers w JOIN user_user u ON u.id = w.user_id
+			             WHERE w.task_id = t.id ORDER BY u.username),
+			       t.parent_id,
+			       (SELECT json_agg(json_build_object('text', i.text, 'done', i.done, 'assignee', u.username)
+			                        ORDER BY i.position, i.id)
+			        FROM task_checklist_items i LEFT JOIN user_user u ON u.id = i.assignee_id
+			        WHERE i.task_id = t.id),
+			       (SELECT json_agg(json_build_object('author', u.username, 'body', c.body,
+			                                          'created_at', c.created_at, 'edited_at', c.edited_at)
+			                        ORDER BY c.created_at, c.id)
+			        FROM task_comments c LEFT JOIN user_user u ON u.id = c.author_id
+			        WHERE c.task_id = t.id)
+			FROM task_task t LEFT JOIN task_files f ON f.task_id = t.id
+			WHERE t.project_id = $1
+			GROUP BY t.id ORDER BY t.id`, projectID)
+		if err != nil {
+			return err
+		}
+		exp.Tasks, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.ExportedTask, error) {
+			var t projectmodel.ExportedTask
+			err := row.Scan(&t.ID, &t.Number, &t.StageID, &t.Status, &t.Priority, &t.StartDate, &t.Deadline,
+				&t.Title, &t.Description, &t.FullDescription, &t.CreatedAt, &t.CompletedAt, &t.Files,
+				&t.Labels, &t.Assignees, &t.Watchers, &t.ParentID, &t.Checklist, &t.Comments)
+			return t, err
+		})
+		if err != nil {
+			return err
+		}
+
+		rows, err = tx.Query(ctx, `
+			SELECT u.username, c.message, c.create_at
+			FROM project_comments c JOIN user_user u ON u.id = c.user_id
+			WHERE c.project_id = $1 ORDER BY c.create_at`, projectID)
+		if err != nil {
+			return err
+		}
+		exp.Comments, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.ExportedNote, error) {
+			var n projectmodel.ExportedNote
+			err := row.Scan(&n.Username, &n.Message, &n.CreatedAt)
+			return n, err
+		})
+		if err != nil {
+			return err
+		}
+
+		rows, err = tx.Query(ctx, `SELECT file FROM project_files WHERE project_id = $1 ORDER BY file`, projectID)
+		if err != nil {
+			return err
+		}
+		exp.Files, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export project: %w", err)
+	}
+	return &exp, nil
+}
+
+// ImportProject recreates an exported project in the active workspace under ownerID.
+// Tasks get new IDs, members and comment authors are matched by username; task comments keep their dates.
+// rename maps an archive file path to the path stored for the new project; nil keeps paths as they are.
+// With dryRun the import runs inside a transaction that is rolled back, so the report lists real conflicts;
+// an import with conflicts is rolled back as well and the report has no project.
+func (db *DB) ImportProject(ctx context.Context, exp *projectmodel.ProjectExport, ownerID int, rename func(projectID int, path string) string, dryRun bool) (*projectmodel.ImportReport, error) {
+	report := &projectmodel.ImportReport{
+		DryRun:    dryRun,
+		TaskIDs:   map[int]int{},
+		Conflicts: []string{},
+		Warnings:  []string{},
+	}
+	if exp.Version != ExportVersion {
+		report.Conflicts = append(report.Conflicts, fmt.Sprintf("unsupported archive version %d", exp.Version))
+		return report, nil
+	}
+	if exp.Project.Title == "" {
+		report.Conflicts = append(report.Conflicts, "project title is empty")
+		return report, nil
+	}
+	if rename == nil {
+		rename = func(_ int, path string) string { return path }
+	}
+
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var exists bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM project_project
+			              WHERE title = $1 AND workspace_id IS NOT DISTINCT FROM $2)`,
+			exp.Project.Title, workspaceArg(ctx)).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("project %q already exists in this workspace", exp.Project.Title))
+		}
+
+		var projectID int
+		err = tx.QueryRow(ctx, `
+			INSERT INTO project_project (title, user_id, workspace_id)
+			VALUES ($1, $2, $3)
+			RETURNING id`, exp.Project.Title, ownerID, workspaceArg(ctx)).Scan(&projectID)
+		if err != nil {
+			return err
+		}
+		if err := addProjectMember(ctx, tx, projectID, ownerID, projectmodel.RoleOwner); err != nil {
+			return err
+		}
+		base := DeriveProjectKey(exp.Project.Title)
+		if key, err := NormalizeProjectKey(exp.Project.Key); err == nil {
+			base = key
+		}
+		key, err := assignProjectKey(ctx, tx, projectID, base, false)
+		if err != nil {
+			return err
+		}
+		if exp.Project.Key != "" && key != base {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("project key %q is taken, using %q", base, key))
+		}
+
+		stageInputs := make([]StageInput, 0, len(exp.Stages))
+		for _, st := range exp.Stages {
+			stageInputs = append(stageInputs, StageInput{Title: st.Title, WIPLimit: st.WIPLimit, IsDone: st.IsDone})
+		}
+		if len(stageInputs) == 0 {
+			stageInputs = defaultStages
+		}
+		stageIDs, err := createStages(ctx, tx, projectID, stageInputs)
+		if err != nil {
+			return err
+		}
+		stages := make(map[int]int, len(exp.Stages))
+		for i, st := range exp.Stages {
+			stages[st.ID] = stageIDs[i]
+		}
+
+		labels := make(map[int]int, len(exp.Labels))
+		labelNames := map[string]int{}
+		for _, l := range exp.Labels {
+			if id, ok := labelNames[strings.ToLower(strings.TrimSpace(l.Name))]; ok {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("label %q appears more than once", l.Name))
+				labels[l.ID] = id
+				continue
+			}
+			input := LabelInput{Name: l.Name, Color: l.Color}
+			if _, err := NormalizeLabelColor(l.Color); err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("label %q has invalid color %q, a palette color is used", l.Name, l.Color))
+				input.Color = ""
+			}
+			id, err := createLabel(ctx, tx, projectID, input)
+			if errors.Is(err, ErrInvalidLabel) {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("label %d has an invalid name %q", l.ID, l.Name))
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			labels[l.ID] = id
+			labelNames[strings.ToLower(strings.TrimSpace(l.Name))] = id
+		}
+
+		users := map[string]int{}
+		lookup := func(username string) (int, bool) {
+			if id, ok := users[username]; ok {
+				return id, id > 0
+			}
+			var id int
+			err := tx.QueryRow(ctx, `SELECT id FROM user_user WHERE username = $1`, username).Scan(&id)
+			users[username] = id
+			return id, err == nil
+		}
+
+		members := map[int]bool{ownerID: true}
+		for _, m := range exp.Members {
+			userID, ok := lookup(m.Username)
+			if !ok {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("member %q does not exist", m.Username))
+				continue
+			}
+			members[userID] = true
+			if userID == ownerID {
+				continue
+			}
+			role := m.Role
+			if role == projectmodel.RoleOwner {
+				role = projectmodel.RoleMaintainer
+			}
+			if err := addProjectMember(ctx, tx, projectID, userID, role); err != nil {
+				return err
+			}
+		}
+
+		// Номера задач сохраняются, чтобы ссылки вида KEY-42 остались прежними;
+		// задачи без номера получают следующие после максимального
+		maxNumber := 0
+		for _, t := range exp.Tasks {
+			maxNumber = max(maxNumber, t.Number)
+		}
+		if _, err := tx.Exec(ctx, `UPDATE project_project SET task_seq = $2 WHERE id = $1`, projectID, maxNumber); err != nil {
+			return err
+		}
+		numbers := map[int]bool{}
+		for _, t := range exp.Tasks {
+			var number *int
+			if t.Number > 0 && !numbers[t.Number] {
+				numbers[t.Number] = true
+				number = &t.Number
+			} else if t.Number > 0 {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("task number %d appears more than once", t.Number))
+			}
+			var stageID *int
+			if t.StageID != nil {
+				id, ok := stages[*t.StageID]
+				if !ok {
+					report.Conflicts = append(report.Conflicts, fmt.Sprintf("task %d refers to unknown stage %d", t.ID, *t.StageID))
+				} else {
+					stageID = &id
+				}
+			}
+			// Архивы до появления приоритетов не содержат его
+			if t.Priority == "" {
+				t.Priority = projectmodel.PriorityMedium
+			}
+			if !slices.Contains(projectmodel.Priorities, t.Priority) {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("task %d has unknown priority %q", t.ID, t.Priority))
+				t.Priority = projectmodel.PriorityMedium
+			}
+			if t.StartDate != nil && t.Deadline != nil && t.StartDate.After(*t.Deadline) {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("task %d starts after its deadline", t.ID))
+				t.StartDate = nil
+			}
+			var newID int
+			err := tx.QueryRow(ctx, `
+				INSERT INTO task_task (project_id, number, stage_id, status, priority, start_date, deadline,
+				                       title, description, full_description, created_at, completed_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+				RETURNING id`, projectID, number, stageID, t.Status, t.Priority, t.StartDate, t.Deadline,
+				t.Title, t.Description, t.FullDescription, t.CreatedAt, t.CompletedAt).Scan(&newID)
+			if err != nil {
+				return err
+			}
+			if _, dup := report.TaskIDs[t.ID]; dup {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("task id %d appears more than once", t.ID))
+			}
+			report.TaskIDs[t.ID] = newID
+			for _, file := range t.Files {
+				if _, err := tx.Exec(ctx, `INSERT INTO task_files (task_id, file) VALUES ($1, $2)`, newID, rename(projectID, file)); err != nil {
+					return err
+				}
+			}
+			for _, table := range []struct {
+				name      string
+				usernames []string
+			}{{"task_assignees", t.Assignees}, {"task_watchers", t.Watchers}} {
+				for _, username := range table.usernames {
+					userID, ok := lookup(username)
+					if !ok || !members[userID] {
+						report.Warnings = append(report.Warnings, fmt.Sprintf("task %d: %q is not a project member, skipped from %s", t.ID, username, strings.TrimPrefix(table.name, "task_")))
+						continue
+					}
+					_, err := tx.Exec(ctx, `INSERT INTO `+table.name+` (task_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, newID, userID)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			for i, item := range t.Checklist {
+				var assigneeID *int
+				if item.Assignee != "" {
+					if userID, ok := lookup(item.Assignee); ok && members[userID] {
+						assigneeID = &userID
+					} else {
+						report.Warnings = append(report.Warnings, fmt.Sprintf("task %d: checklist assignee %q is not a project member", t.ID, item.Assignee))
+					}
+				}
+				text, err := validateChecklistText(item.Text)
+				if err != nil {
+					report.Conflicts = append(report.Conflicts, fmt.Sprintf("task %d: checklist item %d is empty or too long", t.ID, i+1))
+					continue
+				}
+				_, err = tx.Exec(ctx, `
+					INSERT INTO task_checklist_items (task_id, text, done, position, assignee_id, done_at)
+					VALUES ($1, $2, $3, $4, $5, CASE WHEN $3 THEN NOW() END)`, newID, text, item.Done, i, assigneeID)
+				if err != nil {
+					return err
+				}
+			}
+			for i, c := range t.Comments {
+				body, err := validateComment(c.Body)
+				if err != nil {
+					report.Conflicts = append(report.Conflicts, fmt.Sprintf("task %d: comment %d is empty or too long", t.ID, i+1))
+					continue
+				}
+				var authorID *int
+				if c.Author != "" {
+					if userID, ok := lookup(c.Author); ok {
+						authorID = &userID
+					} else {
+						report.Warnings = append(report.Warnings, fmt.Sprintf("task %d: comment author %q does not exist, the owner is used", t.ID, c.Author))
+						authorID = &ownerID
+					}
+				}
+				_, err = tx.Exec(ctx, `
+					INSERT INTO task_comments (task_id, author_id, body, body_html, created_at, edited_at)
+					VALUES ($1, $2, $3, $4, $5, $6)`, newID, authorID, body, markdown.Render(body), c.CreatedAt, c.EditedAt)
+				if err != nil {
+					return err
+				}
+			}
+			for _, labelID := range t.Labels {
+				id, ok := labels[labelID]
+				if !ok {
+					report.Conflicts = append(report.Conflicts, fmt.Sprintf("task %d refers to unknown label %d", t.ID, labelID))
+					continue
+				}
+				_, err := tx.Exec(ctx, `INSERT INTO task_labels (task_id, label_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, newID, id)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Связи подзадач восстанавливаются, когда все задачи уже созданы
+		for _, t := range exp.Tasks {
+			if t.ParentID == nil {
+				continue
+			}
+			parentID, ok := report.TaskIDs[*t.ParentID]
+			if !ok || *t.ParentID == t.ID {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("task %d refers to unknown parent task %d", t.ID, *t.ParentID))
+				continue
+			}
+			if _, err := tx.Exec(ctx, `UPDATE task_task SET parent_id = $2 WHERE id = $1`, report.TaskIDs[t.ID], parentID); err != nil {
+				return err
+			}
+		}
+
+		for _, c := range exp.Comments {
+			authorID, ok := lookup(c.Username)
+			if !ok {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("comment author %q does not exist, the owner is used", c.Username))
+				authorID = ownerID
+			}
+			_, err := tx.Exec(ctx, `
+				INSERT INTO project_comments (project_id, user_id, message, create_at)
+				VALUES ($1, $2, $3, $4)`, projectID, authorID, c.Message, c.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, file := range exp.Files {
+			if _, err := tx.Exec(ctx, `INSERT INTO project_files (project_id, file) VALUES ($1, $2)`, projectID, rename(projectID, file)); err != nil {
+				return err
+			}
+		}
+
+		err = recordActivity(ctx, tx, projectID, projectmodel.ActivityProjectCreated, map[string]any{
+			"title":         exp.Project.Title,
+			"imported_from": exp.Project.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		if len(report.Conflicts) > 0 {
+			return errImportConflicts
+		}
+		report.ProjectID = projectID
+		return nil
+	})
+	if errors.Is(err, errImportConflicts) {
+		report.TaskIDs = map[int]int{}
+		return report, nil
+	}
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, fmt.Errorf("failed to import project: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,49 @@
+package db
+
+import "time"
+
+// Неэкспортируемые функции, проверяемые внешними тестами пакета
+var (
+	HideTaskRef            = hideTaskRef
+	ApplyBlockedDonePolicy = applyBlockedDonePolicy
+	ApplyTaskPatch         = TaskPatch.apply
+	TaskPatchFields        = TaskPatch.fields
+	ValidateBulkOperation  = BulkOperation.validate
+)
+
+// SprintChange — изменение поля задачи в истории спринта
+type SprintChange struct {
+	Field     string
+	OldValue  *string
+	ChangedAt time.Time
+}
+
+// SprintTask — задача спринта с текущими полями и изменениями, от новых к старым
+type SprintTask struct {
+	SprintID *int
+	Points   int
+	Done     bool
+	Changes  []SprintChange
+}
+
+func (t SprintTask) sprintTask() sprintTask {
+	st := sprintTask{sprintID: t.SprintID, points: t.Points, done: t.Done}
+	for _, c := range t.Changes {
+		st.changes = append(st.changes, fieldChange{field: c.Field, oldValue: c.OldValue, changedAt: c.ChangedAt})
+	}
+	return st
+}
+
+// SprintTaskBefore возвращает спринт, очки и завершённость задачи на момент перед at
+func SprintTaskBefore(t SprintTask, at time.Time) (*int, int, bool) {
+	return t.sprintTask().before(at)
+}
+
+// SprintHistoryBefore возвращает объём и остаток спринта на момент перед at
+func SprintHistoryBefore(sprintID int, tasks []SprintTask, at time.Time) (scope, remaining int) {
+	h := sprintHistory{sprintID: sprintID}
+	for _, t := range tasks {
+		h.tasks = append(h.tasks, t.sprintTask())
+	}
+	return h.before(at)
+}
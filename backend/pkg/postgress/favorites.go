@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// recentProjectsLimit is how many recently viewed projects are kept per user in each workspace
+const recentProjectsLimit = 20
+
+// GetFavoriteProjects returns the user's favorite projects of the active workspace in their custom order
+func (db *DB) GetFavoriteProjects(ctx context.Context, userID int) ([]projectmodel.ProjectSummary, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT p.id, p.title, COALESCE(p.key, ''), p.workspace_id, f.position
+		FROM project_favorites f
+		JOIN project_project p ON p.id = f.project_id
+		WHERE f.user_id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2
+		ORDER BY f.position, f.created_at`, userID, workspaceArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query favorite projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []projectmodel.ProjectSummary{}
+	for rows.Next() {
+		var p projectmodel.ProjectSummary
+		if err := rows.Scan(&p.ID, &p.Title, &p.Key, &p.WorkspaceID, &p.Position); err != nil {
+			return nil, fmt.Errorf("failed to scan favorite project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// AddFavoriteProject appends a project to the end of the user's favorites
+func (db *DB) AddFavoriteProject(ctx context.Context, userID, projectID int) error {
+	tag, err := db.Pool.Exec(ctx, `
+		INSERT INTO project_favorites (user_id, project_id, position)
+		SELECT $1, p.id, COALESCE((SELECT MAX(position) + 1 FROM project_favorites WHERE user_id = $1), 0)
+		FROM project_project p
+		WHERE p.id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3
+		ON CONFLICT (user_id, project_id) DO NOTHING`, userID, projectID, workspaceArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to add favorite project: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		var exists bool
+		err := db.Pool.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM project_favorites WHERE user_id = $1 AND project_id = $2)`,
+			userID, projectID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to add favorite project: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("project not found: %w", pgx.ErrNoRows)
+		}
+	}
+	return nil
+}
+
+// RemoveFavoriteProject removes a project from the user's favorites
+func (db *DB) RemoveFavoriteProject(ctx context.Context, userID, projectID int) error {
+	tag, err := db.Pool.Exec(ctx, `
+		DELETE FROM project_favorites WHERE user_id = $1 AND project_id = $2`, userID, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to remove favorite project: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("project %d is not a favorite: %w", projectID, pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// ReorderFavoriteProjects sets the order of the given favorites to the order of projectIDs.
+// Favorites missing from projectIDs keep their relative order after the listed ones.
+func (db *DB) ReorderFavoriteProjects(ctx context.Context, userID int, projectIDs []int) error {
+	_, err := db.Pool.Exec(ctx, `
+		WITH listed AS (
+			SELECT project_id, ord FROM unnest($2::int[]) WITH ORDINALITY AS u(project_id, ord)
+		), ordered AS (
+			SELECT f.project_id,
+			       ROW_NUMBER() OVER (ORDER BY l.ord NULLS LAST, f.position, f.created_at) - 1 AS position
+			FROM project_favorites f
+			LEFT JOIN listed l ON l.project_id = f.project_id
+			WHERE f.user_id = $1
+		)
+		UPDATE project_favorites f SET position = o.position
+		FROM ordered o
+		WHERE f.user_id = $1 AND f.project_id = o.project_id`, userID, projectIDs)
+	if err != nil {
+		return fmt.Errorf("failed to reorder favorite projects: %w", err)
+	}
+	return nil
+}
+
+// RecordProjectView marks a project as just viewed by the user and trims the user's history in the project's workspace
+func (db *DB) RecordProjectView(ctx context.Context, userID, projectID int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO project_recent_views (user_id, project_id, viewed_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT (user_id, project_id) DO UPDATE SET viewed_at = EXCLUDED.viewed_at`, userID, projectID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			WITH workspace_views AS (
+				SELECT v.project_id, v.viewed_at
+				FROM project_recent_views v
+				JOIN project_project p ON p.id = v.project_id
+				WHERE v.user_id = $1
+				  AND p.workspace_id IS NOT DISTINCT FROM (SELECT workspace_id FROM project_project WHERE id = $2)
+			)
+			DELETE FROM project_recent_views
+			WHERE user_id = $1
+			  AND project_id IN (SELECT project_id FROM workspace_views)
+			  AND project_id NOT IN (
+				SELECT project_id FROM workspace_views
+				ORDER BY viewed_at DESC
+				LIMIT $3
+			)`, userID, projectID, recentProjectsLimit)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record project view: %w", err)
+	}
+	return nil
+}
+
+// GetRecentProjects returns the projects of the active workspace the user viewed most recently
+func (db *DB) GetRecentProjects(ctx context.Context, userID, limit int) ([]projectmodel.ProjectSummary, error) {
+	if limit <= 0 || limit > recentProjectsLimit {
+		limit = recentProjectsLimit
+	}
+	rows, err := db.Pool.Query(ctx, `
+		SELECT p.id, p.title, COALESCE(p.key, ''), p.workspace_id, v.viewed_at
+		FROM project_recent_views v
+		JOIN project_project p ON p.id = v.project_id
+		WHERE v.user_id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2
+		ORDER BY v.viewed_at DESC
+		LIMIT $3`, userID, workspaceArg(ctx), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []projectmodel.ProjectSummary{}
+	for rows.Next() {
+		var p projectmodel.ProjectSummary
+		if err := rows.Scan(&p.ID, &p.Title, &p.Key, &p.WorkspaceID, &p.ViewedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recent project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// defaultHistoryLimit is the page size of a task history when none is requested
+const defaultHistoryLimit = 100
+
+// ErrNotRestorable is returned when a history entry is of a field that cannot be restored
+var ErrNotRestorable = errors.New("only full_description can be restored")
+
+// GetTaskHistory returns a page of the task's field changes, newest first.
+// The history is written by database triggers, so it covers every change of the task.
+func (db *DB) GetTaskHistory(ctx context.Context, taskID int, before int64, limit int) ([]projectmodel.TaskChange, error) {
+	if limit <= 0 || limit > defaultHistoryLimit {
+		limit = defaultHistoryLimit
+	}
+	if _, err := taskProject(ctx, db.Pool, taskID); err != nil {
+		return nil, err
+	}
+	rows, err := db.Pool.Query(ctx, `
+		SELECT h.id, h.task_id, h.field, h.old_value, h.new_value,
+		       (SELECT json_build_object('id', u.id, 'username', u.username) FROM user_user u WHERE u.id = h.actor_id),
+		       h.changed_at
+		FROM task_history h
+		WHERE h.task_id = $1 AND ($2::bigint = 0 OR h.id < $2)
+		ORDER BY h.id DESC
+		LIMIT $3`, taskID, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task history: %w", err)
+	}
+	changes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.TaskChange, error) {
+		var c projectmodel.TaskChange
+		err := row.Scan(&c.ID, &c.TaskID, &c.Field, &c.OldValue, &c.NewValue, &c.Actor, &c.ChangedAt)
+		return c, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan task history: %w", err)
+	}
+	return changes, nil
+}
+
+// RestoreFullDescription sets the task's full description back to the value it had before the change.
+// The restore is itself recorded in the history.
+func (db *DB) RestoreFullDescription(ctx context.Context, projectID, taskID int, changeID int64) error {
+	if err := checkTask(ctx, db.Pool, projectID, taskID); err != nil {
+		return err
+	}
+	var (
+		field string
+		value *string
+	)
+	err := db.Pool.QueryRow(ctx, `
+		SELECT field, old_value FROM task_history
+		WHERE id = $2 AND task_id = $1`, taskID, changeID).Scan(&field, &value)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("history entry %d not found: %w", changeID, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query history entry: %w", err)
+	}
+	if field != "full_description" {
+		return fmt.Errorf("%w: entry %d changed %s", ErrNotRestorable, changeID, field)
+	}
+	var restored string
+	if value != nil {
+		restored = *value
+	}
+	return db.PatchTask(ctx, projectID, taskID, TaskPatch{FullDescription: Set(restored)})
+}
@@ -0,0 +1,197 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+var (
+	// ErrInvalidKey is returned for project keys that do not match keyPattern
+	ErrInvalidKey = errors.New("project key must be 2-10 latin letters or digits starting with a letter")
+	// ErrKeyTaken is returned when another project of the workspace uses or used the key
+	ErrKeyTaken = errors.New("project key is already taken")
+)
+
+var (
+	keyPattern     = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+	taskRefPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]{1,9})-([1-9][0-9]*)$`)
+)
+
+// cyrillicKeyLetters transliterates Russian letters for generated project keys
+var cyrillicKeyLetters = map[rune]string{
+	'А': "A", 'Б': "B", 'В': "V", 'Г': "G", 'Д': "D", 'Е': "E", 'Ё': "E", 'Ж': "ZH", 'З': "Z",
+	'И': "I", 'Й': "Y", 'К': "K", 'Л': "L", 'М': "M", 'Н': "N", 'О': "O", 'П': "P", 'Р': "R",
+	'С': "S", 'Т': "T", 'У': "U", 'Ф': "F", 'Х': "KH", 'Ц': "TS", 'Ч': "CH", 'Ш': "SH", 'Щ': "SCH",
+	'Ы': "Y", 'Э': "E", 'Ю': "YU", 'Я': "YA",
+}
+
+// NormalizeProjectKey upper-cases a key and checks its format
+func NormalizeProjectKey(key string) (string, error) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if !keyPattern.MatchString(key) {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
+
+// ParseTaskRef splits a reference like "HACK-42" into the project key and the task number
+func ParseTaskRef(ref string) (string, int, bool) {
+	m := taskRefPattern.FindStringSubmatch(strings.TrimSpace(ref))
+	if m == nil {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return strings.ToUpper(m[1]), number, true
+}
+
+// DeriveProjectKey builds a key from a project title: initials of several words or the start of a single word
+func DeriveProjectKey(title string) string {
+	var words []string
+	for _, field := range strings.FieldsFunc(title, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		var b strings.Builder
+		for _, r := range strings.ToUpper(field) {
+			switch {
+			case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+				b.WriteRune(r)
+			default:
+				b.WriteString(cyrillicKeyLetters[r])
+			}
+		}
+		if w := strings.TrimLeft(b.String(), "0123456789"); w != "" {
+			words = append(words, w)
+		}
+	}
+
+	var key string
+	switch {
+	case len(words) == 0:
+		key = "PRJ"
+	case len(words) == 1:
+		key = words[0]
+	default:
+		for _, w := range words {
+			key += w[:1]
+		}
+		if len(key) < 2 {
+			key = words[0]
+		}
+	}
+	if len(key) > 4 {
+		key = key[:4]
+	}
+	if len(key) < 2 {
+		key += "P"
+	}
+	return key
+}
+
+// assignProjectKey gives a new project its key. A strict key must be free as is;
+// otherwise the base gets a numeric suffix until an unused key is found.
+func assignProjectKey(ctx context.Context, tx pgx.Tx, projectID int, base string, strict bool) (string, error) {
+	candidates := []string{base}
+	if !strict {
+		for i := 2; i < 100; i++ {
+			candidates = append(candidates, base+strconv.Itoa(i))
+		}
+	}
+
+	for _, key := range candidates {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO project_keys (key, workspace_id, project_id)
+			SELECT $1, workspace_id, id FROM project_project WHERE id = $2
+			ON CONFLICT DO NOTHING`, key, projectID)
+		if err != nil {
+			return "", err
+		}
+		if tag.RowsAffected() == 0 {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `UPDATE project_project SET key = $1 WHERE id = $2`, key, projectID); err != nil {
+			return "", err
+		}
+		return key, nil
+	}
+	return "", ErrKeyTaken
+}
+
+// RenameProjectKey changes the project key; references with previous keys stay resolvable
+func (db *DB) RenameProjectKey(ctx context.Context, projectID int, newKey string) error {
+	key, err := NormalizeProjectKey(newKey)
+	if err != nil {
+		return err
+	}
+	err = db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var oldKey string
+		err := tx.QueryRow(ctx, `
+			SELECT key FROM project_project
+			WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $2
+			FOR UPDATE`, projectID, workspaceArg(ctx)).Scan(&oldKey)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("project not found: %w", err)
+			}
+			return err
+		}
+		if oldKey == key {
+			return nil
+		}
+
+		var owner int
+		err = tx.QueryRow(ctx, `
+			INSERT INTO project_keys (key, workspace_id, project_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (COALESCE(workspace_id, 0), key) DO UPDATE SET key = EXCLUDED.key
+			RETURNING project_id`, key, workspaceArg(ctx), projectID).Scan(&owner)
+		if err != nil {
+			return err
+		}
+		if owner != projectID {
+			return ErrKeyTaken
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE project_project SET key = $1 WHERE id = $2`, key, projectID); err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityProjectUpdated, map[string]any{
+			"key":     key,
+			"old_key": oldKey,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename project key: %w", err)
+	}
+	return nil
+}
+
+// GetTaskByRef resolves a reference like "HACK-42" through current and previous project keys
+func (db *DB) GetTaskByRef(ctx context.Context, ref string) (*projectmodel.Task, error) {
+	key, number, ok := ParseTaskRef(ref)
+	if !ok {
+		return nil, fmt.Errorf("invalid task reference %q: %w", ref, pgx.ErrNoRows)
+	}
+	var id int
+	err := db.Pool.QueryRow(ctx, `
+		SELECT t.id
+		FROM project_keys k
+		JOIN task_task t ON t.project_id = k.project_id
+		WHERE COALESCE(k.workspace_id, 0) = COALESCE($1::int, 0) AND k.key = $2 AND t.number = $3`,
+		workspaceArg(ctx), key, number).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("task %s not found: %w", ref, err)
+		}
+		return nil, fmt.Errorf("failed to resolve task reference: %w", err)
+	}
+	return db.GetTaskByID(ctx, id)
+}
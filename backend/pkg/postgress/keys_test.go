@@ -0,0 +1,72 @@
+package db_test
+
+import (
+	"testing"
+
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveProjectKey(t *testing.T) {
+	tests := []struct {
+		title, want string
+	}{
+		{"Hackathon", "HACK"},
+		{"Web Shop", "WS"},
+		{"Alpha Beta Gamma Delta Epsilon", "ABGD"},
+		{"Q3 Roadmap", "QR"},
+		{"Мобильное приложение", "MP"},
+		{"Проект", "PROE"},
+		{"Щука", "SCHU"},
+		{"Юг", "YUG"},
+		{"Ёлка", "ELKA"},
+		{"Съезд", "SEZD"},
+		{"Ы", "YP"},
+		{"x", "XP"},
+		{"Émile", "MILE"},
+		{"2025", "PRJ"},
+		{"ь", "PRJ"},
+		{"  --  ", "PRJ"},
+		{"", "PRJ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			key := db.DeriveProjectKey(tt.title)
+			require.Equal(t, tt.want, key)
+			_, err := db.NormalizeProjectKey(key)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestParseTaskRef(t *testing.T) {
+	tests := []struct {
+		ref    string
+		key    string
+		number int
+		ok     bool
+	}{
+		{"HACK-42", "HACK", 42, true},
+		{" hack-7 ", "HACK", 7, true},
+		{"A1-1", "A1", 1, true},
+		{"ABCDEFGHIJ-3", "ABCDEFGHIJ", 3, true},
+		{"ABCDEFGHIJK-3", "", 0, false},
+		{"H-1", "", 0, false},
+		{"1AB-1", "", 0, false},
+		{"HACK-0", "", 0, false},
+		{"HACK-042", "", 0, false},
+		{"HACK-99999999999999999999", "", 0, false},
+		{"HACK 42", "", 0, false},
+		{"HACK-42x", "", 0, false},
+		{"ХАК-1", "", 0, false},
+		{"", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			key, number, ok := db.ParseTaskRef(tt.ref)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.key, key)
+			require.Equal(t, tt.number, number)
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+const maxLabelNameLen = 50
+
+var (
+	// ErrInvalidLabel is returned for empty or too long label names and malformed colors
+	ErrInvalidLabel = errors.New("label name must be 1-50 characters and color a hex value like #1e88e5")
+	// ErrLabelExists is returned when the project already has a label with the same name
+	ErrLabelExists = errors.New("label with this name already exists")
+)
+
+var labelColorPattern = regexp.MustCompile(`^#(?:[0-9a-f]{3}|[0-9a-f]{6})$`)
+
+// labelPalette supplies colors for labels created without one, in turn
+var labelPalette = []string{
+	"#e53935", "#fb8c00", "#fdd835", "#43a047", "#00acc1", "#1e88e5", "#8e24aa", "#6d4c41", "#757575",
+}
+
+// LabelInput carries the editable fields of a label
+type LabelInput struct {
+	Name  string
+	Color string // #rgb or #rrggbb; empty picks the next palette color
+}
+
+// NormalizeLabelColor lower-cases a hex color and expands the short #rgb form
+func NormalizeLabelColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if !labelColorPattern.MatchString(color) {
+		return "", ErrInvalidLabel
+	}
+	if len(color) == 4 {
+		color = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	}
+	return color, nil
+}
+
+// normalize trims the name and normalizes the color; used is the number of labels the project has
+func (in LabelInput) normalize(used int) (LabelInput, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" || len([]rune(in.Name)) > maxLabelNameLen {
+		return in, ErrInvalidLabel
+	}
+	if in.Color == "" {
+		in.Color = labelPalette[used%len(labelPalette)]
+		return in, nil
+	}
+	color, err := NormalizeLabelColor(in.Color)
+	in.Color = color
+	return in, err
+}
+
+// labelError maps unique violations on the label name to ErrLabelExists
+func labelError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrLabelExists
+	}
+	return err
+}
+
+// createLabel adds a label to a project; the caller holds the project row lock
+func createLabel(ctx context.Context, q querier, projectID int, input LabelInput) (int, error) {
+	var used int
+	if err := q.QueryRow(ctx, `SELECT COUNT(*) FROM project_labels WHERE project_id = $1`, projectID).Scan(&used); err != nil {
+		return 0, err
+	}
+	input, err := input.normalize(used)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	err = q.QueryRow(ctx, `
+		INSERT INTO project_labels (project_id, name, color)
+		VALUES ($1, $2, $3)
+		RETURNING id`, projectID, input.Name, input.Color).Scan(&id)
+	return id, labelError(err)
+}
+
+// lockProject locks a project of the active workspace for the rest of the transaction
+func lockProject(ctx context.Context, tx pgx.Tx, projectID int) error {
+	var id int
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM project_project
+		WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $2
+		FOR UPDATE`, projectID, workspaceArg(ctx)).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("project not found: %w", err)
+	}
+	return err
+}
+
+// checkProjectIDs returns a not found error for the first ID of ids missing from table within the project
+func checkProjectIDs(ctx context.Context, q querier, table, what string, projectID int, ids []int) error {
+	rows, err := q.Query(ctx, `SELECT id FROM `+table+` WHERE project_id = $1 AND id = ANY($2)`, projectID, ids)
+	if err != nil {
+		return err
+	}
+	found, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if !slices.Contains(found, id) {
+			return fmt.Errorf("%s %d not found in project %d: %w", what, id, projectID, pgx.ErrNoRows)
+		}
+	}
+	return nil
+}
+
+// GetLabels returns the project's labels by name with the number of tasks using each
+func (db *DB) GetLabels(ctx context.Context, projectID int) ([]projectmodel.Label, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT l.id, l.project_id, l.name, l.color,
+		       (SELECT COUNT(*) FROM task_labels tl WHERE tl.label_id = l.id)
+		FROM project_labels l
+		JOIN project_project p ON p.id = l.project_id
+		WHERE l.project_id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2
+		ORDER BY lower(l.name), l.id`, projectID, workspaceArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query labels: %w", err)
+	}
+	labels, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.Label, error) {
+		var l projectmodel.Label
+		err := row.Scan(&l.ID, &l.ProjectID, &l.Name, &l.Color, &l.TaskCount)
+		return l, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan labels: %w", err)
+	}
+	return labels, nil
+}
+
+// CreateLabel adds a label to the project's catalog
+func (db *DB) CreateLabel(ctx context.Context, projectID int, input LabelInput) (int, error) {
+	var id int
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		// Блокировка проекта не даёт двум меткам взять один цвет палитры
+		if err := lockProject(ctx, tx, projectID); err != nil {
+			return err
+		}
+		var err error
+		id, err = createLabel(ctx, tx, projectID, input)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create label: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateLabel renames and recolors a label; an empty color keeps the current one
+func (db *DB) UpdateLabel(ctx context.Context, projectID, labelID int, input LabelInput) error {
+	keepColor := input.Color == ""
+	input, err := input.normalize(0)
+	if err != nil {
+		return fmt.Errorf("failed to update label: %w", err)
+	}
+	tag, err := db.Pool.Exec(ctx, `
+		UPDATE project_labels l
+		SET name = $3, color = CASE WHEN $5 THEN l.color ELSE $4 END
+		FROM project_project p
+		WHERE p.id = l.project_id AND l.id = $1 AND l.project_id = $2 AND p.workspace_id IS NOT DISTINCT FROM $6`,
+		labelID, projectID, input.Name, input.Color, keepColor, workspaceArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to update label: %w", labelError(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("label %d not found: %w", labelID, pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// DeleteLabel removes a label from the catalog and from every task
+func (db *DB) DeleteLabel(ctx context.Context, projectID, labelID int) error {
+	tag, err := db.Pool.Exec(ctx, `
+		DELETE FROM project_labels l USING project_project p
+		WHERE p.id = l.project_id AND l.id = $1 AND l.project_id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3`,
+		labelID, projectID, workspaceArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to delete label: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("label %d not found: %w", labelID, pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// SetTaskLabels attaches (or with attach false detaches) every label to every task in one transaction.
+// All tasks and labels must belong to the project. It returns the number of links changed.
+func (db *DB) SetTaskLabels(ctx context.Context, projectID int, taskIDs, labelIDs []int, attach bool) (int, error) {
+	var changed int
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var err error
+		changed, err = setTaskLabels(ctx, tx, projectID, taskIDs, labelIDs, attach)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to change task labels: %w", err)
+	}
+	return changed, nil
+}
+
+func setTaskLabels(ctx context.Context, tx pgx.Tx, projectID int, taskIDs, labelIDs []int, attach bool) (int, error) {
+	if err := lockProject(ctx, tx, projectID); err != nil {
+		return 0, err
+	}
+	if err := checkProjectIDs(ctx, tx, "task_task", "task", projectID, taskIDs); err != nil {
+		return 0, err
+	}
+	if err := checkProjectIDs(ctx, tx, "project_labels", "label", projectID, labelIDs); err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO task_labels (task_id, label_id)
+		SELECT t, l FROM unnest($1::int[]) t, unnest($2::int[]) l
+		ON CONFLICT DO NOTHING
+		RETURNING task_id, label_id`
+	if !attach {
+		query = `
+			DELETE FROM task_labels
+			WHERE task_id = ANY($1) AND label_id = ANY($2)
+			RETURNING task_id, label_id`
+	}
+	rows, err := tx.Query(ctx, query, taskIDs, labelIDs)
+	if err != nil {
+		return 0, err
+	}
+	changes := map[int][]int{}
+	var changed int
+	for rows.Next() {
+		var taskID, labelID int
+		if err := rows.Scan(&taskID, &labelID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		changes[taskID] = append(changes[taskID], labelID)
+		changed++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	key := "labels_removed"
+	if attach {
+		key = "labels_added"
+	}
+	for _, taskID := range taskIDs {
+		if len(changes[taskID]) == 0 {
+			continue
+		}
+		slices.Sort(changes[taskID])
+		err := recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskUpdated, map[string]any{
+			"task_id": taskID,
+			"fields":  []string{"labels"},
+			key:       changes[taskID],
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return changed, nil
+}
+
+// MergeLabels moves every task of label srcID to label dstID and deletes srcID.
+// It returns the number of tasks that gained dstID.
+func (db *DB) MergeLabels(ctx context.Context, projectID, srcID, dstID int) (int, error) {
+	if srcID == dstID {
+		return 0, fmt.Errorf("failed to merge labels: cannot merge a label into itself: %w", ErrInvalidLabel)
+	}
+	var relinked int
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := lockProject(ctx, tx, projectID); err != nil {
+			return err
+		}
+		if err := checkProjectIDs(ctx, tx, "project_labels", "label", projectID, []int{srcID, dstID}); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO task_labels (task_id, label_id)
+			SELECT task_id, $2 FROM task_labels WHERE label_id = $1
+			ON CONFLICT DO NOTHING`, srcID, dstID)
+		if err != nil {
+			return err
+		}
+		relinked = int(tag.RowsAffected())
+		if _, err := tx.Exec(ctx, `DELETE FROM project_labels WHERE id = $1`, srcID); err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityLabelsMerged, map[string]any{
+			"from_label": srcID,
+			"to_label":   dstID,
+			"relinked":   relinked,
+		})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to merge labels: %w", err)
+	}
+	return relinked, nil
+}
@@ -0,0 +1,306 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// blockingLockID is the advisory lock key that serializes changes of the blocking graph,
+// so two concurrent links cannot close a cycle that neither of them sees alone
+const blockingLockID = 20250422
+
+var (
+	// ErrInvalidLink is returned for an unknown link type or a task linked to itself
+	ErrInvalidLink = errors.New("invalid task link")
+	// ErrLinkExists is returned when the same link between two tasks already exists
+	ErrLinkExists = errors.New("tasks are already linked")
+	// ErrLinkCycle is returned when a blocking link would make a task block itself through other tasks
+	ErrLinkCycle = errors.New("link would create a blocking cycle")
+	// ErrNoTaskAccess is returned when the linked task is in a project the user is not a member of
+	ErrNoTaskAccess = errors.New("no access to the linked task")
+	// ErrTaskBlocked is returned when a task with open blockers is moved to a done stage and the project refuses it
+	ErrTaskBlocked = errors.New("task has unresolved blockers")
+)
+
+// storedLink turns a link of the given type from taskID to otherID into the stored direction and kind
+func storedLink(linkType string, taskID, otherID int) (kind string, source, target int, err error) {
+	switch linkType {
+	case projectmodel.LinkBlocks, projectmodel.LinkDuplicates:
+		return linkType, taskID, otherID, nil
+	case projectmodel.LinkBlockedBy:
+		return projectmodel.LinkBlocks, otherID, taskID, nil
+	case projectmodel.LinkDuplicatedBy:
+		return projectmodel.LinkDuplicates, otherID, taskID, nil
+	case projectmodel.LinkRelates:
+		return linkType, min(taskID, otherID), max(taskID, otherID), nil
+	}
+	return "", 0, 0, fmt.Errorf("%w: unknown type %q", ErrInvalidLink, linkType)
+}
+
+// taskProject returns the project of a task in the active workspace
+func taskProject(ctx context.Context, q querier, taskID int) (int, error) {
+	var projectID int
+	err := q.QueryRow(ctx, `
+		SELECT t.project_id FROM task_task t
+		JOIN project_project p ON p.id = t.project_id
+		WHERE t.id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2`, taskID, workspaceArg(ctx)).Scan(&projectID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("task %d not found: %w", taskID, err)
+	}
+	return projectID, err
+}
+
+// checkTask returns pgx.ErrNoRows unless the task belongs to the project in the active workspace
+func checkTask(ctx context.Context, q querier, projectID, taskID int) error {
+	var found bool
+	err := q.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM task_task t
+		               JOIN project_project p ON p.id = t.project_id
+		               WHERE t.id = $1 AND t.project_id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3)`,
+		taskID, projectID, workspaceArg(ctx)).Scan(&found)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("task %d not found in project %d: %w", taskID, projectID, pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// hideTaskRef hides a task referenced from projectID unless it is in the same project or in one of visible
+func hideTaskRef(ref *projectmodel.TaskRef, projectID int, visible []int) {
+	if ref.ProjectID != projectID && !slices.Contains(visible, ref.ProjectID) {
+		ref.Key, ref.Title, ref.Hidden = "", "", true
+	}
+}
+
+// viewerProjects returns those of projectIDs the context actor is a member of; without an actor there are none
+func viewerProjects(ctx context.Context, q querier, projectIDs []int) ([]int, error) {
+	actor, ok := ActorFromContext(ctx)
+	if !ok || len(projectIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := q.Query(ctx, `
+		SELECT project_id FROM project_members WHERE user_id = $1 AND project_id = ANY($2)`, actor, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// hideBlockers hides the blockers of the tasks that belong to projects the context actor cannot see
+func hideBlockers(ctx context.Context, q querier, tasks []projectmodel.Task) error {
+	var foreign []int
+	for _, t := range tasks {
+		for _, b := range t.Blockers {
+			if b.ProjectID != t.ProjectID && !slices.Contains(foreign, b.ProjectID) {
+				foreign = append(foreign, b.ProjectID)
+			}
+		}
+	}
+	if len(foreign) == 0 {
+		return nil
+	}
+	visible, err := viewerProjects(ctx, q, foreign)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		for i := range t.Blockers {
+			hideTaskRef(&t.Blockers[i], t.ProjectID, visible)
+		}
+	}
+	return nil
+}
+
+// blocksPath reports whether from already blocks to, directly or through other tasks
+func blocksPath(ctx context.Context, q querier, from, to int) (bool, error) {
+	var found bool
+	err := q.QueryRow(ctx, `
+		WITH RECURSIVE reach (task_id) AS (
+			SELECT target_id FROM task_links WHERE source_id = $1 AND kind = 'blocks'
+			UNION
+			SELECT l.target_id FROM task_links l
+			JOIN reach r ON l.source_id = r.task_id
+			WHERE l.kind = 'blocks'
+		)
+		SELECT EXISTS (SELECT 1 FROM reach WHERE task_id = $2)`, from, to).Scan(&found)
+	return found, err
+}
+
+// unresolvedBlockers returns the IDs of tasks blocking the task that are not completed
+func unresolvedBlockers(ctx context.Context, q querier, taskID int) ([]int, error) {
+	rows, err := q.Query(ctx, `
+		SELECT b.id FROM task_links l
+		JOIN task_task b ON b.id = l.source_id
+		WHERE l.target_id = $1 AND l.kind = 'blocks' AND b.completed_at IS NULL
+		ORDER BY b.id`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// GetTaskLinks returns the links of a task in both directions, oldest first.
+// Linked tasks of projects the context actor is not a member of are hidden.
+func (db *DB) GetTaskLinks(ctx context.Context, taskID int) ([]projectmodel.TaskLink, error) {
+	projectID, err := taskProject(ctx, db.Pool, taskID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("task %d not found: %w", taskID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task: %w", err)
+	}
+	rows, err := db.Pool.Query(ctx, `
+		SELECT l.id,
+		       CASE WHEN l.source_id = $1 THEN l.kind
+		            WHEN l.kind = 'blocks' THEN 'blocked_by'
+		            WHEN l.kind = 'duplicates' THEN 'duplicated_by'
+		            ELSE l.kind END,
+		       o.id, o.project_id, COALESCE(p.key || '-' || o.number, ''), o.title, o.completed_at IS NOT NULL,
+		       l.created_at
+		FROM task_links l
+		JOIN task_task o ON o.id = CASE WHEN l.source_id = $1 THEN l.target_id ELSE l.source_id END
+		JOIN project_project p ON p.id = o.project_id
+		WHERE l.source_id = $1 OR l.target_id = $1
+		ORDER BY l.created_at, l.id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task links: %w", err)
+	}
+	links, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.TaskLink, error) {
+		var l projectmodel.TaskLink
+		err := row.Scan(&l.ID, &l.Type, &l.Task.ID, &l.Task.ProjectID, &l.Task.Key, &l.Task.Title, &l.Task.Done, &l.CreatedAt)
+		return l, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan task links: %w", err)
+	}
+
+	var foreign []int
+	for _, l := range links {
+		if l.Task.ProjectID != projectID && !slices.Contains(foreign, l.Task.ProjectID) {
+			foreign = append(foreign, l.Task.ProjectID)
+		}
+	}
+	visible, err := viewerProjects(ctx, db.Pool, foreign)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project membership: %w", err)
+	}
+	for i := range links {
+		hideTaskRef(&links[i].Task, projectID, visible)
+	}
+	return links, nil
+}
+
+// AddTaskLink links the task to another task of the workspace, possibly in another project.
+// The acting user must be a member of the other task's project.
+// Blocking links are added one at a time so that a cycle in the blocking graph is always detected.
+func (db *DB) AddTaskLink(ctx context.Context, projectID, taskID int, linkType string, otherID int) (int, error) {
+	if taskID == otherID {
+		return 0, fmt.Errorf("%w: task cannot be linked to itself", ErrInvalidLink)
+	}
+	kind, source, target, err := storedLink(linkType, taskID, otherID)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = db.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := checkTask(ctx, tx, projectID, taskID)
+		if err != nil {
+			return err
+		}
+		otherProjectID, err := taskProject(ctx, tx, otherID)
+		if err != nil {
+			return err
+		}
+		if otherProjectID != projectID {
+			// Без известного пользователя доступ к чужому проекту не проверить, поэтому связь запрещается
+			actor, ok := ActorFromContext(ctx)
+			if !ok {
+				return fmt.Errorf("%w: task %d", ErrNoTaskAccess, otherID)
+			}
+			if err := checkMembers(ctx, tx, otherProjectID, []int{actor}); errors.Is(err, ErrNotMember) {
+				return fmt.Errorf("%w: task %d", ErrNoTaskAccess, otherID)
+			} else if err != nil {
+				return err
+			}
+		}
+
+		if kind == projectmodel.LinkBlocks {
+			if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, blockingLockID); err != nil {
+				return err
+			}
+			cycle, err := blocksPath(ctx, tx, target, source)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return fmt.Errorf("%w: task %d already blocks task %d", ErrLinkCycle, target, source)
+			}
+		}
+
+		var actor *int
+		if uid, ok := ActorFromContext(ctx); ok {
+			actor = &uid
+		}
+		err = tx.QueryRow(ctx, `
+			INSERT INTO task_links (source_id, target_id, kind, created_by)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+			RETURNING id`, source, target, kind, actor).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrLinkExists
+		}
+		if err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskLinked, map[string]any{
+			"task_id":   taskID,
+			"type":      linkType,
+			"linked_id": otherID,
+		})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to link tasks: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteTaskLink removes a link of the task, whichever side of it the task is on
+func (db *DB) DeleteTaskLink(ctx context.Context, projectID, taskID, linkID int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		err := checkTask(ctx, tx, projectID, taskID)
+		if err != nil {
+			return err
+		}
+		var source, target int
+		err = tx.QueryRow(ctx, `
+			DELETE FROM task_links WHERE id = $2 AND (source_id = $1 OR target_id = $1)
+			RETURNING source_id, target_id`, taskID, linkID).Scan(&source, &target)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("link %d not found: %w", linkID, err)
+		}
+		if err != nil {
+			return err
+		}
+		linkedID := target
+		if linkedID == taskID {
+			linkedID = source
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskUnlinked, map[string]any{
+			"task_id":   taskID,
+			"link_id":   linkID,
+			"linked_id": linkedID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete task link: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHideTaskRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     projectmodel.TaskRef
+		visible []int
+		hidden  bool
+	}{
+		{"same project", projectmodel.TaskRef{ID: 1, ProjectID: 10, Key: "WEB-1", Title: "Вёрстка"}, nil, false},
+		{"member of other project", projectmodel.TaskRef{ID: 2, ProjectID: 20, Key: "API-2", Title: "Эндпоинт"}, []int{30, 20}, false},
+		{"not a member", projectmodel.TaskRef{ID: 3, ProjectID: 20, Key: "API-3", Title: "Миграция"}, []int{30}, true},
+		{"no viewer", projectmodel.TaskRef{ID: 4, ProjectID: 20, Key: "API-4", Title: "Отчёт"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := tt.ref
+			db.HideTaskRef(&ref, 10, tt.visible)
+			require.Equal(t, tt.hidden, ref.Hidden)
+			require.Equal(t, tt.ref.ID, ref.ID)
+			if tt.hidden {
+				require.Empty(t, ref.Key)
+				require.Empty(t, ref.Title)
+			} else {
+				require.Equal(t, tt.ref, ref)
+			}
+		})
+	}
+}
+
+func TestApplyBlockedDonePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		blockers []int
+		want     []int
+		err      error
+	}{
+		{"warn without blockers", projectmodel.BlockedDoneWarn, nil, nil, nil},
+		{"refuse without blockers", projectmodel.BlockedDoneRefuse, nil, nil, nil},
+		{"warn with blockers", projectmodel.BlockedDoneWarn, []int{3, 5}, []int{3, 5}, nil},
+		{"refuse with blockers", projectmodel.BlockedDoneRefuse, []int{3}, nil, db.ErrTaskBlocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.ApplyBlockedDonePolicy(tt.policy, tt.blockers)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBlockingLinks(t *testing.T) {
+	ctx := context.Background()
+	database := setupDB(t)
+
+	projectID, err := database.CreateProject(ctx, "Blocking Links Project", 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = database.DeleteProject(ctx, projectID) })
+
+	var tasks [3]int
+	for i := range tasks {
+		tasks[i], err = database.CreateTask(ctx, projectID, "Task", "", "")
+		require.NoError(t, err)
+	}
+	a, b, c := tasks[0], tasks[1], tasks[2]
+
+	t.Run("Cycle", func(t *testing.T) {
+		_, err := database.AddTaskLink(ctx, projectID, a, projectmodel.LinkBlocks, b)
+		require.NoError(t, err)
+		_, err = database.AddTaskLink(ctx, projectID, b, projectmodel.LinkBlocks, c)
+		require.NoError(t, err)
+
+		_, err = database.AddTaskLink(ctx, projectID, c, projectmodel.LinkBlocks, a)
+		require.ErrorIs(t, err, db.ErrLinkCycle)
+		_, err = database.AddTaskLink(ctx, projectID, a, projectmodel.LinkBlockedBy, c)
+		require.ErrorIs(t, err, db.ErrLinkCycle)
+		_, err = database.AddTaskLink(ctx, projectID, a, projectmodel.LinkBlocks, a)
+		require.ErrorIs(t, err, db.ErrInvalidLink)
+
+		// Связь, не замыкающая цикл, допустима
+		_, err = database.AddTaskLink(ctx, projectID, a, projectmodel.LinkBlocks, c)
+		require.NoError(t, err)
+	})
+
+	t.Run("DonePolicy", func(t *testing.T) {
+		stages, err := database.GetStages(ctx, projectID)
+		require.NoError(t, err)
+		doneStage := 0
+		for _, st := range stages {
+			if st.IsDone {
+				doneStage = st.ID
+			}
+		}
+		require.NotZero(t, doneStage)
+
+		_, err = database.UpdateProjectSettings(ctx, projectID, db.ProjectSettingsPatch{BlockedDonePolicy: db.Set(projectmodel.BlockedDoneRefuse)})
+		require.NoError(t, err)
+		err = database.MoveTaskToStage(ctx, projectID, b, doneStage)
+		require.ErrorIs(t, err, db.ErrTaskBlocked)
+
+		_, err = database.UpdateProjectSettings(ctx, projectID, db.ProjectSettingsPatch{BlockedDonePolicy: db.Set(projectmodel.BlockedDoneWarn)})
+		require.NoError(t, err)
+		require.NoError(t, database.MoveTaskToStage(ctx, projectID, b, doneStage))
+	})
+}
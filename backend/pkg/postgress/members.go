@@ -0,0 +1,292 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/nais2008/hackanet2025/backend/pkg/events"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+var (
+	// ErrNotOwner is returned when a non-owner tries to act on behalf of the project owner
+	ErrNotOwner = errors.New("only the project owner can do this")
+	// ErrOwnerRole is returned when the owner role is changed outside of an ownership transfer
+	ErrOwnerRole = errors.New("project owner can only change through an ownership transfer")
+	// ErrTransferPending is returned when the project already has a pending ownership transfer
+	ErrTransferPending = errors.New("project already has a pending ownership transfer")
+	// ErrTransferResolved is returned when a transfer was already accepted or declined
+	ErrTransferResolved = errors.New("ownership transfer is already resolved")
+	// ErrNotTransferParty is returned when the user is neither the initiator nor the target of a transfer
+	ErrNotTransferParty = errors.New("user is not a party of this ownership transfer")
+)
+
+// addProjectMember inserts or updates a project membership
+func addProjectMember(ctx context.Context, q querier, projectID, userID int, role string) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO project_members (project_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (project_id, user_id) DO UPDATE SET role = EXCLUDED.role`, projectID, userID, role)
+	return err
+}
+
+// GetProjectMembers lists the members of a project in the active workspace
+func (db *DB) GetProjectMembers(ctx context.Context, projectID int) ([]projectmodel.Member, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT m.project_id, m.user_id, u.username, m.role, m.joined_at
+		FROM project_members m
+		JOIN project_project p ON p.id = m.project_id
+		JOIN user_user u ON u.id = m.user_id
+		WHERE m.project_id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2
+		ORDER BY u.username`, projectID, workspaceArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []projectmodel.Member{}
+	for rows.Next() {
+		var m projectmodel.Member
+		if err := rows.Scan(&m.ProjectID, &m.UserID, &m.Username, &m.Role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan project member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// ProjectRole returns the user's role in a project, or pgx.ErrNoRows if the user is not a member
+func (db *DB) ProjectRole(ctx context.Context, projectID, userID int) (string, error) {
+	var role string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT m.role
+		FROM project_members m
+		JOIN project_project p ON p.id = m.project_id
+		WHERE m.project_id = $1 AND m.user_id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3`,
+		projectID, userID, workspaceArg(ctx)).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("user %d is not a member of project %d: %w", userID, projectID, err)
+		}
+		return "", fmt.Errorf("failed to query project role: %w", err)
+	}
+	return role, nil
+}
+
+// SetProjectMember adds a member or changes a member's role; the owner role is managed by ownership transfers
+func (db *DB) SetProjectMember(ctx context.Context, projectID, userID int, role string) error {
+	if role == projectmodel.RoleOwner {
+		return ErrOwnerRole
+	}
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var ownerID int
+		err := tx.QueryRow(ctx, `
+			SELECT user_id FROM project_project
+			WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $2`, projectID, workspaceArg(ctx)).Scan(&ownerID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("project not found: %w", err)
+			}
+			return err
+		}
+		if ownerID == userID {
+			return ErrOwnerRole
+		}
+		if err := addProjectMember(ctx, tx, projectID, userID, role); err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityMemberChanged, map[string]any{
+			"user_id": userID,
+			"role":    role,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set project member: %w", err)
+	}
+	return nil
+}
+
+// RemoveProjectMember removes a member from a project; the owner cannot be removed
+func (db *DB) RemoveProjectMember(ctx context.Context, projectID, userID int) error {
+	var evs []events.Event
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var role string
+		err := tx.QueryRow(ctx, `
+			DELETE FROM project_members m USING project_project p
+			WHERE p.id = m.project_id AND m.project_id = $1 AND m.user_id = $2
+			  AND p.workspace_id IS NOT DISTINCT FROM $3
+			RETURNING m.role`, projectID, userID, workspaceArg(ctx)).Scan(&role)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("user %d is not a member of project %d: %w", userID, projectID, err)
+			}
+			return err
+		}
+		if role == projectmodel.RoleOwner {
+			return ErrOwnerRole
+		}
+		// Бывший участник не может оставаться исполнителем или наблюдателем задач проекта
+		evs, err = removeFromProjectTasks(ctx, tx, projectID, userID)
+		if err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityMemberRemoved, map[string]any{
+			"user_id": userID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove project member: %w", err)
+	}
+	db.publish(ctx, evs)
+	return nil
+}
+
+// RequestOwnershipTransfer starts a transfer of the project from its current owner to another user
+func (db *DB) RequestOwnershipTransfer(ctx context.Context, projectID, fromUserID, toUserID int) (int, error) {
+	var id int
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var ownerID int
+		err := tx.QueryRow(ctx, `
+			SELECT user_id FROM project_project
+			WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $2
+			FOR UPDATE`, projectID, workspaceArg(ctx)).Scan(&ownerID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("project not found: %w", err)
+			}
+			return err
+		}
+		if ownerID != fromUserID {
+			return ErrNotOwner
+		}
+		if toUserID == fromUserID {
+			return fmt.Errorf("user %d already owns project %d", toUserID, projectID)
+		}
+
+		err = tx.QueryRow(ctx, `
+			INSERT INTO project_ownership_transfers (project_id, from_user_id, to_user_id)
+			VALUES ($1, $2, $3)
+			RETURNING id`, projectID, fromUserID, toUserID).Scan(&id)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrTransferPending
+		}
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to request ownership transfer: %w", err)
+	}
+	return id, nil
+}
+
+// GetPendingTransfer returns the pending ownership transfer of a project
+func (db *DB) GetPendingTransfer(ctx context.Context, projectID int) (*projectmodel.OwnershipTransfer, error) {
+	var t projectmodel.OwnershipTransfer
+	err := db.Pool.QueryRow(ctx, `
+		SELECT t.id, t.project_id, t.from_user_id, t.to_user_id, t.status, t.created_at, t.resolved_at
+		FROM project_ownership_transfers t
+		JOIN project_project p ON p.id = t.project_id
+		WHERE t.project_id = $1 AND t.status = $2 AND p.workspace_id IS NOT DISTINCT FROM $3`,
+		projectID, projectmodel.TransferPending, workspaceArg(ctx)).Scan(
+		&t.ID, &t.ProjectID, &t.FromUserID, &t.ToUserID, &t.Status, &t.CreatedAt, &t.ResolvedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no pending ownership transfer: %w", err)
+		}
+		return nil, fmt.Errorf("failed to query ownership transfer: %w", err)
+	}
+	return &t, nil
+}
+
+// AcceptOwnershipTransfer makes the target the new owner and demotes the previous owner to maintainer
+func (db *DB) AcceptOwnershipTransfer(ctx context.Context, transferID, userID int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		t, err := lockTransfer(ctx, tx, transferID)
+		if err != nil {
+			return err
+		}
+		if t.ToUserID != userID {
+			return ErrNotTransferParty
+		}
+
+		tag, err := tx.Exec(ctx, `
+			UPDATE project_project SET user_id = $1
+			WHERE id = $2 AND user_id = $3`, t.ToUserID, t.ProjectID, t.FromUserID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotOwner
+		}
+		if err := addProjectMember(ctx, tx, t.ProjectID, t.ToUserID, projectmodel.RoleOwner); err != nil {
+			return err
+		}
+		if err := addProjectMember(ctx, tx, t.ProjectID, t.FromUserID, projectmodel.RoleMaintainer); err != nil {
+			return err
+		}
+		if err := resolveTransfer(ctx, tx, transferID, projectmodel.TransferAccepted); err != nil {
+			return err
+		}
+		return recordActivity(WithActor(ctx, userID), tx, t.ProjectID, projectmodel.ActivityOwnershipTransferred, map[string]any{
+			"transfer_id":  t.ID,
+			"from_user_id": t.FromUserID,
+			"to_user_id":   t.ToUserID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to accept ownership transfer: %w", err)
+	}
+	return nil
+}
+
+// DeclineOwnershipTransfer rejects a pending transfer; both the target and the initiator may do so
+func (db *DB) DeclineOwnershipTransfer(ctx context.Context, transferID, userID int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		t, err := lockTransfer(ctx, tx, transferID)
+		if err != nil {
+			return err
+		}
+		if t.ToUserID != userID && t.FromUserID != userID {
+			return ErrNotTransferParty
+		}
+		return resolveTransfer(ctx, tx, transferID, projectmodel.TransferDeclined)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to decline ownership transfer: %w", err)
+	}
+	return nil
+}
+
+// lockTransfer loads a pending transfer of the active workspace and locks it for the rest of tx
+func lockTransfer(ctx context.Context, tx pgx.Tx, transferID int) (*projectmodel.OwnershipTransfer, error) {
+	var t projectmodel.OwnershipTransfer
+	err := tx.QueryRow(ctx, `
+		SELECT t.id, t.project_id, t.from_user_id, t.to_user_id, t.status, t.created_at, t.resolved_at
+		FROM project_ownership_transfers t
+		JOIN project_project p ON p.id = t.project_id
+		WHERE t.id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2
+		FOR UPDATE OF t`, transferID, workspaceArg(ctx)).Scan(
+		&t.ID, &t.ProjectID, &t.FromUserID, &t.ToUserID, &t.Status, &t.CreatedAt, &t.ResolvedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("ownership transfer not found: %w", err)
+		}
+		return nil, err
+	}
+	if t.Status != projectmodel.TransferPending {
+		return nil, ErrTransferResolved
+	}
+	return &t, nil
+}
+
+func resolveTransfer(ctx context.Context, tx pgx.Tx, transferID int, status string) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE project_ownership_transfers SET status = $1, resolved_at = NOW()
+		WHERE id = $2`, status, transferID)
+	return err
+}
@@ -0,0 +1,4 @@
+-- Шаблоны проектов: шаблон — это обычный проект с флагом is_template
+ALTER TABLE project_project ADD COLUMN IF NOT EXISTS is_template BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS project_project_is_template_idx ON project_project (is_template) WHERE is_template;
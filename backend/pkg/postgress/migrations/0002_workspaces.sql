@@ -0,0 +1,21 @@
+-- Рабочие пространства: владеют проектами и имеют собственный список участников
+CREATE TABLE IF NOT EXISTS workspace_workspace (
+    id         SERIAL PRIMARY KEY,
+    title      TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id INT NOT NULL REFERENCES workspace_workspace (id) ON DELETE CASCADE,
+    user_id      INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    role         TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'member')),
+    joined_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS workspace_members_user_idx ON workspace_members (user_id);
+
+ALTER TABLE project_project
+    ADD COLUMN IF NOT EXISTS workspace_id INT REFERENCES workspace_workspace (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS project_project_workspace_idx ON project_project (workspace_id);
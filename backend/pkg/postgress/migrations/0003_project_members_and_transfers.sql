@@ -0,0 +1,40 @@
+-- Участники проекта с ролями; владелец дублируется из project_project.user_id
+CREATE TABLE IF NOT EXISTS project_members (
+    project_id INT NOT NULL REFERENCES project_project (id) ON DELETE CASCADE,
+    user_id    INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    role       TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'maintainer', 'member')),
+    joined_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS project_members_user_idx ON project_members (user_id);
+
+INSERT INTO project_members (project_id, user_id, role)
+SELECT id, user_id, 'owner' FROM project_project
+ON CONFLICT (project_id, user_id) DO UPDATE SET role = 'owner';
+
+-- Запросы на передачу владения проектом
+CREATE TABLE IF NOT EXISTS project_ownership_transfers (
+    id           SERIAL PRIMARY KEY,
+    project_id   INT NOT NULL REFERENCES project_project (id) ON DELETE CASCADE,
+    from_user_id INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    to_user_id   INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    status       TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at  TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS project_ownership_transfers_pending_idx
+    ON project_ownership_transfers (project_id) WHERE status = 'pending';
+
+-- История проекта: типизированные записи с автором и данными
+CREATE TABLE IF NOT EXISTS project_activity (
+    id         BIGSERIAL PRIMARY KEY,
+    project_id INT NOT NULL REFERENCES project_project (id) ON DELETE CASCADE,
+    actor_id   INT REFERENCES user_user (id) ON DELETE SET NULL,
+    type       TEXT NOT NULL,
+    payload    JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS project_activity_project_idx ON project_activity (project_id, id DESC);
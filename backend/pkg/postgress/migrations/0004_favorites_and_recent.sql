@@ -0,0 +1,18 @@
+-- Избранные проекты пользователя с явным порядком
+CREATE TABLE IF NOT EXISTS project_favorites (
+    user_id    INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    project_id INT NOT NULL REFERENCES project_project (id) ON DELETE CASCADE,
+    position   INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, project_id)
+);
+
+-- Недавно просмотренные проекты
+CREATE TABLE IF NOT EXISTS project_recent_views (
+    user_id    INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    project_id INT NOT NULL REFERENCES project_project (id) ON DELETE CASCADE,
+    viewed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, project_id)
+);
+
+CREATE INDEX IF NOT EXISTS project_recent_views_user_idx ON project_recent_views (user_id, viewed_at DESC);
@@ -0,0 +1,11 @@
+-- Отметка «с последнего визита» для ленты активности проекта
+CREATE TABLE IF NOT EXISTS project_activity_visits (
+    user_id      INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    project_id   INT NOT NULL REFERENCES project_project (id) ON DELETE CASCADE,
+    last_seen_id BIGINT NOT NULL DEFAULT 0,
+    visited_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, project_id)
+);
+
+CREATE INDEX IF NOT EXISTS project_activity_type_idx ON project_activity (project_id, type, id DESC);
+CREATE INDEX IF NOT EXISTS project_activity_actor_idx ON project_activity (project_id, actor_id, id DESC);
@@ -0,0 +1,5 @@
+-- Время завершения задачи для статистики проекта
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS task_task_project_created_idx ON task_task (project_id, created_at);
+CREATE INDEX IF NOT EXISTS task_task_project_completed_idx ON task_task (project_id, completed_at) WHERE completed_at IS NOT NULL;
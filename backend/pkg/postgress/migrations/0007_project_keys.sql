@@ -0,0 +1,47 @@
+-- Короткие ключи проектов (HACK) и порядковые номера задач внутри проекта (HACK-42)
+ALTER TABLE project_project ADD COLUMN IF NOT EXISTS key TEXT;
+ALTER TABLE project_project ADD COLUMN IF NOT EXISTS task_seq INT NOT NULL DEFAULT 0;
+
+UPDATE project_project SET key = 'P' || id WHERE key IS NULL;
+
+-- Все ключи проекта, включая прежние: по ним продолжают разрешаться старые ссылки
+CREATE TABLE IF NOT EXISTS project_keys (
+    key          TEXT NOT NULL,
+    workspace_id INT REFERENCES workspace_workspace (id) ON DELETE CASCADE,
+    project_id   INT NOT NULL REFERENCES project_project (id) ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS project_keys_scope_idx ON project_keys (COALESCE(workspace_id, 0), key);
+CREATE INDEX IF NOT EXISTS project_keys_project_idx ON project_keys (project_id);
+
+INSERT INTO project_keys (key, workspace_id, project_id)
+SELECT key, workspace_id, id FROM project_project
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS number INT;
+
+UPDATE task_task t SET number = n.number
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY project_id ORDER BY id) AS number FROM task_task) n
+WHERE n.id = t.id AND t.number IS NULL;
+
+UPDATE project_project p SET task_seq = COALESCE((SELECT MAX(number) FROM task_task WHERE project_id = p.id), 0);
+
+CREATE UNIQUE INDEX IF NOT EXISTS task_task_project_number_idx ON task_task (project_id, number);
+
+-- Номер выдаётся под блокировкой строки проекта, поэтому параллельные вставки не получают одинаковых номеров
+CREATE OR REPLACE FUNCTION task_task_assign_number() RETURNS trigger AS $$
+BEGIN
+    IF NEW.number IS NULL THEN
+        UPDATE project_project SET task_seq = task_seq + 1
+        WHERE id = NEW.project_id
+        RETURNING task_seq INTO NEW.number;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_task_assign_number ON task_task;
+CREATE TRIGGER task_task_assign_number
+    BEFORE INSERT ON task_task
+    FOR EACH ROW EXECUTE FUNCTION task_task_assign_number();
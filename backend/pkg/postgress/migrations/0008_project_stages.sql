@@ -0,0 +1,59 @@
+-- Колонки Kanban-доски проекта с порядком и WIP-лимитами
+CREATE TABLE IF NOT EXISTS project_stages (
+    id         SERIAL PRIMARY KEY,
+    project_id INT NOT NULL REFERENCES project_project (id) ON DELETE CASCADE,
+    title      TEXT NOT NULL,
+    position   INT NOT NULL,
+    wip_limit  INT CHECK (wip_limit IS NULL OR wip_limit > 0),
+    is_done    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS project_stages_project_idx ON project_stages (project_id, position);
+
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS stage_id INT REFERENCES project_stages (id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS task_task_stage_idx ON task_task (stage_id);
+
+-- Стандартные колонки для уже существующих проектов
+INSERT INTO project_stages (project_id, title, position, is_done)
+SELECT p.id, s.title, s.position, s.is_done
+FROM project_project p
+CROSS JOIN (VALUES ('К выполнению', 0, FALSE), ('В процессе', 1, FALSE), ('Сделано', 2, TRUE)) AS s (title, position, is_done)
+WHERE NOT EXISTS (SELECT 1 FROM project_stages WHERE project_id = p.id);
+
+UPDATE task_task t SET stage_id = s.id
+FROM project_stages s
+WHERE t.stage_id IS NULL AND s.project_id = t.project_id
+  AND s.position = CASE WHEN t.completed_at IS NULL THEN 0 ELSE 2 END;
+
+-- Задача без колонки попадает в первую подходящую; completed_at следует за признаком is_done колонки
+CREATE OR REPLACE FUNCTION task_task_sync_stage() RETURNS trigger AS $$
+DECLARE
+    done BOOLEAN;
+BEGIN
+    IF NEW.stage_id IS NULL THEN
+        SELECT id INTO NEW.stage_id FROM project_stages
+        WHERE project_id = NEW.project_id AND is_done = (NEW.completed_at IS NOT NULL)
+        ORDER BY position, id LIMIT 1;
+    END IF;
+    IF NEW.stage_id IS NULL THEN
+        SELECT id INTO NEW.stage_id FROM project_stages
+        WHERE project_id = NEW.project_id
+        ORDER BY position, id LIMIT 1;
+    END IF;
+    IF NEW.stage_id IS NOT NULL THEN
+        SELECT is_done INTO done FROM project_stages WHERE id = NEW.stage_id;
+        IF done THEN
+            NEW.completed_at := COALESCE(NEW.completed_at, NOW());
+        ELSE
+            NEW.completed_at := NULL;
+        END IF;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_task_sync_stage ON task_task;
+CREATE TRIGGER task_task_sync_stage
+    BEFORE INSERT OR UPDATE OF stage_id ON task_task
+    FOR EACH ROW EXECUTE FUNCTION task_task_sync_stage();
@@ -0,0 +1,60 @@
+-- Порядок задач внутри колонки: лексикографический ранг, см. пакет rank
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS rank TEXT COLLATE "C";
+
+UPDATE task_task t SET rank = r.rank
+FROM (SELECT id, lpad(ROW_NUMBER() OVER (PARTITION BY stage_id ORDER BY id)::text, 10, '0') || 'i' AS rank
+      FROM task_task) r
+WHERE r.id = t.id AND t.rank IS NULL;
+
+-- Отложенная проверка нужна перебалансировке, которая переписывает ранги всей колонки
+ALTER TABLE task_task ADD CONSTRAINT task_task_stage_rank_key UNIQUE (stage_id, rank) DEFERRABLE INITIALLY IMMEDIATE;
+
+-- Ранг сразу после a; повторяет rank.After
+CREATE OR REPLACE FUNCTION task_rank_after(a TEXT) RETURNS TEXT AS $$
+DECLARE
+    digits CONSTANT TEXT := '0123456789abcdefghijklmnopqrstuvwxyz';
+    prefix TEXT := substring(COALESCE(a, '') FROM '^z*');
+    lo INT := GREATEST(strpos(digits, substr(COALESCE(a, ''), length(prefix) + 1, 1)), 1) - 1;
+BEGIN
+    RETURN prefix || substr(digits, (lo + 36) / 2 + 1, 1);
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Новая задача и задача, перенесённая в другую колонку без явного ранга, встают в конец колонки
+CREATE OR REPLACE FUNCTION task_task_sync_stage() RETURNS trigger AS $$
+DECLARE
+    done BOOLEAN;
+    append BOOLEAN;
+BEGIN
+    IF NEW.stage_id IS NULL THEN
+        SELECT id INTO NEW.stage_id FROM project_stages
+        WHERE project_id = NEW.project_id AND is_done = (NEW.completed_at IS NOT NULL)
+        ORDER BY position, id LIMIT 1;
+    END IF;
+    IF NEW.stage_id IS NULL THEN
+        SELECT id INTO NEW.stage_id FROM project_stages
+        WHERE project_id = NEW.project_id
+        ORDER BY position, id LIMIT 1;
+    END IF;
+    IF NEW.stage_id IS NOT NULL THEN
+        SELECT is_done INTO done FROM project_stages WHERE id = NEW.stage_id;
+        IF done THEN
+            NEW.completed_at := COALESCE(NEW.completed_at, NOW());
+        ELSE
+            NEW.completed_at := NULL;
+        END IF;
+    END IF;
+
+    IF TG_OP = 'INSERT' THEN
+        append := NEW.rank IS NULL;
+    ELSE
+        append := NEW.stage_id IS DISTINCT FROM OLD.stage_id AND NEW.rank IS NOT DISTINCT FROM OLD.rank;
+    END IF;
+    IF append THEN
+        -- Блокировка колонки упорядочивает параллельные вставки и переносы в неё
+        PERFORM 1 FROM project_stages WHERE id = NEW.stage_id FOR UPDATE;
+        SELECT task_rank_after(MAX(rank)) INTO NEW.rank FROM task_task WHERE stage_id IS NOT DISTINCT FROM NEW.stage_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
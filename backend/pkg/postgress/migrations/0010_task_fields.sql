@@ -0,0 +1,10 @@
+-- Статус, приоритет, срок и дата начала задачи
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT '';
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS priority TEXT NOT NULL DEFAULT 'medium'
+    CHECK (priority IN ('low', 'medium', 'high', 'critical'));
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS deadline TIMESTAMPTZ;
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS start_date TIMESTAMPTZ;
+
+ALTER TABLE task_task ADD CONSTRAINT task_task_dates_check CHECK (start_date IS NULL OR deadline IS NULL OR start_date <= deadline);
+
+CREATE INDEX IF NOT EXISTS task_task_project_deadline_idx ON task_task (project_id, deadline) WHERE completed_at IS NULL;
@@ -0,0 +1,18 @@
+-- Каталог меток проекта и их связь с задачами
+CREATE TABLE IF NOT EXISTS project_labels (
+    id SERIAL PRIMARY KEY,
+    project_id INT NOT NULL REFERENCES project_project(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (name <> ''),
+    color TEXT NOT NULL CHECK (color ~ '^#[0-9a-f]{6}$'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS project_labels_name_key ON project_labels (project_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS task_labels (
+    task_id INT NOT NULL REFERENCES task_task(id) ON DELETE CASCADE,
+    label_id INT NOT NULL REFERENCES project_labels(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_labels_label_idx ON task_labels (label_id);
@@ -0,0 +1,19 @@
+-- Исполнители и наблюдатели задач; ими могут быть только участники проекта
+CREATE TABLE IF NOT EXISTS task_assignees (
+    task_id     INT NOT NULL REFERENCES task_task (id) ON DELETE CASCADE,
+    user_id     INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    assigned_by INT REFERENCES user_user (id) ON DELETE SET NULL,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_assignees_user_idx ON task_assignees (user_id);
+
+CREATE TABLE IF NOT EXISTS task_watchers (
+    task_id    INT NOT NULL REFERENCES task_task (id) ON DELETE CASCADE,
+    user_id    INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_watchers_user_idx ON task_watchers (user_id);
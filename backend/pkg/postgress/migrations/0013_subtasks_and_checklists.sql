@@ -0,0 +1,23 @@
+-- Настройка проекта: максимальная вложенность подзадач
+ALTER TABLE project_project ADD COLUMN IF NOT EXISTS max_subtask_depth INT NOT NULL DEFAULT 3
+    CHECK (max_subtask_depth BETWEEN 1 AND 10);
+
+-- Подзадачи: родитель в том же проекте; каскадное удаление, перенос на уровень выше делает приложение
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES task_task (id) ON DELETE CASCADE;
+ALTER TABLE task_task ADD CONSTRAINT task_task_parent_check CHECK (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS task_task_parent_idx ON task_task (parent_id) WHERE parent_id IS NOT NULL;
+
+-- Пункты чек-листа задачи
+CREATE TABLE IF NOT EXISTS task_checklist_items (
+    id          SERIAL PRIMARY KEY,
+    task_id     INT NOT NULL REFERENCES task_task (id) ON DELETE CASCADE,
+    text        TEXT NOT NULL CHECK (text <> ''),
+    done        BOOLEAN NOT NULL DEFAULT FALSE,
+    position    INT NOT NULL,
+    assignee_id INT REFERENCES user_user (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    done_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS task_checklist_items_task_idx ON task_checklist_items (task_id, position);
@@ -0,0 +1,18 @@
+-- Связи между задачами, в том числе из разных проектов пространства.
+-- blocks и duplicates направлены от source к target; relates хранится с source_id < target_id
+CREATE TABLE IF NOT EXISTS task_links (
+    id         SERIAL PRIMARY KEY,
+    source_id  INT NOT NULL REFERENCES task_task (id) ON DELETE CASCADE,
+    target_id  INT NOT NULL REFERENCES task_task (id) ON DELETE CASCADE,
+    kind       TEXT NOT NULL CHECK (kind IN ('blocks', 'relates', 'duplicates')),
+    created_by INT REFERENCES user_user (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (source_id <> target_id),
+    UNIQUE (source_id, target_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS task_links_target_idx ON task_links (target_id, kind);
+
+-- Что делать при переносе в колонку «готово» задачи с незавершёнными блокерами
+ALTER TABLE project_project ADD COLUMN IF NOT EXISTS blocked_done_policy TEXT NOT NULL DEFAULT 'warn'
+    CHECK (blocked_done_policy IN ('warn', 'refuse'));
@@ -0,0 +1,54 @@
+-- Комментарии к задачам: исходный Markdown и отрендеренный очищенный HTML
+CREATE TABLE IF NOT EXISTS task_comments (
+    id         SERIAL PRIMARY KEY,
+    task_id    INT NOT NULL REFERENCES task_task (id) ON DELETE CASCADE,
+    author_id  INT REFERENCES user_user (id) ON DELETE SET NULL,
+    body       TEXT NOT NULL CHECK (body <> ''),
+    body_html  TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    edited_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS task_comments_task_idx ON task_comments (task_id, created_at);
+
+-- Прежние версии отредактированных комментариев
+CREATE TABLE IF NOT EXISTS task_comment_revisions (
+    id         SERIAL PRIMARY KEY,
+    comment_id INT NOT NULL REFERENCES task_comments (id) ON DELETE CASCADE,
+    body       TEXT NOT NULL,
+    edited_by  INT REFERENCES user_user (id) ON DELETE SET NULL,
+    edited_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_comment_revisions_comment_idx ON task_comment_revisions (comment_id, edited_at);
+
+CREATE TABLE IF NOT EXISTS task_comment_reactions (
+    comment_id INT NOT NULL REFERENCES task_comments (id) ON DELETE CASCADE,
+    user_id    INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    emoji      TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id, emoji)
+);
+
+-- Упомянутые в комментарии участники проекта
+CREATE TABLE IF NOT EXISTS task_comment_mentions (
+    comment_id INT NOT NULL REFERENCES task_comments (id) ON DELETE CASCADE,
+    user_id    INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+-- Уведомления пользователей
+CREATE TABLE IF NOT EXISTS notifications (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    type       TEXT NOT NULL,
+    project_id INT REFERENCES project_project (id) ON DELETE CASCADE,
+    task_id    INT REFERENCES task_task (id) ON DELETE CASCADE,
+    comment_id INT REFERENCES task_comments (id) ON DELETE CASCADE,
+    actor_id   INT REFERENCES user_user (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    read_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_idx ON notifications (user_id, id DESC);
+CREATE INDEX IF NOT EXISTS notifications_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
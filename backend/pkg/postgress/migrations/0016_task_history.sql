@@ -0,0 +1,101 @@
+-- Пополевая история изменений задач. Значения хранятся текстом (даты — в ISO 8601);
+-- автор изменения берётся из настройки транзакции app.actor_id, которую выставляет приложение
+CREATE TABLE IF NOT EXISTS task_history (
+    id         BIGSERIAL PRIMARY KEY,
+    task_id    INT NOT NULL REFERENCES task_task (id) ON DELETE CASCADE,
+    field      TEXT NOT NULL,
+    old_value  TEXT,
+    new_value  TEXT,
+    actor_id   INT REFERENCES user_user (id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_history_task_idx ON task_history (task_id, id DESC);
+
+CREATE OR REPLACE FUNCTION task_history_actor() RETURNS INT AS $$
+    SELECT NULLIF(current_setting('app.actor_id', TRUE), '')::INT;
+$$ LANGUAGE sql STABLE;
+
+-- Колонка хранится названием, остальные поля — как есть; ранг не отслеживается
+CREATE OR REPLACE FUNCTION task_task_log_history() RETURNS trigger AS $$
+DECLARE
+    old_row JSONB;
+    new_row JSONB;
+    f TEXT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_history (task_id, field, new_value, actor_id)
+        VALUES (NEW.id, 'created', NEW.title, task_history_actor());
+        RETURN NULL;
+    END IF;
+
+    old_row := to_jsonb(OLD);
+    new_row := to_jsonb(NEW);
+    FOREACH f IN ARRAY ARRAY['title', 'description', 'full_description', 'status', 'priority',
+                             'start_date', 'deadline', 'parent_id', 'completed_at'] LOOP
+        IF old_row -> f IS DISTINCT FROM new_row -> f THEN
+            INSERT INTO task_history (task_id, field, old_value, new_value, actor_id)
+            VALUES (NEW.id, f, old_row ->> f, new_row ->> f, task_history_actor());
+        END IF;
+    END LOOP;
+    IF NEW.stage_id IS DISTINCT FROM OLD.stage_id THEN
+        INSERT INTO task_history (task_id, field, old_value, new_value, actor_id)
+        VALUES (NEW.id, 'stage',
+                (SELECT title FROM project_stages WHERE id = OLD.stage_id),
+                (SELECT title FROM project_stages WHERE id = NEW.stage_id),
+                task_history_actor());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_task_log_history ON task_task;
+CREATE TRIGGER task_task_log_history
+    AFTER INSERT OR UPDATE ON task_task
+    FOR EACH ROW EXECUTE FUNCTION task_task_log_history();
+
+-- Исполнители и метки записываются по имени; при удалении самой задачи записи не нужны
+CREATE OR REPLACE FUNCTION task_relation_log_history() RETURNS trigger AS $$
+DECLARE
+    changed_task INT;
+    item_name TEXT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        changed_task := NEW.task_id;
+    ELSE
+        changed_task := OLD.task_id;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM task_task WHERE id = changed_task) THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_TABLE_NAME = 'task_assignees' THEN
+        SELECT u.username INTO item_name FROM user_user u
+        WHERE u.id = CASE WHEN TG_OP = 'INSERT' THEN NEW.user_id ELSE OLD.user_id END;
+    ELSE
+        SELECT l.name INTO item_name FROM project_labels l
+        WHERE l.id = CASE WHEN TG_OP = 'INSERT' THEN NEW.label_id ELSE OLD.label_id END;
+    END IF;
+    -- Метка или пользователь удалены целиком — это не изменение задачи
+    IF item_name IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO task_history (task_id, field, old_value, new_value, actor_id)
+    VALUES (changed_task, CASE WHEN TG_TABLE_NAME = 'task_assignees' THEN 'assignee' ELSE 'label' END,
+            CASE WHEN TG_OP = 'DELETE' THEN item_name END,
+            CASE WHEN TG_OP = 'INSERT' THEN item_name END,
+            task_history_actor());
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_assignees_log_history ON task_assignees;
+CREATE TRIGGER task_assignees_log_history
+    AFTER INSERT OR DELETE ON task_assignees
+    FOR EACH ROW EXECUTE FUNCTION task_relation_log_history();
+
+DROP TRIGGER IF EXISTS task_labels_log_history ON task_labels;
+CREATE TRIGGER task_labels_log_history
+    AFTER INSERT OR DELETE ON task_labels
+    FOR EACH ROW EXECUTE FUNCTION task_relation_log_history();
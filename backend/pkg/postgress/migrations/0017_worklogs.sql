@@ -0,0 +1,21 @@
+-- Учёт времени: записи о работе над задачами и запущенные таймеры
+CREATE TABLE IF NOT EXISTS task_worklogs (
+    id         SERIAL PRIMARY KEY,
+    task_id    INT NOT NULL REFERENCES task_task (id) ON DELETE CASCADE,
+    user_id    INT NOT NULL REFERENCES user_user (id) ON DELETE CASCADE,
+    minutes    INT NOT NULL CHECK (minutes > 0),
+    work_date  DATE NOT NULL,
+    note       TEXT NOT NULL DEFAULT '',
+    from_timer BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_worklogs_task_idx ON task_worklogs (task_id, work_date);
+CREATE INDEX IF NOT EXISTS task_worklogs_user_idx ON task_worklogs (user_id, work_date);
+
+-- Первичный ключ по пользователю: у каждого не больше одного запущенного таймера
+CREATE TABLE IF NOT EXISTS task_timers (
+    user_id    INT PRIMARY KEY REFERENCES user_user (id) ON DELETE CASCADE,
+    task_id    INT NOT NULL REFERENCES task_task (id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,65 @@
+-- Спринты проекта. Активным одновременно может быть только один спринт
+CREATE TABLE IF NOT EXISTS project_sprints (
+    id           SERIAL PRIMARY KEY,
+    project_id   INT NOT NULL REFERENCES project_project (id) ON DELETE CASCADE,
+    name         TEXT NOT NULL,
+    goal         TEXT NOT NULL DEFAULT '',
+    start_date   DATE NOT NULL,
+    end_date     DATE NOT NULL CHECK (end_date >= start_date),
+    state        TEXT NOT NULL DEFAULT 'planned' CHECK (state IN ('planned', 'active', 'completed')),
+    started_at   TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS project_sprints_project_idx ON project_sprints (project_id, start_date);
+CREATE UNIQUE INDEX IF NOT EXISTS project_sprints_active_idx ON project_sprints (project_id) WHERE state = 'active';
+
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS story_points INT CHECK (story_points >= 0);
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS sprint_id INT REFERENCES project_sprints (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS task_task_sprint_idx ON task_task (sprint_id);
+CREATE INDEX IF NOT EXISTS task_history_sprint_idx ON task_history (new_value) WHERE field = 'sprint_id';
+
+-- Оценка и спринт задачи попадают в историю: по ней строятся диаграмма сгорания и скорость команды
+CREATE OR REPLACE FUNCTION task_task_log_history() RETURNS trigger AS $$
+DECLARE
+    old_row JSONB;
+    new_row JSONB;
+    f TEXT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_history (task_id, field, new_value, actor_id)
+        VALUES (NEW.id, 'created', NEW.title, task_history_actor());
+        -- Значения при создании нужны, чтобы восстановить состояние задачи на любой момент
+        IF NEW.story_points IS NOT NULL THEN
+            INSERT INTO task_history (task_id, field, new_value, actor_id)
+            VALUES (NEW.id, 'story_points', NEW.story_points::TEXT, task_history_actor());
+        END IF;
+        IF NEW.sprint_id IS NOT NULL THEN
+            INSERT INTO task_history (task_id, field, new_value, actor_id)
+            VALUES (NEW.id, 'sprint_id', NEW.sprint_id::TEXT, task_history_actor());
+        END IF;
+        RETURN NULL;
+    END IF;
+
+    old_row := to_jsonb(OLD);
+    new_row := to_jsonb(NEW);
+    FOREACH f IN ARRAY ARRAY['title', 'description', 'full_description', 'status', 'priority',
+                             'start_date', 'deadline', 'parent_id', 'completed_at',
+                             'story_points', 'sprint_id'] LOOP
+        IF old_row -> f IS DISTINCT FROM new_row -> f THEN
+            INSERT INTO task_history (task_id, field, old_value, new_value, actor_id)
+            VALUES (NEW.id, f, old_row ->> f, new_row ->> f, task_history_actor());
+        END IF;
+    END LOOP;
+    IF NEW.stage_id IS DISTINCT FROM OLD.stage_id THEN
+        INSERT INTO task_history (task_id, field, old_value, new_value, actor_id)
+        VALUES (NEW.id, 'stage',
+                (SELECT title FROM project_stages WHERE id = OLD.stage_id),
+                (SELECT title FROM project_stages WHERE id = NEW.stage_id),
+                task_history_actor());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
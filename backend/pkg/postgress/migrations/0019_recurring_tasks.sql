@@ -0,0 +1,34 @@
+-- Повторяющиеся задачи: правило повторения привязано к задаче-шаблону, по нему
+-- планировщик создаёт обычные задачи. next_at — ближайшее ещё не обработанное
+-- вхождение правила, NULL — правило исчерпано
+CREATE TABLE IF NOT EXISTS task_recurrences (
+    id         SERIAL PRIMARY KEY,
+    task_id    INT NOT NULL UNIQUE REFERENCES task_task (id) ON DELETE CASCADE,
+    rule       TEXT NOT NULL,
+    starts_at  TIMESTAMPTZ NOT NULL,
+    timezone   TEXT NOT NULL DEFAULT 'UTC',
+    next_at    TIMESTAMPTZ,
+    created_by INT REFERENCES user_user (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_recurrences_next_idx ON task_recurrences (next_at) WHERE next_at IS NOT NULL;
+
+-- Пропуск (shift_to IS NULL) или перенос отдельного вхождения
+CREATE TABLE IF NOT EXISTS task_recurrence_exceptions (
+    id            SERIAL PRIMARY KEY,
+    recurrence_id INT NOT NULL REFERENCES task_recurrences (id) ON DELETE CASCADE,
+    occurrence_at TIMESTAMPTZ NOT NULL,
+    shift_to      TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (recurrence_id, occurrence_at)
+);
+
+CREATE INDEX IF NOT EXISTS task_recurrence_exceptions_shift_idx ON task_recurrence_exceptions (shift_to) WHERE shift_to IS NOT NULL;
+
+-- Созданная по расписанию задача помнит шаблон и вхождение; уникальность пары
+-- гарантирует, что вхождение превращается в задачу ровно один раз
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS template_id INT REFERENCES task_task (id) ON DELETE SET NULL;
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS occurrence_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS task_task_occurrence_key ON task_task (template_id, occurrence_at);
@@ -0,0 +1,4 @@
+-- Архивированные задачи скрыты из списка задач проекта, но сохраняют все свои данные
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS task_task_archived_idx ON task_task (project_id) WHERE archived_at IS NOT NULL;
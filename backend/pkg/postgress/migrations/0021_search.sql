@@ -0,0 +1,27 @@
+-- Полнотекстовый поиск. Тексты индексируются в двух конфигурациях: russian приводит русские
+-- слова к основе, а english оставляет их как есть, поэтому префикс набираемого слова находит
+-- и основу, и словоформу. Названия весят больше описаний и комментариев
+ALTER TABLE task_task ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian'::regconfig, COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english'::regconfig, COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('russian'::regconfig, COALESCE(description, '')), 'B') ||
+    setweight(to_tsvector('english'::regconfig, COALESCE(description, '')), 'B') ||
+    setweight(to_tsvector('russian'::regconfig, COALESCE(full_description, '')), 'C') ||
+    setweight(to_tsvector('english'::regconfig, COALESCE(full_description, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS task_task_search_idx ON task_task USING GIN (search_vector);
+
+ALTER TABLE task_comments ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian'::regconfig, body), 'B') ||
+    setweight(to_tsvector('english'::regconfig, body), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS task_comments_search_idx ON task_comments USING GIN (search_vector);
+
+ALTER TABLE project_project ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian'::regconfig, COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english'::regconfig, COALESCE(title, '')), 'A')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS project_project_search_idx ON project_project USING GIN (search_vector);
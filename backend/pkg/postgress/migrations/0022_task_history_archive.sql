@@ -0,0 +1,44 @@
+-- Архивация и возврат задачи из архива попадают в её историю.
+-- Функция повторяет версию из 0018_sprints.sql (включая запись спринта и очков при создании задачи),
+-- новое здесь только поле archived_at в списке отслеживаемых.
+CREATE OR REPLACE FUNCTION task_task_log_history() RETURNS trigger AS $$
+DECLARE
+    old_row JSONB;
+    new_row JSONB;
+    f TEXT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_history (task_id, field, new_value, actor_id)
+        VALUES (NEW.id, 'created', NEW.title, task_history_actor());
+        -- Значения при создании нужны, чтобы восстановить состояние задачи на любой момент
+        IF NEW.story_points IS NOT NULL THEN
+            INSERT INTO task_history (task_id, field, new_value, actor_id)
+            VALUES (NEW.id, 'story_points', NEW.story_points::TEXT, task_history_actor());
+        END IF;
+        IF NEW.sprint_id IS NOT NULL THEN
+            INSERT INTO task_history (task_id, field, new_value, actor_id)
+            VALUES (NEW.id, 'sprint_id', NEW.sprint_id::TEXT, task_history_actor());
+        END IF;
+        RETURN NULL;
+    END IF;
+
+    old_row := to_jsonb(OLD);
+    new_row := to_jsonb(NEW);
+    FOREACH f IN ARRAY ARRAY['title', 'description', 'full_description', 'status', 'priority',
+                             'start_date', 'deadline', 'parent_id', 'completed_at',
+                             'story_points', 'sprint_id', 'archived_at'] LOOP
+        IF old_row -> f IS DISTINCT FROM new_row -> f THEN
+            INSERT INTO task_history (task_id, field, old_value, new_value, actor_id)
+            VALUES (NEW.id, f, old_row ->> f, new_row ->> f, task_history_actor());
+        END IF;
+    END LOOP;
+    IF NEW.stage_id IS DISTINCT FROM OLD.stage_id THEN
+        INSERT INTO task_history (task_id, field, old_value, new_value, actor_id)
+        VALUES (NEW.id, 'stage',
+                (SELECT title FROM project_stages WHERE id = OLD.stage_id),
+                (SELECT title FROM project_stages WHERE id = NEW.stage_id),
+                task_history_actor());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
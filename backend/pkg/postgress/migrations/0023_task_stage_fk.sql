@@ -0,0 +1,6 @@
+-- С ON DELETE RESTRICT удаление проекта зависело от порядка каскадов задач и колонок.
+-- NO ACTION проверяется в конце оператора, когда каскад уже удалил задачи; удаление непустой колонки
+-- в приложении по-прежнему защищает DeleteStage.
+ALTER TABLE task_task DROP CONSTRAINT IF EXISTS task_task_stage_id_fkey;
+ALTER TABLE task_task ADD CONSTRAINT task_task_stage_id_fkey
+    FOREIGN KEY (stage_id) REFERENCES project_stages (id);
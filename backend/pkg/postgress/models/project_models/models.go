@@ -1,13 +1,17 @@
 package projectmodels
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Project struct {
 	ID          string    `json:"id"`
 	Title       string    `json:"title"`
+	Key         string    `json:"key"`
 	Description string    `json:"description"`
 	Logo        string    `json:"logo"`
-	Group       string    `json:"Group"`
+	WorkspaceID *int      `json:"workspace_id"`
 	Files       string    `json:"link"`
 	UserID      string    `json:"user_id"`
 	CreatedAt   time.Time `json:"createdAt"`
@@ -16,13 +20,619 @@ type Project struct {
 }
 
 type Task struct {
-	ID               string `json:"id"`
-	ProjectID        int    `json:"project_id"`
-	Title            string `json:"title"`
-	Description      string `json:"description"`
-	Full_description string `json:"Full_description"`
-	CreatedAt        string `json:"created_at"`
-	UpdatedAt        string `json:"updated_at"`
-	Files            string `json:"files"`
-	Tags             string `json:"Tags"`
+	ID               string     `json:"id"`
+	ProjectID        int        `json:"project_id"`
+	Number           int        `json:"number"`
+	Key              string     `json:"key"`
+	StageID          *int       `json:"stage_id"`
+	Stage            string     `json:"stage"`
+	ParentID         *int       `json:"parent_id"`
+	Rank             string     `json:"rank"`
+	Title            string     `json:"title"`
+	Description      string     `json:"description"`
+	Full_description string     `json:"Full_description"`
+	Status           string     `json:"status"`
+	Priority         string     `json:"priority"`
+	StartDate        *time.Time `json:"start_date"`
+	Deadline         *time.Time `json:"deadline"`
+	Overdue          bool       `json:"overdue"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	CompletedAt      *time.Time `json:"completed_at"`
+	ArchivedAt       *time.Time `json:"archived_at"`
+	StoryPoints      *int       `json:"story_points"`
+	SprintID         *int       `json:"sprint_id"`
+	// TemplateID and OccurrenceAt are set on tasks created from a recurring template task
+	TemplateID    *int         `json:"template_id"`
+	OccurrenceAt  *time.Time   `json:"occurrence_at"`
+	Files         string       `json:"files"`
+	Labels        []Label      `json:"labels"`
+	Assignees     []UserRef    `json:"assignees"`
+	Watchers      []UserRef    `json:"watchers"`
+	Progress      TaskProgress `json:"progress"`
+	LoggedMinutes int          `json:"logged_minutes"`
+	// Blockers lists the tasks blocking this one that are not completed yet
+	Blockers []TaskRef `json:"blockers"`
+	// Warnings explains why a change was allowed despite a problem, e.g. a move to done with open blockers
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// TaskProgress counts the direct subtasks and checklist items of a task and how many are done.
+// Percent is nil when the task has neither.
+type TaskProgress struct {
+	Subtasks      int  `json:"subtasks"`
+	SubtasksDone  int  `json:"subtasks_done"`
+	Checklist     int  `json:"checklist"`
+	ChecklistDone int  `json:"checklist_done"`
+	Percent       *int `json:"percent"`
+}
+
+type ChecklistItem struct {
+	ID         int        `json:"id"`
+	TaskID     int        `json:"task_id"`
+	Text       string     `json:"text"`
+	Done       bool       `json:"done"`
+	Position   int        `json:"position"`
+	AssigneeID *int       `json:"assignee_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DoneAt     *time.Time `json:"done_at"`
+}
+
+// TaskRef is a task as shown inside other objects.
+// A task of a project the viewer is not a member of is Hidden, without its key and title.
+type TaskRef struct {
+	ID        int    `json:"id"`
+	ProjectID int    `json:"project_id"`
+	Key       string `json:"key"`
+	Title     string `json:"title"`
+	Done      bool   `json:"done"`
+	Hidden    bool   `json:"hidden,omitempty"`
+}
+
+// Типы связей между задачами; blocked_by и duplicated_by — обратные стороны blocks и duplicates
+const (
+	LinkBlocks       = "blocks"
+	LinkBlockedBy    = "blocked_by"
+	LinkRelates      = "relates"
+	LinkDuplicates   = "duplicates"
+	LinkDuplicatedBy = "duplicated_by"
+)
+
+// TaskLink is a link as seen from one of its tasks: Type is read as "this task <Type> Task"
+type TaskLink struct {
+	ID        int       `json:"id"`
+	Type      string    `json:"type"`
+	Task      TaskRef   `json:"task"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaskComment is a comment on a task. HTML is the sanitized rendering of the Markdown Body.
+type TaskComment struct {
+	ID        int        `json:"id"`
+	TaskID    int        `json:"task_id"`
+	Author    *UserRef   `json:"author"`
+	Body      string     `json:"body"`
+	HTML      string     `json:"html"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at"`
+	Mentions  []UserRef  `json:"mentions"`
+	Reactions []Reaction `json:"reactions"`
+}
+
+type Reaction struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	UserIDs []int  `json:"user_ids"`
+}
+
+// CommentRevision is a previous version of an edited comment
+type CommentRevision struct {
+	Body     string    `json:"body"`
+	EditedBy *int      `json:"edited_by"`
+	EditedAt time.Time `json:"edited_at"`
+}
+
+// Типы уведомлений
+const (
+	NotificationMentioned = "comment.mentioned"
+)
+
+type Notification struct {
+	ID        int64      `json:"id"`
+	Type      string     `json:"type"`
+	ProjectID *int       `json:"project_id"`
+	TaskID    *int       `json:"task_id"`
+	CommentID *int       `json:"comment_id"`
+	ActorID   *int       `json:"actor_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at"`
+}
+
+// TaskChange is one field change in a task's history. Values are text, dates in RFC 3339;
+// Text is a human-readable summary filled in by the API.
+type TaskChange struct {
+	ID        int64     `json:"id"`
+	TaskID    int       `json:"task_id"`
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"old_value"`
+	NewValue  *string   `json:"new_value"`
+	Actor     *UserRef  `json:"actor"`
+	ChangedAt time.Time `json:"changed_at"`
+	Text      string    `json:"text"`
+}
+
+// Worklog is time a user spent on a task on a date (YYYY-MM-DD)
+type Worklog struct {
+	ID        int       `json:"id"`
+	TaskID    int       `json:"task_id"`
+	User      UserRef   `json:"user"`
+	Minutes   int       `json:"minutes"`
+	Date      string    `json:"date"`
+	Note      string    `json:"note"`
+	FromTimer bool      `json:"from_timer"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Timer is the running timer of a user
+type Timer struct {
+	Task      TaskRef   `json:"task"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Recurrence repeats a template task by an RRULE. Occurrences are in Timezone with the
+// wall-clock time of StartsAt; NextAt is nil once the rule has no more occurrences.
+type Recurrence struct {
+	ID         int                   `json:"id"`
+	TaskID     int                   `json:"task_id"`
+	Rule       string                `json:"rule"`
+	StartsAt   time.Time             `json:"starts_at"`
+	Timezone   string                `json:"timezone"`
+	NextAt     *time.Time            `json:"next_at"`
+	CreatedAt  time.Time             `json:"created_at"`
+	Exceptions []RecurrenceException `json:"exceptions"`
+}
+
+// RecurrenceException skips an occurrence, or shifts it to ShiftTo
+type RecurrenceException struct {
+	ID           int        `json:"id"`
+	OccurrenceAt time.Time  `json:"occurrence_at"`
+	ShiftTo      *time.Time `json:"shift_to"`
+}
+
+// Occurrence is an upcoming occurrence of a recurrence. RunAt is when its task is created,
+// nil when the occurrence is skipped.
+type Occurrence struct {
+	At          time.Time  `json:"at"`
+	RunAt       *time.Time `json:"run_at"`
+	ExceptionID *int       `json:"exception_id"`
+}
+
+// Состояния спринта
+const (
+	SprintPlanned   = "planned"
+	SprintActive    = "active"
+	SprintCompleted = "completed"
+)
+
+// Sprint is a time box of a project. Dates are YYYY-MM-DD; the task and point counts are current.
+type Sprint struct {
+	ID          int        `json:"id"`
+	ProjectID   int        `json:"project_id"`
+	Name        string     `json:"name"`
+	Goal        string     `json:"goal"`
+	StartDate   string     `json:"start_date"`
+	EndDate     string     `json:"end_date"`
+	State       string     `json:"state"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Tasks       int        `json:"tasks"`
+	TasksDone   int        `json:"tasks_done"`
+	Points      int        `json:"points"`
+	PointsDone  int        `json:"points_done"`
+}
+
+// SprintCompletion is the outcome of completing a sprint
+type SprintCompletion struct {
+	Sprint          Sprint `json:"sprint"`
+	CompletedPoints int    `json:"completed_points"`
+	// CarriedOver lists the unfinished tasks moved to CarriedTo, or to the backlog when it is nil
+	CarriedOver []int `json:"carried_over"`
+	CarriedTo   *int  `json:"carried_to"`
+}
+
+// BurndownDay is the state of a sprint at the end of a day. Scope and Remaining are nil for days ahead.
+type BurndownDay struct {
+	Date      string  `json:"date"`
+	Scope     *int    `json:"scope"`
+	Remaining *int    `json:"remaining"`
+	Ideal     float64 `json:"ideal"`
+}
+
+// Burndown tracks the story points left in a sprint day by day
+type Burndown struct {
+	SprintID int `json:"sprint_id"`
+	// Committed is the points in the sprint when it was started, or now for a planned sprint
+	Committed int           `json:"committed"`
+	Days      []BurndownDay `json:"days"`
+}
+
+// SprintVelocity is the points a completed sprint committed to and delivered
+type SprintVelocity struct {
+	SprintID  int       `json:"sprint_id"`
+	Name      string    `json:"name"`
+	Committed int       `json:"committed"`
+	Completed int       `json:"completed"`
+	EndedAt   time.Time `json:"ended_at"`
+}
+
+// Velocity lists the latest completed sprints, oldest first, with their average delivered points
+type Velocity struct {
+	Sprints []SprintVelocity `json:"sprints"`
+	Average float64          `json:"average"`
+}
+
+// TimeReportRow is logged time summed over one group of a time report.
+// Only the fields of the requested grouping are set.
+type TimeReportRow struct {
+	UserID       *int   `json:"user_id,omitempty"`
+	Username     string `json:"username,omitempty"`
+	ProjectID    *int   `json:"project_id,omitempty"`
+	ProjectTitle string `json:"project_title,omitempty"`
+	TaskID       *int   `json:"task_id,omitempty"`
+	TaskKey      string `json:"task_key,omitempty"`
+	TaskTitle    string `json:"task_title,omitempty"`
+	// Week is the Monday of the week, YYYY-MM-DD
+	Week    string `json:"week,omitempty"`
+	Minutes int    `json:"minutes"`
+}
+
+// UserRef is a user as shown inside other objects
+type UserRef struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+// Приоритеты задач по возрастанию
+const (
+	PriorityLow      = "low"
+	PriorityMedium   = "medium"
+	PriorityHigh     = "high"
+	PriorityCritical = "critical"
+)
+
+// Priorities lists the task priorities from lowest to highest
+var Priorities = []string{PriorityLow, PriorityMedium, PriorityHigh, PriorityCritical}
+
+// ProjectSettings are the per-project options that change how tasks behave
+type ProjectSettings struct {
+	MaxSubtaskDepth int `json:"max_subtask_depth"`
+	// BlockedDonePolicy decides whether a task with open blockers may move to a done stage
+	BlockedDonePolicy string `json:"blocked_done_policy"`
+}
+
+// Реакция на перенос в колонку «готово» задачи с незавершёнными блокерами
+const (
+	BlockedDoneWarn   = "warn"
+	BlockedDoneRefuse = "refuse"
+)
+
+type Stage struct {
+	ID        int    `json:"id"`
+	ProjectID int    `json:"project_id"`
+	Title     string `json:"title"`
+	Position  int    `json:"position"`
+	WIPLimit  *int   `json:"wip_limit"`
+	IsDone    bool   `json:"is_done"`
+	TaskCount int    `json:"task_count"`
+}
+
+type Label struct {
+	ID        int    `json:"id"`
+	ProjectID int    `json:"project_id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	TaskCount int    `json:"task_count,omitempty"`
+}
+
+// Роли участников проекта
+const (
+	RoleOwner      = "owner"
+	RoleMaintainer = "maintainer"
+	RoleMember     = "member"
+)
+
+type Member struct {
+	ProjectID int       `json:"project_id"`
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+// Статусы передачи владения
+const (
+	TransferPending  = "pending"
+	TransferAccepted = "accepted"
+	TransferDeclined = "declined"
+)
+
+type OwnershipTransfer struct {
+	ID         int        `json:"id"`
+	ProjectID  int        `json:"project_id"`
+	FromUserID int        `json:"from_user_id"`
+	ToUserID   int        `json:"to_user_id"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+// Типы записей истории проекта
+const (
+	ActivityProjectCreated       = "project.created"
+	ActivityProjectUpdated       = "project.updated"
+	ActivityOwnershipTransferred = "project.ownership_transferred"
+	ActivityTaskCreated          = "task.created"
+	ActivityTaskUpdated          = "task.updated"
+	ActivityTaskMoved            = "task.moved"
+	ActivityTaskDeleted          = "task.deleted"
+	ActivityTaskLinked           = "task.linked"
+	ActivityTaskUnlinked         = "task.unlinked"
+	ActivityLabelsMerged         = "labels.merged"
+	ActivityCommentAdded         = "comment.added"
+	ActivityCommentEdited        = "comment.edited"
+	ActivityCommentDeleted       = "comment.deleted"
+	ActivityTimeLogged           = "time.logged"
+	ActivitySprintStarted        = "sprint.started"
+	ActivitySprintCompleted      = "sprint.completed"
+	ActivityMemberChanged        = "member.changed"
+	ActivityMemberRemoved        = "member.removed"
+)
+
+type Activity struct {
+	ID        int64           `json:"id"`
+	ProjectID int             `json:"project_id"`
+	ActorID   *int            `json:"actor_id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ActivityVisit is the user's "since last visit" marker in a project's activity feed
+type ActivityVisit struct {
+	ProjectID  int        `json:"project_id"`
+	LastSeenID int64      `json:"last_seen_id"`
+	VisitedAt  *time.Time `json:"visited_at"`
+	Unread     int        `json:"unread"`
+}
+
+// ProjectSummary is a lightweight project view for navigation lists
+type ProjectSummary struct {
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Key         string     `json:"key"`
+	WorkspaceID *int       `json:"workspace_id"`
+	Position    *int       `json:"position,omitempty"`
+	ViewedAt    *time.Time `json:"viewed_at,omitempty"`
+}
+
+// ProjectStats holds aggregated task numbers for the project dashboard
+type ProjectStats struct {
+	ProjectID          int              `json:"project_id"`
+	Days               int              `json:"days"`
+	TotalTasks         int              `json:"total_tasks"`
+	CompletedTasks     int              `json:"completed_tasks"`
+	AvgCompletionHours *float64         `json:"avg_completion_hours"`
+	OverdueTasks       int              `json:"overdue_tasks"`
+	ByStage            []StageTaskStats `json:"by_stage"`
+	ByPriority         map[string]int   `json:"by_priority"`
+	ByAssignee         []AssigneeStats  `json:"by_assignee"`
+	UnassignedTasks    int              `json:"unassigned_tasks"`
+	Daily              []DailyTaskStats `json:"daily"`
+}
+
+type StageTaskStats struct {
+	StageID  int    `json:"stage_id"`
+	Title    string `json:"title"`
+	Tasks    int    `json:"tasks"`
+	WIPLimit *int   `json:"wip_limit"`
+}
+
+type AssigneeStats struct {
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	Tasks     int    `json:"tasks"`
+	Completed int    `json:"completed"`
+	Overdue   int    `json:"overdue"`
+}
+
+type DailyTaskStats struct {
+	Date      string `json:"date"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+// ProjectExport is the portable snapshot of a project stored in an export archive manifest.
+// IDs are the ones of the source environment and are remapped on import.
+type ProjectExport struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Project    ExportedProject `json:"project"`
+	Members    []ExportedUser  `json:"members"`
+	Stages     []ExportedStage `json:"stages,omitempty"`
+	Labels     []ExportedLabel `json:"labels,omitempty"`
+	Tasks      []ExportedTask  `json:"tasks"`
+	Comments   []ExportedNote  `json:"comments"`
+	Files      []string        `json:"files"`
+	// MissingFiles lists referenced files whose content was not available when the archive was written
+	MissingFiles []string `json:"missing_files,omitempty"`
+}
+
+type ExportedProject struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Key   string `json:"key,omitempty"`
+}
+
+type ExportedUser struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+type ExportedStage struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	WIPLimit *int   `json:"wip_limit,omitempty"`
+	IsDone   bool   `json:"is_done"`
+}
+
+type ExportedLabel struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type ExportedTask struct {
+	ID              int        `json:"id"`
+	Number          int        `json:"number,omitempty"`
+	StageID         *int       `json:"stage_id,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	Priority        string     `json:"priority,omitempty"`
+	StartDate       *time.Time `json:"start_date,omitempty"`
+	Deadline        *time.Time `json:"deadline,omitempty"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	FullDescription string     `json:"full_description"`
+	CreatedAt       time.Time  `json:"created_at"`
+	CompletedAt     *time.Time `json:"completed_at"`
+	Files           []string   `json:"files"`
+	Labels          []int      `json:"labels,omitempty"`
+	Assignees       []string   `json:"assignees,omitempty"`
+	Watchers        []string   `json:"watchers,omitempty"`
+	ParentID        *int       `json:"parent_id,omitempty"`
+	// Checklist lists the task's checklist items in order
+	Checklist []ExportedChecklistItem `json:"checklist,omitempty"`
+	// Comments lists the task's comments, oldest first
+	Comments []ExportedTaskComment `json:"comments,omitempty"`
+}
+
+type ExportedChecklistItem struct {
+	Text     string `json:"text"`
+	Done     bool   `json:"done"`
+	Assignee string `json:"assignee,omitempty"`
+}
+
+// ExportedTaskComment is a task comment; Author is empty when the author's account was deleted
+type ExportedTaskComment struct {
+	Author    string     `json:"author,omitempty"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+type ExportedNote struct {
+	Username  string    `json:"username"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ImportReport describes the result, or with DryRun the expected result, of a project import
+type ImportReport struct {
+	DryRun    bool        `json:"dry_run"`
+	ProjectID int         `json:"project_id,omitempty"`
+	TaskIDs   map[int]int `json:"task_ids"`
+	Conflicts []string    `json:"conflicts"`
+	Warnings  []string    `json:"warnings"`
+}
+
+// ImportedBoard is a board from another tool converted by a format-specific parser
+type ImportedBoard struct {
+	Source   string         `json:"source"`
+	Name     string         `json:"name"`
+	Stages   []string       `json:"stages"`
+	Cards    []ImportedCard `json:"cards"`
+	Skipped  []string       `json:"skipped"`
+	Unmapped map[string]int `json:"unmapped"`
+	// LabelColors holds the hex colors of labels by name when the source has them
+	LabelColors map[string]string `json:"label_colors,omitempty"`
+}
+
+type ImportedCard struct {
+	Key         string            `json:"key"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Stage       string            `json:"stage"`
+	Priority    string            `json:"priority"`
+	Labels      []string          `json:"labels"`
+	Due         *time.Time        `json:"due"`
+	CreatedAt   *time.Time        `json:"created_at"`
+	CompletedAt *time.Time        `json:"completed_at"`
+	Comments    []ImportedComment `json:"comments"`
+}
+
+type ImportedComment struct {
+	Author    string     `json:"author"`
+	Text      string     `json:"text"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+// BoardImportReport describes the result, or with DryRun the expected result, of a board import
+type BoardImportReport struct {
+	DryRun   bool           `json:"dry_run"`
+	Source   string         `json:"source"`
+	Created  int            `json:"created"`
+	TaskIDs  map[string]int `json:"task_ids"`
+	Skipped  []string       `json:"skipped"`
+	Unmapped map[string]int `json:"unmapped"`
+	// StagesCreated lists board columns that did not match an existing stage by title
+	StagesCreated []string `json:"stages_created"`
+	// LabelsCreated lists labels that did not match an existing project label by name
+	LabelsCreated []string `json:"labels_created"`
+}
+
+// Statuses of a task in a bulk update result
+const (
+	BulkTaskOK     = "ok"
+	BulkTaskFailed = "failed"
+	// BulkTaskSkipped marks a task already deleted as a subtask of another task of the same bulk delete
+	BulkTaskSkipped = "skipped"
+)
+
+// BulkResult describes the outcome, or with DryRun the expected outcome, of a bulk task update.
+// The changes are applied only when no task failed.
+type BulkResult struct {
+	DryRun    bool             `json:"dry_run"`
+	Applied   bool             `json:"applied"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Tasks     []BulkTaskResult `json:"tasks"`
+}
+
+type BulkTaskResult struct {
+	TaskID int    `json:"task_id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Kinds of search results
+const (
+	SearchTask    = "task"
+	SearchComment = "comment"
+	SearchProject = "project"
+)
+
+var SearchTypes = []string{SearchTask, SearchComment, SearchProject}
+
+// SearchResult is a task, comment or project matching a search. Title and Snippet are HTML
+// with the matched words in <mark>; a comment's title is the title of its task.
+type SearchResult struct {
+	Type      string  `json:"type"`
+	ID        int     `json:"id"`
+	ProjectID int     `json:"project_id"`
+	TaskID    *int    `json:"task_id"` // task of a comment
+	Key       string  `json:"key"`     // key of the task, empty for projects
+	Title     string  `json:"title"`
+	Snippet   string  `json:"snippet"`
+	Rank      float64 `json:"rank"`
 }
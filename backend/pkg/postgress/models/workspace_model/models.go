@@ -0,0 +1,24 @@
+package workspacemodel
+
+import "time"
+
+// Роли участников рабочего пространства
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+type Workspace struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+	Role      string    `json:"role,omitempty"` // роль текущего пользователя, если известна
+}
+
+type Member struct {
+	WorkspaceID int       `json:"workspace_id"`
+	UserID      int       `json:"user_id"`
+	Username    string    `json:"username"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
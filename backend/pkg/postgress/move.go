@@ -0,0 +1,247 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+	"github.com/nais2008/hackanet2025/backend/pkg/rank"
+)
+
+// rebalanceLockID is the advisory lock key that lets only one server instance rebalance ranks at a time
+const rebalanceLockID = 20250420
+
+// ErrNeighbor is returned when a move refers to a neighbor task that is not in the target stage
+var ErrNeighbor = errors.New("neighbor task is not in the target stage")
+
+// TaskPlacement is the target of a task move. A zero StageID keeps the current stage.
+// BeforeID places the task right before that task, AfterID right after it;
+// with neither the task goes to the end of the stage.
+type TaskPlacement struct {
+	StageID  int
+	BeforeID int
+	AfterID  int
+}
+
+// MoveTask moves a task to the placement, rewriting only the task's own row.
+// Moves into a stage are serialized by the stage row lock, so concurrent moves never get the same rank.
+func (db *DB) MoveTask(ctx context.Context, projectID, taskID int, to TaskPlacement) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		return moveTask(ctx, tx, projectID, taskID, to)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move task: %w", err)
+	}
+	return nil
+}
+
+func moveTask(ctx context.Context, tx pgx.Tx, projectID, taskID int, to TaskPlacement) error {
+	var fromStage *int
+	err := tx.QueryRow(ctx, `
+		SELECT t.stage_id FROM task_task t
+		JOIN project_project p ON p.id = t.project_id
+		WHERE t.id = $1 AND t.project_id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3
+		FOR UPDATE OF t`, taskID, projectID, workspaceArg(ctx)).Scan(&fromStage)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("task %d not found in project %d: %w", taskID, projectID, err)
+		}
+		return err
+	}
+
+	stageID := to.StageID
+	if stageID == 0 {
+		if fromStage == nil {
+			return fmt.Errorf("task %d has no stage: %w", taskID, ErrNeighbor)
+		}
+		stageID = *fromStage
+	}
+	target, err := stageProject(ctx, tx, stageID)
+	if err != nil {
+		return err
+	}
+	if target != projectID {
+		return ErrStageMismatch
+	}
+	blockers, err := doneMoveBlockers(ctx, tx, projectID, taskID, stageID)
+	if err != nil {
+		return err
+	}
+	sameStage := fromStage != nil && *fromStage == stageID
+	if sameStage {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM project_stages WHERE id = $1 FOR UPDATE`, stageID); err != nil {
+			return err
+		}
+	} else if err := reserveStage(ctx, tx, stageID, 1); err != nil {
+		return err
+	}
+
+	lower, upper, err := neighborRanks(ctx, tx, taskID, stageID, to)
+	if err != nil {
+		return err
+	}
+	newRank, err := rank.Between(lower, upper)
+	if err != nil {
+		return fmt.Errorf("failed to rank task between %q and %q: %w", lower, upper, err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE task_task SET stage_id = $2, rank = $3 WHERE id = $1`, taskID, stageID, newRank); err != nil {
+		return err
+	}
+	payload := map[string]any{
+		"task_id":    taskID,
+		"from_stage": fromStage,
+		"to_stage":   stageID,
+	}
+	if to.BeforeID > 0 {
+		payload["before_id"] = to.BeforeID
+	}
+	if to.AfterID > 0 {
+		payload["after_id"] = to.AfterID
+	}
+	if len(blockers) > 0 {
+		payload["blocked_by"] = blockers
+	}
+	return recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskMoved, payload)
+}
+
+// doneMoveBlockers returns the open blockers of a not yet completed task moved to a done stage.
+// Depending on the project's blocked_done_policy such a move is refused with ErrTaskBlocked.
+func doneMoveBlockers(ctx context.Context, tx pgx.Tx, projectID, taskID, stageID int) ([]int, error) {
+	var completing bool
+	err := tx.QueryRow(ctx, `
+		SELECT s.is_done AND t.completed_at IS NULL
+		FROM project_stages s, task_task t
+		WHERE s.id = $1 AND t.id = $2`, stageID, taskID).Scan(&completing)
+	if err != nil || !completing {
+		return nil, err
+	}
+	blockers, err := unresolvedBlockers(ctx, tx, taskID)
+	if err != nil || len(blockers) == 0 {
+		return nil, err
+	}
+	settings, err := projectSettings(ctx, tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return applyBlockedDonePolicy(settings.BlockedDonePolicy, blockers)
+}
+
+// applyBlockedDonePolicy refuses completing a task with open blockers under the refuse policy;
+// otherwise the move is allowed and the blockers are returned to be reported
+func applyBlockedDonePolicy(policy string, blockers []int) ([]int, error) {
+	if len(blockers) == 0 {
+		return nil, nil
+	}
+	if policy == projectmodel.BlockedDoneRefuse {
+		return nil, fmt.Errorf("%w: blocked by tasks %v", ErrTaskBlocked, blockers)
+	}
+	return blockers, nil
+}
+
+// neighborRanks returns the ranks the moved task has to fit between; an empty rank is an open end
+func neighborRanks(ctx context.Context, tx pgx.Tx, taskID, stageID int, to TaskPlacement) (string, string, error) {
+	neighborRank := func(id int) (string, error) {
+		if id == taskID {
+			return "", fmt.Errorf("task cannot be placed next to itself: %w", ErrNeighbor)
+		}
+		var r string
+		err := tx.QueryRow(ctx, `SELECT rank FROM task_task WHERE id = $1 AND stage_id = $2`, id, stageID).Scan(&r)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("task %d: %w", id, ErrNeighbor)
+		}
+		return r, err
+	}
+
+	var lower, upper string
+	switch {
+	case to.BeforeID > 0:
+		r, err := neighborRank(to.BeforeID)
+		if err != nil {
+			return "", "", err
+		}
+		upper = r
+		err = tx.QueryRow(ctx, `
+			SELECT COALESCE(MAX(rank), '') FROM task_task
+			WHERE stage_id = $1 AND rank < $2 AND id <> $3`, stageID, upper, taskID).Scan(&lower)
+		if err != nil {
+			return "", "", err
+		}
+	case to.AfterID > 0:
+		r, err := neighborRank(to.AfterID)
+		if err != nil {
+			return "", "", err
+		}
+		lower = r
+		err = tx.QueryRow(ctx, `
+			SELECT COALESCE(MIN(rank), '') FROM task_task
+			WHERE stage_id = $1 AND rank > $2 AND id <> $3`, stageID, lower, taskID).Scan(&upper)
+		if err != nil {
+			return "", "", err
+		}
+	default:
+		err := tx.QueryRow(ctx, `
+			SELECT COALESCE(MAX(rank), '') FROM task_task
+			WHERE stage_id = $1 AND id <> $2`, stageID, taskID).Scan(&lower)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return lower, upper, nil
+}
+
+// RebalanceRanks respreads the ranks of every stage whose longest rank exceeds maxLen
+// and returns the number of rebalanced stages. It does nothing while another instance is rebalancing.
+func (db *DB) RebalanceRanks(ctx context.Context, maxLen int) (int, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT stage_id FROM task_task
+		WHERE stage_id IS NOT NULL
+		GROUP BY stage_id
+		HAVING MAX(length(rank)) > $1`, maxLen)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find stages to rebalance: %w", err)
+	}
+	stages, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return 0, fmt.Errorf("failed to find stages to rebalance: %w", err)
+	}
+
+	done := 0
+	for _, stageID := range stages {
+		var locked bool
+		err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+			if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, rebalanceLockID).Scan(&locked); err != nil || !locked {
+				return err
+			}
+			if _, err := tx.Exec(ctx, `SET CONSTRAINTS task_task_stage_rank_key DEFERRED`); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, `SELECT 1 FROM project_stages WHERE id = $1 FOR UPDATE`, stageID); err != nil {
+				return err
+			}
+			rows, err := tx.Query(ctx, `SELECT id FROM task_task WHERE stage_id = $1 ORDER BY rank, id`, stageID)
+			if err != nil {
+				return err
+			}
+			ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(ctx, `
+				UPDATE task_task t SET rank = u.rank
+				FROM unnest($1::int[], $2::text[]) AS u(id, rank)
+				WHERE t.id = u.id`, ids, rank.Spread(len(ids)))
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("failed to rebalance stage %d: %w", stageID, err)
+		}
+		if !locked {
+			break
+		}
+		done++
+	}
+	return done, nil
+}
@@ -2,19 +2,24 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/nais2008/hackanet2025/backend/pkg/events"
 	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
 )
 
 // Project retrieves a project by title
 func (db *DB) Project(ctx context.Context, title string) (*projectmodel.Project, error) {
-	query := `SELECT id, title, user_id 
+	query := `SELECT id, title, COALESCE(key, ''), user_id, workspace_id 
               FROM project_project 
-              WHERE title = $1`
+              WHERE title = $1 AND workspace_id IS NOT DISTINCT FROM $2`
 	var p projectmodel.Project
-	err := db.Pool.QueryRow(ctx, query, title).Scan(&p.ID, &p.Title, &p.UserID)
+	err := db.Pool.QueryRow(ctx, query, title, workspaceArg(ctx)).Scan(&p.ID, &p.Title, &p.Key, &p.UserID, &p.WorkspaceID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("project not found: %w", err)
@@ -26,11 +31,11 @@ func (db *DB) Project(ctx context.Context, title string) (*projectmodel.Project,
 
 // GetProjectByID retrieves a project by ID
 func (db *DB) GetProjectByID(ctx context.Context, id int) (*projectmodel.Project, error) {
-	query := `SELECT id, title, user_id 
+	query := `SELECT id, title, COALESCE(key, ''), user_id, workspace_id 
               FROM project_project 
-              WHERE id = $1`
+              WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $2`
 	var p projectmodel.Project
-	err := db.Pool.QueryRow(ctx, query, id).Scan(&p.ID, &p.Title, &p.UserID)
+	err := db.Pool.QueryRow(ctx, query, id, workspaceArg(ctx)).Scan(&p.ID, &p.Title, &p.Key, &p.UserID, &p.WorkspaceID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("project not found: %w", err)
@@ -40,29 +45,126 @@ func (db *DB) GetProjectByID(ctx context.Context, id int) (*projectmodel.Project
 	return &p, nil
 }
 
+// taskColumns lists the task fields read by scanTask; queries alias task_task as t and project_project as p
+const taskColumns = `t.id, t.project_id, t.number, COALESCE(p.key || '-' || t.number, ''),
+	t.stage_id, COALESCE((SELECT title FROM project_stages WHERE id = t.stage_id), ''), t.parent_id, COALESCE(t.rank, ''), t.title,
+	t.description, t.full_description, t.status, t.priority, t.start_date, t.deadline,
+	COALESCE(t.deadline < NOW() AND t.completed_at IS NULL, FALSE), t.created_at, t.completed_at, t.story_points, t.sprint_id,
+	t.template_id, t.occurrence_at, t.archived_at,
+	COALESCE((SELECT json_agg(json_build_object('id', l.id, 'project_id', l.project_id, 'name', l.name, 'color', l.color)
+	                          ORDER BY lower(l.name), l.id)
+	          FROM task_labels tl JOIN project_labels l ON l.id = tl.label_id
+	          WHERE tl.task_id = t.id), '[]'::json),
+	COALESCE((SELECT json_agg(json_build_object('id', u.id, 'username', u.username) ORDER BY a.assigned_at, u.id)
+	          FROM task_assignees a JOIN user_user u ON u.id = a.user_id
+	          WHERE a.task_id = t.id), '[]'::json),
+	COALESCE((SELECT json_agg(json_build_object('id', u.id, 'username', u.username) ORDER BY u.username)
+	          FROM task_watchers w JOIN user_user u ON u.id = w.user_id
+	          WHERE w.task_id = t.id), '[]'::json),
+	(SELECT COUNT(*) FROM task_task c WHERE c.parent_id = t.id),
+	(SELECT COUNT(c.completed_at) FROM task_task c WHERE c.parent_id = t.id),
+	(SELECT COUNT(*) FROM task_checklist_items i WHERE i.task_id = t.id),
+	(SELECT COUNT(*) FILTER (WHERE i.done) FROM task_checklist_items i WHERE i.task_id = t.id),
+	(SELECT COALESCE(SUM(wl.minutes), 0) FROM task_worklogs wl WHERE wl.task_id = t.id),
+	COALESCE((SELECT json_agg(json_build_object('id', b.id, 'project_id', b.project_id,
+	                          'key', COALESCE(bp.key || '-' || b.number, ''), 'title', b.title, 'done', FALSE) ORDER BY b.id)
+	          FROM task_links bl
+	          JOIN task_task b ON b.id = bl.source_id
+	          JOIN project_project bp ON bp.id = b.project_id
+	          WHERE bl.target_id = t.id AND bl.kind = 'blocks' AND b.completed_at IS NULL), '[]'::json)`
+
+func scanTask(row pgx.Row) (projectmodel.Task, error) {
+	var t projectmodel.Task
+	err := row.Scan(&t.ID, &t.ProjectID, &t.Number, &t.Key, &t.StageID, &t.Stage, &t.ParentID, &t.Rank, &t.Title,
+		&t.Description, &t.Full_description, &t.Status, &t.Priority, &t.StartDate, &t.Deadline,
+		&t.Overdue, &t.CreatedAt, &t.CompletedAt, &t.StoryPoints, &t.SprintID,
+		&t.TemplateID, &t.OccurrenceAt, &t.ArchivedAt, &t.Labels, &t.Assignees, &t.Watchers,
+		&t.Progress.Subtasks, &t.Progress.SubtasksDone, &t.Progress.Checklist, &t.Progress.ChecklistDone, &t.LoggedMinutes, &t.Blockers)
+	if total := t.Progress.Subtasks + t.Progress.Checklist; total > 0 {
+		percent := (t.Progress.SubtasksDone + t.Progress.ChecklistDone) * 100 / total
+		t.Progress.Percent = &percent
+	}
+	return t, err
+}
+
 // GetTaskByID retrieves a task by ID
 func (db *DB) GetTaskByID(ctx context.Context, id int) (*projectmodel.Task, error) {
-	query := `SELECT id, project_id, title, description, full_description 
-              FROM task_task 
-              WHERE id = $1`
-	var t projectmodel.Task
-	err := db.Pool.QueryRow(ctx, query, id).Scan(&t.ID, &t.ProjectID, &t.Title, &t.Description, &t.Full_description)
+	query := `SELECT ` + taskColumns + `
+              FROM task_task t
+              JOIN project_project p ON p.id = t.project_id
+              WHERE t.id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2`
+	t, err := scanTask(db.Pool.QueryRow(ctx, query, id, workspaceArg(ctx)))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("task not found: %w", err)
 		}
 		return nil, fmt.Errorf("failed to query task: %w", err)
 	}
-	return &t, nil
+	tasks := []projectmodel.Task{t}
+	if err := hideBlockers(ctx, db.Pool, tasks); err != nil {
+		return nil, fmt.Errorf("failed to query task blockers: %w", err)
+	}
+	return &tasks[0], nil
+}
+
+// GetProjectTask retrieves a task by ID only if it belongs to the project
+func (db *DB) GetProjectTask(ctx context.Context, projectID, id int) (*projectmodel.Task, error) {
+	query := `SELECT ` + taskColumns + `
+              FROM task_task t
+              JOIN project_project p ON p.id = t.project_id
+              WHERE t.id = $1 AND t.project_id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3`
+	t, err := scanTask(db.Pool.QueryRow(ctx, query, id, projectID, workspaceArg(ctx)))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("task %d not found in project %d: %w", id, projectID, err)
+		}
+		return nil, fmt.Errorf("failed to query task: %w", err)
+	}
+	tasks := []projectmodel.Task{t}
+	if err := hideBlockers(ctx, db.Pool, tasks); err != nil {
+		return nil, fmt.Errorf("failed to query task blockers: %w", err)
+	}
+	return &tasks[0], nil
 }
 
-// CreateProject creates a new project and returns its ID
+// CreateProject creates a new project in the active workspace and returns its ID
 func (db *DB) CreateProject(ctx context.Context, title string, userID int) (int, error) {
-	query := `INSERT INTO project_project (title, user_id) 
-              VALUES ($1, $2) 
-              RETURNING id`
+	return db.CreateProjectWithKey(ctx, title, "", userID)
+}
+
+// CreateProjectWithKey creates a project with the given key; an empty key is derived from the title
+func (db *DB) CreateProjectWithKey(ctx context.Context, title, key string, userID int) (int, error) {
+	base, strict := DeriveProjectKey(title), false
+	if key != "" {
+		normalized, err := NormalizeProjectKey(key)
+		if err != nil {
+			return 0, err
+		}
+		base, strict = normalized, true
+	}
+
+	query := `WITH p AS (
+                  INSERT INTO project_project (title, user_id, workspace_id)
+                  VALUES ($1, $2, $3)
+                  RETURNING id, user_id
+              )
+              INSERT INTO project_members (project_id, user_id, role)
+              SELECT id, user_id, 'owner' FROM p
+              RETURNING project_id`
 	var id int
-	err := db.Pool.QueryRow(ctx, query, title, userID).Scan(&id)
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, query, title, userID, workspaceArg(ctx)).Scan(&id); err != nil {
+			return err
+		}
+		key, err := assignProjectKey(ctx, tx, id, base, strict)
+		if err != nil {
+			return err
+		}
+		if _, err := createStages(ctx, tx, id, defaultStages); err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, id, projectmodel.ActivityProjectCreated, map[string]any{"title": title, "key": key})
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to create project: %w", err)
 	}
@@ -73,21 +175,24 @@ func (db *DB) CreateProject(ctx context.Context, title string, userID int) (int,
 func (db *DB) UpdateProjectTitle(ctx context.Context, id int, title string) error {
 	query := `UPDATE project_project 
               SET title = $1 
-              WHERE id = $2`
-	tag, err := db.Pool.Exec(ctx, query, title, id)
-	if err != nil {
-		return fmt.Errorf("failed to update project title: %w", err)
-	}
-	if tag.RowsAffected() == 0 {
-		return fmt.Errorf("project with id %d not found", id)
-	}
-	return nil
+              WHERE id = $2 AND workspace_id IS NOT DISTINCT FROM $3`
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, title, id, workspaceArg(ctx))
+		if err != nil {
+			return fmt.Errorf("failed to update project title: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("project with id %d not found", id)
+		}
+		return recordActivity(ctx, tx, id, projectmodel.ActivityProjectUpdated, map[string]any{"title": title})
+	})
+	return err
 }
 
 // DeleteProject deletes a project by ID
 func (db *DB) DeleteProject(ctx context.Context, id int) error {
-	query := `DELETE FROM project_project WHERE id = $1`
-	tag, err := db.Pool.Exec(ctx, query, id)
+	query := `DELETE FROM project_project WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $2`
+	tag, err := db.Pool.Exec(ctx, query, id, workspaceArg(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete project: %w", err)
 	}
@@ -98,18 +203,208 @@ func (db *DB) DeleteProject(ctx context.Context, id int) error {
 }
 
 func (db *DB) CreateTask(ctx context.Context, projectID int, title, description, fullDescription string) (int, error) {
-	var id int
-	err := db.Pool.QueryRow(ctx, `
-		INSERT INTO task_task (project_id, title, description, full_description)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id`, projectID, title, description, fullDescription).Scan(&id)
-	return id, err
+	return db.CreateTaskFromInput(ctx, projectID, TaskInput{
+		Title:           title,
+		Description:     description,
+		FullDescription: fullDescription,
+	})
+}
+
+// CreateTaskFromInput creates a task in the project. Without a stage the task goes to the first stage;
+// an explicit stage must belong to the project and have room under its WIP limit.
+func (db *DB) CreateTaskFromInput(ctx context.Context, projectID int, in TaskInput) (int, error) {
+	if in.Priority == "" {
+		in.Priority = projectmodel.PriorityMedium
+	}
+	if err := in.validate(); err != nil {
+		return 0, err
+	}
+	var (
+		id  int
+		evs []events.Event
+	)
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if in.ParentID != nil {
+			if err := lockProject(ctx, tx, projectID); err != nil {
+				return err
+			}
+			if err := checkParent(ctx, tx, projectID, 0, *in.ParentID); err != nil {
+				return err
+			}
+		}
+		if in.SprintID != nil {
+			if err := checkSprint(ctx, tx, projectID, *in.SprintID); err != nil {
+				return err
+			}
+		}
+		if in.StageID != nil {
+			target, err := stageProject(ctx, tx, *in.StageID)
+			if err != nil {
+				return err
+			}
+			if target != projectID {
+				return ErrStageMismatch
+			}
+			if err := reserveStage(ctx, tx, *in.StageID, 1); err != nil {
+				return err
+			}
+		}
+		err := tx.QueryRow(ctx, `
+			INSERT INTO task_task (project_id, stage_id, parent_id, title, description, full_description, status, priority,
+			                       start_date, deadline, story_points, sprint_id)
+			SELECT id, $2, $3, $4, $5, $6, $7, $8, $9, $10, $12, $13 FROM project_project
+			WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $11
+			RETURNING id`,
+			projectID, in.StageID, in.ParentID, in.Title, in.Description, in.FullDescription,
+			in.Status, in.Priority, in.StartDate, in.Deadline, workspaceArg(ctx), in.StoryPoints, in.SprintID).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("project not found: %w", err)
+		}
+		if err != nil {
+			return err
+		}
+		err = recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskCreated, map[string]any{
+			"task_id": id,
+			"title":   in.Title,
+		})
+		if err != nil || len(in.AssigneeIDs) == 0 {
+			return err
+		}
+		evs, err = addAssignees(ctx, tx, projectID, id, in.AssigneeIDs)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	db.publish(ctx, evs)
+	return id, nil
+}
+
+// TaskFilter narrows down the tasks returned by GetTasks; zero values do not filter
+type TaskFilter struct {
+	Completed     *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	StageID       *int
+	Status        *string
+	Priorities    []string // any of the listed priorities
+	MinPriority   string   // this priority or higher
+	Overdue       *bool    // past the deadline and not completed
+	DueAfter      *time.Time
+	DueBefore     *time.Time
+	Labels        []int // tasks with any of the labels, or all of them with AllLabels
+	AllLabels     bool
+	AssigneeID    *int // tasks assigned to this user
+	Unassigned    bool // tasks without assignees
+	WatcherID     *int // tasks watched by this user
+	ParentID      *int // direct subtasks of this task
+	TopLevel      bool // tasks that are not subtasks
+	SprintID      *int // tasks of this sprint
+	Backlog       bool // tasks outside of any sprint
+	Archived      bool // archived tasks instead of the active ones
+	// Sort lists sort keys from taskSortKeys, a leading "-" sorts descending; creation order by default
+	Sort []string
+}
+
+// taskSortKeys maps the sort keys accepted by TaskFilter.Sort to SQL expressions
+var taskSortKeys = map[string]string{
+	"created_at": "t.created_at",
+	"deadline":   "t.deadline",
+	"start_date": "t.start_date",
+	"priority":   "array_position(@priorities::text[], t.priority)",
+	"title":      "t.title",
+	"number":     "t.number",
+	"rank":       "t.rank",
+}
+
+// orderBy builds the ORDER BY list for the filter's sort keys; empty values sort last
+func (f TaskFilter) orderBy() (string, error) {
+	var terms []string
+	for _, key := range f.Sort {
+		dir := "ASC"
+		if strings.HasPrefix(key, "-") {
+			key, dir = key[1:], "DESC"
+		}
+		expr, ok := taskSortKeys[key]
+		if !ok {
+			return "", fmt.Errorf("%w: unknown sort key %q", ErrInvalidTask, key)
+		}
+		terms = append(terms, expr+" "+dir+" NULLS LAST")
+	}
+	if len(terms) == 0 {
+		terms = append(terms, "t.created_at")
+	}
+	return strings.Join(append(terms, "t.id"), ", "), nil
 }
 
 func (db *DB) GetTasksByProjectID(ctx context.Context, projectID int) ([]projectmodel.Task, error) {
+	return db.GetTasks(ctx, projectID, TaskFilter{})
+}
+
+// GetTasks returns the project's tasks matching the filter in the filter's sort order
+func (db *DB) GetTasks(ctx context.Context, projectID int, f TaskFilter) ([]projectmodel.Task, error) {
+	for _, priority := range append(slices.Clone(f.Priorities), f.MinPriority) {
+		if priority != "" && !slices.Contains(projectmodel.Priorities, priority) {
+			return nil, fmt.Errorf("%w: unknown priority %q", ErrInvalidTask, priority)
+		}
+	}
+	orderBy, err := f.orderBy()
+	if err != nil {
+		return nil, err
+	}
 	rows, err := db.Pool.Query(ctx, `
-		SELECT id, project_id, title, description, full_description, created_at
-		FROM task_task WHERE project_id = $1`, projectID)
+		SELECT `+taskColumns+`
+		FROM task_task t
+		JOIN project_project p ON p.id = t.project_id
+		WHERE t.project_id = @project_id AND p.workspace_id IS NOT DISTINCT FROM @workspace_id
+		  AND (@completed::bool IS NULL OR (t.completed_at IS NOT NULL) = @completed)
+		  AND (@created_after::timestamptz IS NULL OR t.created_at >= @created_after)
+		  AND (@created_before::timestamptz IS NULL OR t.created_at < @created_before)
+		  AND (@stage_id::int IS NULL OR t.stage_id = @stage_id)
+		  AND (@status::text IS NULL OR t.status = @status)
+		  AND (cardinality(@any_priority::text[]) = 0 OR t.priority = ANY(@any_priority))
+		  AND (@min_priority = '' OR array_position(@priorities::text[], t.priority) >= array_position(@priorities::text[], @min_priority))
+		  AND (@overdue::bool IS NULL OR (t.deadline < NOW() AND t.completed_at IS NULL) = @overdue)
+		  AND (@due_after::timestamptz IS NULL OR t.deadline >= @due_after)
+		  AND (@due_before::timestamptz IS NULL OR t.deadline < @due_before)
+		  AND (cardinality(@labels::int[]) = 0 OR CASE WHEN @all_labels
+		       THEN (SELECT COUNT(*) FROM task_labels tl WHERE tl.task_id = t.id AND tl.label_id = ANY(@labels))
+		            = (SELECT COUNT(DISTINCT l) FROM unnest(@labels::int[]) l)
+		       ELSE EXISTS (SELECT 1 FROM task_labels tl WHERE tl.task_id = t.id AND tl.label_id = ANY(@labels))
+		       END)
+		  AND (@assignee_id::int IS NULL OR EXISTS (SELECT 1 FROM task_assignees a WHERE a.task_id = t.id AND a.user_id = @assignee_id))
+		  AND (NOT @unassigned OR NOT EXISTS (SELECT 1 FROM task_assignees a WHERE a.task_id = t.id))
+		  AND (@watcher_id::int IS NULL OR EXISTS (SELECT 1 FROM task_watchers w WHERE w.task_id = t.id AND w.user_id = @watcher_id))
+		  AND (@parent_id::int IS NULL OR t.parent_id = @parent_id)
+		  AND (NOT @top_level OR t.parent_id IS NULL)
+		  AND (@sprint_id::int IS NULL OR t.sprint_id = @sprint_id)
+		  AND (NOT @backlog OR t.sprint_id IS NULL)
+		  AND (t.archived_at IS NOT NULL) = @archived
+		ORDER BY `+orderBy, pgx.NamedArgs{
+		"project_id":     projectID,
+		"workspace_id":   workspaceArg(ctx),
+		"completed":      f.Completed,
+		"created_after":  f.CreatedAfter,
+		"created_before": f.CreatedBefore,
+		"stage_id":       f.StageID,
+		"status":         f.Status,
+		"any_priority":   append([]string{}, f.Priorities...),
+		"min_priority":   f.MinPriority,
+		"priorities":     projectmodel.Priorities,
+		"overdue":        f.Overdue,
+		"due_after":      f.DueAfter,
+		"due_before":     f.DueBefore,
+		"labels":         append([]int{}, f.Labels...),
+		"all_labels":     f.AllLabels,
+		"assignee_id":    f.AssigneeID,
+		"unassigned":     f.Unassigned,
+		"watcher_id":     f.WatcherID,
+		"parent_id":      f.ParentID,
+		"top_level":      f.TopLevel,
+		"sprint_id":      f.SprintID,
+		"backlog":        f.Backlog,
+		"archived":       f.Archived,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -117,37 +412,93 @@ func (db *DB) GetTasksByProjectID(ctx context.Context, projectID int) ([]project
 
 	tasks := []projectmodel.Task{}
 	for rows.Next() {
-		var t projectmodel.Task
-		err := rows.Scan(&t.ID, &t.ProjectID, &t.Title, &t.Description, &t.Full_description, &t.CreatedAt)
+		t, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
 		tasks = append(tasks, t)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := hideBlockers(ctx, db.Pool, tasks); err != nil {
+		return nil, fmt.Errorf("failed to query task blockers: %w", err)
+	}
 	return tasks, nil
 }
 
-func (db *DB) GetTaskByTitleAndProjectID(ctx context.Context, projectID int, title string) (*projectmodel.Task, error) {
-	var t projectmodel.Task
-	err := db.Pool.QueryRow(ctx, `
-		SELECT id, project_id, title, description, full_description, created_at
-		FROM task_task WHERE project_id = $1 AND title = $2`, projectID, title).Scan(
-		&t.ID, &t.ProjectID, &t.Title, &t.Description, &t.Full_description, &t.CreatedAt,
-	)
+// UpdateTask replaces the title and descriptions of a project's task
+func (db *DB) UpdateTask(ctx context.Context, projectID, id int, title, description, fullDescription string) error {
+	return db.PatchTask(ctx, projectID, id, TaskPatch{
+		Title:           Set(title),
+		Description:     Set(description),
+		FullDescription: Set(fullDescription),
+	})
+}
+
+// PatchTask changes the fields set in the patch and leaves the others as they are
+func (db *DB) PatchTask(ctx context.Context, projectID, id int, patch TaskPatch) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		return patchTask(ctx, tx, projectID, id, patch)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("task %d not found in project %d: %w", id, projectID, err)
+	}
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to update task: %w", err)
 	}
-	return &t, nil
+	return nil
 }
 
-func (db *DB) UpdateTask(ctx context.Context, projectID int, oldTitle, newTitle, description, fullDescription string) error {
-	_, err := db.Pool.Exec(ctx, `
-		UPDATE task_task SET title = $1, description = $2, full_description = $3
-		WHERE project_id = $4 AND title = $5`, newTitle, description, fullDescription, projectID, oldTitle)
-	return err
+func patchTask(ctx context.Context, tx pgx.Tx, projectID, id int, patch TaskPatch) error {
+	t, err := scanTask(tx.QueryRow(ctx, `
+		SELECT `+taskColumns+`
+		FROM task_task t
+		JOIN project_project p ON p.id = t.project_id
+		WHERE t.id = $1 AND t.project_id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3
+		FOR UPDATE OF t`, id, projectID, workspaceArg(ctx)))
+	if err != nil {
+		return err
+	}
+	in := patch.apply(TaskInput{
+		Title:           t.Title,
+		Description:     t.Description,
+		FullDescription: t.Full_description,
+		Status:          t.Status,
+		Priority:        t.Priority,
+		StartDate:       t.StartDate,
+		Deadline:        t.Deadline,
+		StoryPoints:     t.StoryPoints,
+		SprintID:        t.SprintID,
+	})
+	if err := in.validate(); err != nil {
+		return err
+	}
+	if in.SprintID != nil && (t.SprintID == nil || *in.SprintID != *t.SprintID) {
+		if err := checkSprint(ctx, tx, t.ProjectID, *in.SprintID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE task_task
+		SET title = $2, description = $3, full_description = $4,
+		    status = $5, priority = $6, start_date = $7, deadline = $8, story_points = $9, sprint_id = $10
+		WHERE id = $1`,
+		id, in.Title, in.Description, in.FullDescription, in.Status, in.Priority, in.StartDate, in.Deadline,
+		in.StoryPoints, in.SprintID)
+	if err != nil {
+		return err
+	}
+	return recordActivity(ctx, tx, t.ProjectID, projectmodel.ActivityTaskUpdated, map[string]any{
+		"task_id": id,
+		"title":   in.Title,
+		"fields":  patch.fields(),
+	})
 }
 
-func (db *DB) DeleteTask(ctx context.Context, projectID int, title string) error {
-	_, err := db.Pool.Exec(ctx, `DELETE FROM task_task WHERE project_id = $1 AND title = $2`, projectID, title)
-	return err
+// DeleteTask deletes a project's task; a task with subtasks is not deleted
+func (db *DB) DeleteTask(ctx context.Context, projectID, id int) error {
+	return db.DeleteTaskWithSubtasks(ctx, projectID, id, SubtasksRefuse)
 }
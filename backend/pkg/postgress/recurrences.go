@@ -0,0 +1,475 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nais2008/hackanet2025/backend/pkg/events"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+	"github.com/nais2008/hackanet2025/backend/pkg/rrule"
+)
+
+const (
+	// recurrenceLockID is the advisory lock key, together with the recurrence ID, that lets
+	// only one server instance generate the tasks of a recurrence at a time
+	recurrenceLockID = 20250501
+	// maxCatchUpOccurrences limits the missed occurrences one run creates per recurrence,
+	// e.g. after the servers were down for a long time
+	maxCatchUpOccurrences = 100
+	// maxUpcomingOccurrences limits the listed upcoming occurrences
+	maxUpcomingOccurrences = 100
+)
+
+// ErrInvalidRecurrence is returned for a bad rule, time zone or occurrence exception
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+// RecurrenceInput holds the rule of a recurring task
+type RecurrenceInput struct {
+	Rule     string
+	StartsAt time.Time // first occurrence; its wall-clock time in Timezone is kept
+	Timezone string    // IANA name, UTC by default
+}
+
+// recurrence is a stored rule ready to compute occurrences
+type recurrence struct {
+	id       int
+	taskID   int
+	rule     *rrule.Rule
+	startsAt time.Time
+	nextAt   *time.Time
+}
+
+// loadRecurrence reads and parses the recurrence of a template task, locking it when lock is set
+func loadRecurrence(ctx context.Context, q querier, taskID int, lock bool) (*recurrence, error) {
+	query := `SELECT id, task_id, rule, starts_at, timezone, next_at FROM task_recurrences WHERE task_id = $1`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+	var (
+		r        recurrence
+		rule, tz string
+	)
+	err := q.QueryRow(ctx, query, taskID).Scan(&r.id, &r.taskID, &rule, &r.startsAt, &tz, &r.nextAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("task %d has no recurrence: %w", taskID, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.parse(rule, tz); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *recurrence) parse(rule, tz string) error {
+	var err error
+	if r.rule, err = rrule.Parse(rule); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRecurrence, err)
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidRecurrence, tz)
+	}
+	r.startsAt = r.startsAt.In(loc)
+	return nil
+}
+
+// GetRecurrence returns the recurrence of a template task with its exceptions
+func (db *DB) GetRecurrence(ctx context.Context, taskID int) (*projectmodel.Recurrence, error) {
+	if _, err := taskProject(ctx, db.Pool, taskID); err != nil {
+		return nil, err
+	}
+	var r projectmodel.Recurrence
+	err := db.Pool.QueryRow(ctx, `
+		SELECT id, task_id, rule, starts_at, timezone, next_at, created_at,
+		       COALESCE((SELECT json_agg(json_build_object('id', e.id, 'occurrence_at', e.occurrence_at, 'shift_to', e.shift_to)
+		                                 ORDER BY e.occurrence_at)
+		                 FROM task_recurrence_exceptions e WHERE e.recurrence_id = r.id), '[]'::json)
+		FROM task_recurrences r
+		WHERE task_id = $1`, taskID).Scan(&r.ID, &r.TaskID, &r.Rule, &r.StartsAt, &r.Timezone, &r.NextAt, &r.CreatedAt, &r.Exceptions)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("task %d has no recurrence: %w", taskID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recurrence: %w", err)
+	}
+	return &r, nil
+}
+
+// SetRecurrence makes the task a template repeated by the rule, replacing its previous rule
+// and exceptions. Only occurrences from now on are created, even if the rule starts in the past.
+func (db *DB) SetRecurrence(ctx context.Context, projectID, taskID int, in RecurrenceInput) error {
+	if in.Timezone == "" {
+		in.Timezone = "UTC"
+	}
+	r := recurrence{taskID: taskID, startsAt: in.StartsAt}
+	if err := r.parse(in.Rule, in.Timezone); err != nil {
+		return err
+	}
+	from := r.startsAt.Add(-time.Nanosecond)
+	if now := time.Now(); now.After(from) {
+		from = now
+	}
+	nextAt, ok := r.rule.After(r.startsAt, from)
+	if !ok {
+		return fmt.Errorf("%w: the rule has no occurrences from now on", ErrInvalidRecurrence)
+	}
+
+	var actor *int
+	if uid, ok := ActorFromContext(ctx); ok {
+		actor = &uid
+	}
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := checkTask(ctx, tx, projectID, taskID); err != nil {
+			return err
+		}
+		var templateID *int
+		if err := tx.QueryRow(ctx, `SELECT template_id FROM task_task WHERE id = $1`, taskID).Scan(&templateID); err != nil {
+			return err
+		}
+		if templateID != nil {
+			return fmt.Errorf("%w: task %d was created from a recurring task", ErrInvalidRecurrence, taskID)
+		}
+		var id int
+		err := tx.QueryRow(ctx, `
+			INSERT INTO task_recurrences (task_id, rule, starts_at, timezone, next_at, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (task_id) DO UPDATE
+			SET rule = EXCLUDED.rule, starts_at = EXCLUDED.starts_at, timezone = EXCLUDED.timezone,
+			    next_at = EXCLUDED.next_at, created_by = EXCLUDED.created_by, created_at = NOW()
+			RETURNING id`, taskID, r.rule.String(), r.startsAt, in.Timezone, nextAt, actor).Scan(&id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `DELETE FROM task_recurrence_exceptions WHERE recurrence_id = $1`, id)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set recurrence: %w", err)
+	}
+	return nil
+}
+
+// DeleteRecurrence stops repeating the task; tasks already created are kept
+func (db *DB) DeleteRecurrence(ctx context.Context, projectID, taskID int) error {
+	if err := checkTask(ctx, db.Pool, projectID, taskID); err != nil {
+		return err
+	}
+	tag, err := db.Pool.Exec(ctx, `DELETE FROM task_recurrences WHERE task_id = $1`, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to delete recurrence: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("task %d has no recurrence: %w", taskID, pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// SetOccurrenceException skips an upcoming occurrence of the task's rule, or shifts it to
+// shiftTo when that is set. A second exception for the same occurrence replaces the first.
+func (db *DB) SetOccurrenceException(ctx context.Context, projectID, taskID int, occurrence time.Time, shiftTo *time.Time) (int, error) {
+	if shiftTo != nil && !shiftTo.After(time.Now()) {
+		return 0, fmt.Errorf("%w: an occurrence can only be shifted to the future", ErrInvalidRecurrence)
+	}
+	var id int
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := checkTask(ctx, tx, projectID, taskID); err != nil {
+			return err
+		}
+		r, err := loadRecurrence(ctx, tx, taskID, true)
+		if err != nil {
+			return err
+		}
+		if !r.rule.Occurs(r.startsAt, occurrence) {
+			return fmt.Errorf("%w: %s is not an occurrence of the rule", ErrInvalidRecurrence, occurrence.Format(time.RFC3339))
+		}
+		if r.nextAt == nil || occurrence.Before(*r.nextAt) {
+			return fmt.Errorf("%w: occurrence %s has already passed", ErrInvalidRecurrence, occurrence.Format(time.RFC3339))
+		}
+		return tx.QueryRow(ctx, `
+			INSERT INTO task_recurrence_exceptions (recurrence_id, occurrence_at, shift_to)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (recurrence_id, occurrence_at) DO UPDATE SET shift_to = EXCLUDED.shift_to, created_at = NOW()
+			RETURNING id`, r.id, occurrence, shiftTo).Scan(&id)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to set occurrence exception: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteOccurrenceException restores an occurrence. An occurrence whose time has passed
+// stays skipped, and a shifted one whose task was created is not created again.
+func (db *DB) DeleteOccurrenceException(ctx context.Context, projectID, taskID, exceptionID int) error {
+	if err := checkTask(ctx, db.Pool, projectID, taskID); err != nil {
+		return err
+	}
+	tag, err := db.Pool.Exec(ctx, `
+		DELETE FROM task_recurrence_exceptions e
+		USING task_recurrences r
+		WHERE e.id = $2 AND r.id = e.recurrence_id AND r.task_id = $1`, taskID, exceptionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete occurrence exception: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("occurrence exception %d not found: %w", exceptionID, pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// GetUpcomingOccurrences returns up to limit occurrences whose tasks are not created yet,
+// in the order they are due, including skipped ones and occurrences shifted later
+func (db *DB) GetUpcomingOccurrences(ctx context.Context, taskID, limit int) ([]projectmodel.Occurrence, error) {
+	if limit <= 0 || limit > maxUpcomingOccurrences {
+		limit = maxUpcomingOccurrences
+	}
+	if _, err := taskProject(ctx, db.Pool, taskID); err != nil {
+		return nil, err
+	}
+	r, err := loadRecurrence(ctx, db.Pool, taskID, false)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Pool.Query(ctx, `
+		SELECT e.id, e.occurrence_at, e.shift_to FROM task_recurrence_exceptions e
+		WHERE e.recurrence_id = $1
+		  AND NOT EXISTS (SELECT 1 FROM task_task t WHERE t.template_id = $2 AND t.occurrence_at = e.occurrence_at)`,
+		r.id, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query occurrence exceptions: %w", err)
+	}
+	exceptions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.RecurrenceException, error) {
+		var e projectmodel.RecurrenceException
+		err := row.Scan(&e.ID, &e.OccurrenceAt, &e.ShiftTo)
+		return e, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan occurrence exceptions: %w", err)
+	}
+
+	occurrences := []projectmodel.Occurrence{}
+	if r.nextAt != nil {
+		for _, at := range r.rule.Next(r.startsAt, r.nextAt.Add(-time.Nanosecond), limit) {
+			o := projectmodel.Occurrence{At: at, RunAt: &at}
+			for _, e := range exceptions {
+				if e.OccurrenceAt.Equal(at) {
+					o.ExceptionID, o.RunAt = &e.ID, e.ShiftTo
+				}
+			}
+			occurrences = append(occurrences, o)
+		}
+	}
+	// Перенесённые вхождения, время которых уже прошло, ждут своего нового срока
+	for _, e := range exceptions {
+		if e.ShiftTo != nil && (r.nextAt == nil || e.OccurrenceAt.Before(*r.nextAt)) {
+			occurrences = append(occurrences, projectmodel.Occurrence{At: e.OccurrenceAt, RunAt: e.ShiftTo, ExceptionID: &e.ID})
+		}
+	}
+	slices.SortStableFunc(occurrences, func(a, b projectmodel.Occurrence) int {
+		return runAt(a).Compare(runAt(b))
+	})
+	if len(occurrences) > limit {
+		occurrences = occurrences[:limit]
+	}
+	return occurrences, nil
+}
+
+// runAt orders occurrences by when they are due; skipped ones by their original time
+func runAt(o projectmodel.Occurrence) time.Time {
+	if o.RunAt != nil {
+		return *o.RunAt
+	}
+	return o.At
+}
+
+// createOccurrence creates the task of a template's occurrence, starting at runAt.
+// It returns 0 when the task of the occurrence already exists.
+// The task goes to the stage chosen by the stage trigger and fails with ErrWIPLimit when that stage is full,
+// so the occurrence is retried by a later run.
+func createOccurrence(ctx context.Context, tx pgx.Tx, templateID int, occurrence, runAt time.Time) (int, []events.Event, error) {
+	var (
+		id, projectID int
+		title         string
+		stageID       *int
+	)
+	// Срок задачи отстоит от начала так же, как у шаблона
+	err := tx.QueryRow(ctx, `
+		INSERT INTO task_task (project_id, title, description, full_description, status, priority, story_points,
+		                       start_date, deadline, template_id, occurrence_at)
+		SELECT project_id, title, description, full_description, status, priority, story_points,
+		       $3::timestamptz, $3::timestamptz + (deadline - start_date), id, $2::timestamptz
+		FROM task_task WHERE id = $1
+		ON CONFLICT (template_id, occurrence_at) DO NOTHING
+		RETURNING id, project_id, title, stage_id`, templateID, occurrence, runAt).Scan(&id, &projectID, &title, &stageID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	// Лимит проверяется после вставки: под блокировкой колонки новая задача уже учтена в счёте
+	if stageID != nil {
+		if err := reserveStage(ctx, tx, *stageID, 0); err != nil {
+			return 0, nil, err
+		}
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_labels (task_id, label_id)
+		SELECT $2, label_id FROM task_labels WHERE task_id = $1`, templateID, id)
+	if err != nil {
+		return 0, nil, err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_checklist_items (task_id, text, position, assignee_id)
+		SELECT $2, text, position, assignee_id FROM task_checklist_items WHERE task_id = $1`, templateID, id)
+	if err != nil {
+		return 0, nil, err
+	}
+	err = recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskCreated, map[string]any{
+		"task_id":     id,
+		"title":       title,
+		"template_id": templateID,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT a.user_id FROM task_assignees a
+		JOIN project_members m ON m.user_id = a.user_id AND m.project_id = $2
+		WHERE a.task_id = $1
+		ORDER BY a.assigned_at, a.user_id`, templateID, projectID)
+	if err != nil {
+		return 0, nil, err
+	}
+	assignees, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil || len(assignees) == 0 {
+		return id, nil, err
+	}
+	evs, err := addAssignees(ctx, tx, projectID, id, assignees)
+	return id, evs, err
+}
+
+// RunRecurrences creates the tasks of all due occurrences and returns how many were created.
+// A failing template does not stop the run: its error is joined into the returned one and the next template is processed.
+// Each recurrence is processed under its row lock and an advisory lock, and every occurrence
+// maps to at most one task, so concurrent runs on several server instances create each task once.
+func (db *DB) RunRecurrences(ctx context.Context) (int, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT task_id FROM task_recurrences WHERE next_at <= NOW()
+		UNION
+		SELECT r.task_id FROM task_recurrence_exceptions e
+		JOIN task_recurrences r ON r.id = e.recurrence_id
+		WHERE e.shift_to <= NOW()
+		  AND NOT EXISTS (SELECT 1 FROM task_task t WHERE t.template_id = r.task_id AND t.occurrence_at = e.occurrence_at)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find due recurrences: %w", err)
+	}
+	templates, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return 0, fmt.Errorf("failed to find due recurrences: %w", err)
+	}
+
+	created := 0
+	var errs []error
+	for _, templateID := range templates {
+		n, err := db.runRecurrence(ctx, templateID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create recurring tasks of task %d: %w", templateID, err))
+			continue
+		}
+		created += n
+	}
+	return created, errors.Join(errs...)
+}
+
+// runRecurrence creates the due tasks of one template and advances its rule
+func (db *DB) runRecurrence(ctx context.Context, templateID int) (int, error) {
+	var (
+		created int
+		evs     []events.Event
+	)
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var locked bool
+		err := tx.QueryRow(ctx, `
+			SELECT pg_try_advisory_xact_lock($1, id) FROM task_recurrences WHERE task_id = $2`,
+			recurrenceLockID, templateID).Scan(&locked)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !locked) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r, err := loadRecurrence(ctx, tx, templateID, true)
+		if err != nil {
+			return err
+		}
+		var now time.Time
+		if err := tx.QueryRow(ctx, `SELECT NOW()`).Scan(&now); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, `
+			SELECT id, occurrence_at, shift_to FROM task_recurrence_exceptions WHERE recurrence_id = $1`, r.id)
+		if err != nil {
+			return err
+		}
+		exceptions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.RecurrenceException, error) {
+			var e projectmodel.RecurrenceException
+			err := row.Scan(&e.ID, &e.OccurrenceAt, &e.ShiftTo)
+			return e, err
+		})
+		if err != nil {
+			return err
+		}
+		hasException := func(occurrence time.Time) bool {
+			return slices.ContainsFunc(exceptions, func(e projectmodel.RecurrenceException) bool {
+				return e.OccurrenceAt.Equal(occurrence)
+			})
+		}
+
+		create := func(occurrence, runAt time.Time) error {
+			id, taskEvs, err := createOccurrence(ctx, tx, templateID, occurrence, runAt)
+			if id != 0 {
+				created++
+				evs = append(evs, taskEvs...)
+			}
+			return err
+		}
+
+		next := r.nextAt
+		for n := 0; next != nil && !next.After(now) && n < maxCatchUpOccurrences; n++ {
+			// Пропущенное или перенесённое вхождение здесь не создаётся
+			if !hasException(*next) {
+				if err := create(*next, *next); err != nil {
+					return err
+				}
+			}
+			at, ok := r.rule.After(r.startsAt, *next)
+			next = nil
+			if ok {
+				next = &at
+			}
+		}
+		// Перенесённое вхождение создаётся в свой новый срок, даже если он раньше исходного
+		for _, e := range exceptions {
+			if e.ShiftTo != nil && !e.ShiftTo.After(now) {
+				if err := create(e.OccurrenceAt, *e.ShiftTo); err != nil {
+					return err
+				}
+			}
+		}
+		_, err = tx.Exec(ctx, `UPDATE task_recurrences SET next_at = $2 WHERE id = $1`, r.id, next)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	db.publish(ctx, evs)
+	return created, nil
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+	"github.com/nais2008/hackanet2025/backend/pkg/search"
+)
+
+// maxSearchResults limits the results of one search
+const maxSearchResults = 50
+
+// ErrInvalidSearch is returned for unknown search result types
+var ErrInvalidSearch = errors.New("invalid search")
+
+// SearchFilter describes a search; zero values do not filter
+type SearchFilter struct {
+	Query     string
+	UserID    int      // only projects the user is a member of are searched
+	Types     []string // any of projectmodel.SearchTypes, all by default
+	ProjectID *int
+	Limit     int
+}
+
+// Search finds tasks, comments and projects of the user's projects in the active workspace
+// whose text contains words starting with every word of the query, best matches first.
+// Archived tasks and their comments are not searched.
+func (db *DB) Search(ctx context.Context, f SearchFilter) ([]projectmodel.SearchResult, error) {
+	for _, t := range f.Types {
+		if !slices.Contains(projectmodel.SearchTypes, t) {
+			return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidSearch, t)
+		}
+	}
+	if len(f.Types) == 0 {
+		f.Types = projectmodel.SearchTypes
+	}
+	if f.Limit <= 0 || f.Limit > maxSearchResults {
+		f.Limit = maxSearchResults
+	}
+	results := []projectmodel.SearchResult{}
+	query := search.PrefixQuery(f.Query)
+	if query == "" {
+		return results, nil
+	}
+
+	// Подсветка дорогая, поэтому строится только для отобранных результатов
+	rows, err := db.Pool.Query(ctx, `
+		WITH q AS (
+			SELECT to_tsquery('russian', @query) || to_tsquery('english', @query) AS q
+		), visible AS (
+			SELECT p.id, p.key FROM project_project p
+			WHERE p.workspace_id IS NOT DISTINCT FROM @workspace
+			  AND (@project::int IS NULL OR p.id = @project)
+			  AND EXISTS (SELECT 1 FROM project_members m WHERE m.project_id = p.id AND m.user_id = @user)
+		), hits AS (
+			SELECT 'task' AS type, t.id, t.project_id, NULL::int AS task_id, COALESCE(v.key || '-' || t.number, '') AS key,
+			       t.title, concat_ws(' ', t.description, t.full_description) AS body,
+			       ts_rank(t.search_vector, q.q)::float8 AS rank
+			FROM task_task t JOIN visible v ON v.id = t.project_id, q
+			WHERE 'task' = ANY(@types) AND t.archived_at IS NULL AND t.search_vector @@ q.q
+			UNION ALL
+			SELECT 'comment', c.id, t.project_id, t.id, COALESCE(v.key || '-' || t.number, ''),
+			       t.title, c.body, ts_rank(c.search_vector, q.q)::float8
+			FROM task_comments c JOIN task_task t ON t.id = c.task_id JOIN visible v ON v.id = t.project_id, q
+			WHERE 'comment' = ANY(@types) AND t.archived_at IS NULL AND c.search_vector @@ q.q
+			UNION ALL
+			SELECT 'project', p.id, p.id, NULL, '', p.title, '', ts_rank(p.search_vector, q.q)::float8
+			FROM project_project p JOIN visible v ON v.id = p.id, q
+			WHERE 'project' = ANY(@types) AND p.search_vector @@ q.q
+		)
+		SELECT h.type, h.id, h.project_id, h.task_id, h.key,
+		       ts_headline('russian', COALESCE(h.title, ''), q.q, @title_options),
+		       ts_headline('russian', COALESCE(h.body, ''), q.q, @snippet_options), h.rank
+		FROM (SELECT * FROM hits ORDER BY rank DESC, type, id LIMIT @limit) h, q
+		ORDER BY h.rank DESC, h.type, h.id`, pgx.NamedArgs{
+		"query":           query,
+		"workspace":       workspaceArg(ctx),
+		"project":         f.ProjectID,
+		"user":            f.UserID,
+		"types":           f.Types,
+		"limit":           f.Limit,
+		"title_options":   search.TitleOptions,
+		"snippet_options": search.SnippetOptions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r projectmodel.SearchResult
+		if err := rows.Scan(&r.Type, &r.ID, &r.ProjectID, &r.TaskID, &r.Key, &r.Title, &r.Snippet, &r.Rank); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		r.Title, r.Snippet = search.Snippet(r.Title), search.Snippet(r.Snippet)
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	return results, nil
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// maxSubtaskDepthLimit bounds the configurable subtask nesting
+const maxSubtaskDepthLimit = 10
+
+// ErrInvalidSettings is returned for project settings out of their allowed range
+var ErrInvalidSettings = errors.New("invalid project settings")
+
+// ProjectSettingsPatch lists settings changes; unset fields are left as they are
+type ProjectSettingsPatch struct {
+	MaxSubtaskDepth   Optional[int]    `json:"max_subtask_depth"`
+	BlockedDonePolicy Optional[string] `json:"blocked_done_policy"`
+}
+
+// GetProjectSettings returns the settings of a project in the active workspace
+func (db *DB) GetProjectSettings(ctx context.Context, projectID int) (*projectmodel.ProjectSettings, error) {
+	s, err := projectSettings(ctx, db.Pool, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project settings: %w", err)
+	}
+	return s, nil
+}
+
+func projectSettings(ctx context.Context, q querier, projectID int) (*projectmodel.ProjectSettings, error) {
+	var s projectmodel.ProjectSettings
+	err := q.QueryRow(ctx, `
+		SELECT max_subtask_depth, blocked_done_policy FROM project_project
+		WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $2`, projectID, workspaceArg(ctx)).Scan(&s.MaxSubtaskDepth, &s.BlockedDonePolicy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// UpdateProjectSettings applies the patch and returns the resulting settings.
+// Lowering the subtask depth does not touch existing subtasks; only new nesting is checked.
+func (db *DB) UpdateProjectSettings(ctx context.Context, projectID int, patch ProjectSettingsPatch) (*projectmodel.ProjectSettings, error) {
+	var s *projectmodel.ProjectSettings
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := lockProject(ctx, tx, projectID); err != nil {
+			return err
+		}
+		var err error
+		if s, err = projectSettings(ctx, tx, projectID); err != nil {
+			return err
+		}
+		var fields []string
+		if patch.MaxSubtaskDepth.Set {
+			depth := patch.MaxSubtaskDepth.Value
+			if depth < 1 || depth > maxSubtaskDepthLimit {
+				return fmt.Errorf("%w: max_subtask_depth must be between 1 and %d", ErrInvalidSettings, maxSubtaskDepthLimit)
+			}
+			s.MaxSubtaskDepth = depth
+			fields = append(fields, "max_subtask_depth")
+		}
+		if patch.BlockedDonePolicy.Set {
+			switch policy := patch.BlockedDonePolicy.Value; policy {
+			case projectmodel.BlockedDoneWarn, projectmodel.BlockedDoneRefuse:
+				s.BlockedDonePolicy = policy
+			default:
+				return fmt.Errorf("%w: blocked_done_policy must be warn or refuse", ErrInvalidSettings)
+			}
+			fields = append(fields, "blocked_done_policy")
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE project_project SET max_subtask_depth = $2, blocked_done_policy = $3
+			WHERE id = $1`, projectID, s.MaxSubtaskDepth, s.BlockedDonePolicy)
+		if err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityProjectUpdated, map[string]any{
+			"settings": fields,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project settings: %w", err)
+	}
+	return s, nil
+}
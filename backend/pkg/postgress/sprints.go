@@ -0,0 +1,572 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+const (
+	// maxSprintNameLen limits the name of a sprint
+	maxSprintNameLen = 100
+	// maxSprintGoalLen limits the goal of a sprint
+	maxSprintGoalLen = 1000
+	// defaultVelocitySprints is how many completed sprints the velocity covers when not requested
+	defaultVelocitySprints = 5
+	// maxVelocitySprints limits the completed sprints the velocity covers
+	maxVelocitySprints = 50
+)
+
+var (
+	// ErrInvalidSprint is returned for sprint fields out of range or a sprint of another project
+	ErrInvalidSprint = errors.New("invalid sprint")
+	// ErrSprintState is returned when the sprint's state does not allow the operation
+	ErrSprintState = errors.New("operation is not allowed in the sprint's state")
+	// ErrSprintActive is returned when a sprint is started while another one of the project is active
+	ErrSprintActive = errors.New("project already has an active sprint")
+)
+
+// SprintInput holds the fields of a sprint; dates are YYYY-MM-DD
+type SprintInput struct {
+	Name      string
+	Goal      string
+	StartDate string
+	EndDate   string
+}
+
+func (in *SprintInput) validate() error {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Goal = strings.TrimSpace(in.Goal)
+	if in.Name == "" || utf8.RuneCountInString(in.Name) > maxSprintNameLen {
+		return fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidSprint, maxSprintNameLen)
+	}
+	if utf8.RuneCountInString(in.Goal) > maxSprintGoalLen {
+		return fmt.Errorf("%w: goal must be at most %d characters", ErrInvalidSprint, maxSprintGoalLen)
+	}
+	start, err := time.Parse(time.DateOnly, in.StartDate)
+	if err != nil {
+		return fmt.Errorf("%w: start_date must be YYYY-MM-DD", ErrInvalidSprint)
+	}
+	end, err := time.Parse(time.DateOnly, in.EndDate)
+	if err != nil {
+		return fmt.Errorf("%w: end_date must be YYYY-MM-DD", ErrInvalidSprint)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("%w: end_date is before start_date", ErrInvalidSprint)
+	}
+	return nil
+}
+
+// SprintPatch lists sprint field changes; unset fields are left as they are
+type SprintPatch struct {
+	Name      Optional[string] `json:"name"`
+	Goal      Optional[string] `json:"goal"`
+	StartDate Optional[string] `json:"start_date"`
+	EndDate   Optional[string] `json:"end_date"`
+}
+
+const sprintColumns = `s.id, s.project_id, s.name, s.goal,
+	to_char(s.start_date, 'YYYY-MM-DD'), to_char(s.end_date, 'YYYY-MM-DD'),
+	s.state, s.started_at, s.completed_at, s.created_at,
+	(SELECT COUNT(*) FROM task_task t WHERE t.sprint_id = s.id),
+	(SELECT COUNT(t.completed_at) FROM task_task t WHERE t.sprint_id = s.id),
+	(SELECT COALESCE(SUM(t.story_points), 0) FROM task_task t WHERE t.sprint_id = s.id),
+	(SELECT COALESCE(SUM(t.story_points) FILTER (WHERE t.completed_at IS NOT NULL), 0) FROM task_task t WHERE t.sprint_id = s.id)`
+
+func scanSprint(row pgx.Row) (projectmodel.Sprint, error) {
+	var s projectmodel.Sprint
+	err := row.Scan(&s.ID, &s.ProjectID, &s.Name, &s.Goal, &s.StartDate, &s.EndDate,
+		&s.State, &s.StartedAt, &s.CompletedAt, &s.CreatedAt,
+		&s.Tasks, &s.TasksDone, &s.Points, &s.PointsDone)
+	return s, err
+}
+
+// checkSprint checks that tasks of the project can be planned into the sprint
+func checkSprint(ctx context.Context, q querier, projectID, sprintID int) error {
+	var (
+		sprintProject int
+		state         string
+	)
+	err := q.QueryRow(ctx, `SELECT project_id, state FROM project_sprints WHERE id = $1`, sprintID).Scan(&sprintProject, &state)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("sprint %d not found: %w", sprintID, err)
+	}
+	if err != nil {
+		return err
+	}
+	if sprintProject != projectID {
+		return fmt.Errorf("%w: sprint %d is in another project", ErrInvalidSprint, sprintID)
+	}
+	if state == projectmodel.SprintCompleted {
+		return fmt.Errorf("%w: sprint %d is completed", ErrSprintState, sprintID)
+	}
+	return nil
+}
+
+// lockSprint locks a sprint of the active workspace and returns its project and state
+func lockSprint(ctx context.Context, tx pgx.Tx, sprintID int) (projectID int, state string, err error) {
+	err = tx.QueryRow(ctx, `
+		SELECT s.project_id, s.state FROM project_sprints s
+		JOIN project_project p ON p.id = s.project_id
+		WHERE s.id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2
+		FOR UPDATE OF s`, sprintID, workspaceArg(ctx)).Scan(&projectID, &state)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, "", fmt.Errorf("sprint %d not found: %w", sprintID, err)
+	}
+	return projectID, state, err
+}
+
+// GetSprints returns the project's sprints by start date
+func (db *DB) GetSprints(ctx context.Context, projectID int) ([]projectmodel.Sprint, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT `+sprintColumns+`
+		FROM project_sprints s
+		JOIN project_project p ON p.id = s.project_id
+		WHERE s.project_id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2
+		ORDER BY s.start_date, s.id`, projectID, workspaceArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sprints: %w", err)
+	}
+	sprints, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.Sprint, error) {
+		return scanSprint(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan sprints: %w", err)
+	}
+	return sprints, nil
+}
+
+// GetSprint returns a sprint of the project
+func (db *DB) GetSprint(ctx context.Context, projectID, sprintID int) (*projectmodel.Sprint, error) {
+	s, err := scanSprint(db.Pool.QueryRow(ctx, `
+		SELECT `+sprintColumns+`
+		FROM project_sprints s
+		JOIN project_project p ON p.id = s.project_id
+		WHERE s.id = $1 AND s.project_id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3`,
+		sprintID, projectID, workspaceArg(ctx)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("sprint %d not found in project %d: %w", sprintID, projectID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sprint: %w", err)
+	}
+	return &s, nil
+}
+
+// CreateSprint adds a planned sprint to the project
+func (db *DB) CreateSprint(ctx context.Context, projectID int, in SprintInput) (int, error) {
+	if err := in.validate(); err != nil {
+		return 0, err
+	}
+	var id int
+	err := db.Pool.QueryRow(ctx, `
+		INSERT INTO project_sprints (project_id, name, goal, start_date, end_date)
+		SELECT id, $2, $3, $4::date, $5::date FROM project_project
+		WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $6
+		RETURNING id`, projectID, in.Name, in.Goal, in.StartDate, in.EndDate, workspaceArg(ctx)).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("project not found: %w", err)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to create sprint: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateSprint changes the fields set in the patch; a completed sprint cannot be changed
+func (db *DB) UpdateSprint(ctx context.Context, sprintID int, patch SprintPatch) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if _, state, err := lockSprint(ctx, tx, sprintID); err != nil {
+			return err
+		} else if state == projectmodel.SprintCompleted {
+			return fmt.Errorf("%w: sprint %d is completed", ErrSprintState, sprintID)
+		}
+		var in SprintInput
+		err := tx.QueryRow(ctx, `
+			SELECT name, goal, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD')
+			FROM project_sprints WHERE id = $1`, sprintID).Scan(&in.Name, &in.Goal, &in.StartDate, &in.EndDate)
+		if err != nil {
+			return err
+		}
+		if patch.Name.Set {
+			in.Name = patch.Name.Value
+		}
+		if patch.Goal.Set {
+			in.Goal = patch.Goal.Value
+		}
+		if patch.StartDate.Set {
+			in.StartDate = patch.StartDate.Value
+		}
+		if patch.EndDate.Set {
+			in.EndDate = patch.EndDate.Value
+		}
+		if err := in.validate(); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE project_sprints SET name = $2, goal = $3, start_date = $4::date, end_date = $5::date
+			WHERE id = $1`, sprintID, in.Name, in.Goal, in.StartDate, in.EndDate)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update sprint: %w", err)
+	}
+	return nil
+}
+
+// DeleteSprint deletes a planned sprint; its tasks go back to the backlog
+func (db *DB) DeleteSprint(ctx context.Context, sprintID int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if _, state, err := lockSprint(ctx, tx, sprintID); err != nil {
+			return err
+		} else if state != projectmodel.SprintPlanned {
+			return fmt.Errorf("%w: only a planned sprint can be deleted", ErrSprintState)
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM project_sprints WHERE id = $1`, sprintID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete sprint: %w", err)
+	}
+	return nil
+}
+
+// StartSprint makes a planned sprint the project's active one
+func (db *DB) StartSprint(ctx context.Context, sprintID int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		projectID, state, err := lockSprint(ctx, tx, sprintID)
+		if err != nil {
+			return err
+		}
+		if state != projectmodel.SprintPlanned {
+			return fmt.Errorf("%w: sprint %d is %s", ErrSprintState, sprintID, state)
+		}
+		if err := lockProject(ctx, tx, projectID); err != nil {
+			return err
+		}
+		var active bool
+		err = tx.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM project_sprints WHERE project_id = $1 AND state = 'active')`,
+			projectID).Scan(&active)
+		if err != nil {
+			return err
+		}
+		if active {
+			return ErrSprintActive
+		}
+
+		var points int
+		err = tx.QueryRow(ctx, `
+			UPDATE project_sprints SET state = 'active', started_at = NOW()
+			WHERE id = $1
+			RETURNING (SELECT COALESCE(SUM(story_points), 0) FROM task_task WHERE sprint_id = $1)`,
+			sprintID).Scan(&points)
+		if err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivitySprintStarted, map[string]any{
+			"sprint_id": sprintID,
+			"points":    points,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start sprint: %w", err)
+	}
+	return nil
+}
+
+// CompleteSprint completes the active sprint and carries its unfinished tasks over.
+// When carryTo is not set they move to the project's next planned sprint, or to the backlog
+// when there is none; a set carryTo names the planned sprint, nil meaning the backlog.
+func (db *DB) CompleteSprint(ctx context.Context, sprintID int, carryTo Optional[*int]) (*projectmodel.SprintCompletion, error) {
+	var result projectmodel.SprintCompletion
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		projectID, state, err := lockSprint(ctx, tx, sprintID)
+		if err != nil {
+			return err
+		}
+		if state != projectmodel.SprintActive {
+			return fmt.Errorf("%w: sprint %d is %s", ErrSprintState, sprintID, state)
+		}
+
+		target := carryTo.Value
+		if !carryTo.Set {
+			err := tx.QueryRow(ctx, `
+				SELECT id FROM project_sprints
+				WHERE project_id = $1 AND state = 'planned'
+				ORDER BY start_date, id
+				LIMIT 1`, projectID).Scan(&target)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+		} else if target != nil {
+			if *target == sprintID {
+				return fmt.Errorf("%w: tasks cannot be carried over to the sprint being completed", ErrInvalidSprint)
+			}
+			if err := checkSprint(ctx, tx, projectID, *target); err != nil {
+				return err
+			}
+		}
+
+		rows, err := tx.Query(ctx, `
+			UPDATE task_task SET sprint_id = $2
+			WHERE sprint_id = $1 AND completed_at IS NULL
+			RETURNING id`, sprintID, target)
+		if err != nil {
+			return err
+		}
+		if result.CarriedOver, err = pgx.CollectRows(rows, pgx.RowTo[int]); err != nil {
+			return err
+		}
+		result.CarriedTo = target
+
+		err = tx.QueryRow(ctx, `
+			UPDATE project_sprints SET state = 'completed', completed_at = NOW()
+			WHERE id = $1
+			RETURNING (SELECT COALESCE(SUM(story_points), 0) FROM task_task WHERE sprint_id = $1)`,
+			sprintID).Scan(&result.CompletedPoints)
+		if err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivitySprintCompleted, map[string]any{
+			"sprint_id":        sprintID,
+			"completed_points": result.CompletedPoints,
+			"carried_over":     result.CarriedOver,
+			"carried_to":       target,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete sprint: %w", err)
+	}
+	return &result, nil
+}
+
+// sprintTask is a task that has been in a sprint, with its current fields and its history of them
+type sprintTask struct {
+	sprintID *int
+	points   int
+	done     bool
+	// changes of sprint_id, story_points and completed_at, newest first
+	changes []fieldChange
+}
+
+type fieldChange struct {
+	field     string
+	oldValue  *string
+	changedAt time.Time
+}
+
+// before returns the task's sprint, points and completion as they were just before at,
+// by reverting its newer changes
+func (t sprintTask) before(at time.Time) (sprintID *int, points int, done bool) {
+	sprintID, points, done = t.sprintID, t.points, t.done
+	for _, c := range t.changes {
+		if c.changedAt.Before(at) {
+			break
+		}
+		switch c.field {
+		case "sprint_id":
+			sprintID = nil
+			if c.oldValue != nil {
+				if id, err := strconv.Atoi(*c.oldValue); err == nil {
+					sprintID = &id
+				}
+			}
+		case "story_points":
+			points = 0
+			if c.oldValue != nil {
+				points, _ = strconv.Atoi(*c.oldValue)
+			}
+		case "completed_at":
+			done = c.oldValue != nil
+		}
+	}
+	return sprintID, points, done
+}
+
+// sprintHistory replays the history of all tasks that have ever been in a sprint
+type sprintHistory struct {
+	sprintID int
+	tasks    []sprintTask
+}
+
+// before sums the story points in the sprint and those of them not completed just before at
+func (h sprintHistory) before(at time.Time) (scope, remaining int) {
+	for _, t := range h.tasks {
+		sprintID, points, done := t.before(at)
+		if sprintID == nil || *sprintID != h.sprintID {
+			continue
+		}
+		scope += points
+		if !done {
+			remaining += points
+		}
+	}
+	return scope, remaining
+}
+
+// loadSprintHistory reads the tasks that have been in the sprint and their changes from the task history
+func loadSprintHistory(ctx context.Context, q querier, sprintID int) (*sprintHistory, error) {
+	rows, err := q.Query(ctx, `
+		SELECT t.id, t.sprint_id, COALESCE(t.story_points, 0), t.completed_at IS NOT NULL
+		FROM task_task t
+		WHERE t.sprint_id = $1
+		   OR t.id IN (SELECT task_id FROM task_history WHERE field = 'sprint_id' AND new_value = $1::text)`, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		ids   []int
+		tasks = map[int]*sprintTask{}
+	)
+	for rows.Next() {
+		var (
+			id int
+			t  sprintTask
+		)
+		if err := rows.Scan(&id, &t.sprintID, &t.points, &t.done); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+		tasks[id] = &t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = q.Query(ctx, `
+		SELECT task_id, field, old_value, changed_at FROM task_history
+		WHERE task_id = ANY($1) AND field IN ('sprint_id', 'story_points', 'completed_at')
+		ORDER BY changed_at DESC, id DESC`, append([]int{}, ids...))
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			taskID int
+			c      fieldChange
+		)
+		if err := rows.Scan(&taskID, &c.field, &c.oldValue, &c.changedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tasks[taskID].changes = append(tasks[taskID].changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	h := &sprintHistory{sprintID: sprintID}
+	for _, id := range ids {
+		h.tasks = append(h.tasks, *tasks[id])
+	}
+	return h, nil
+}
+
+// GetBurndown returns the sprint's story points day by day in loc, replayed from the task history.
+// Days after today, or after the sprint was completed, have no actual values.
+func (db *DB) GetBurndown(ctx context.Context, projectID, sprintID int, loc *time.Location) (*projectmodel.Burndown, error) {
+	sprint, err := db.GetSprint(ctx, projectID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	h, err := loadSprintHistory(ctx, db.Pool, sprintID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sprint history: %w", err)
+	}
+
+	start, _ := time.ParseInLocation(time.DateOnly, sprint.StartDate, loc)
+	end, _ := time.ParseInLocation(time.DateOnly, sprint.EndDate, loc)
+	// После завершения спринта незавершённые задачи уходят из него, поэтому
+	// последнее состояние берётся на момент перед завершением
+	cutoff := time.Now().Add(time.Microsecond)
+	if sprint.CompletedAt != nil {
+		cutoff = *sprint.CompletedAt
+	}
+
+	b := &projectmodel.Burndown{SprintID: sprintID, Committed: sprint.Points}
+	if sprint.StartedAt != nil {
+		b.Committed, _ = h.before(sprint.StartedAt.Add(time.Microsecond))
+	}
+	var days []time.Time
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	for i, day := range days {
+		d := projectmodel.BurndownDay{Date: day.Format(time.DateOnly)}
+		if len(days) > 1 {
+			d.Ideal = float64(b.Committed) * float64(len(days)-1-i) / float64(len(days)-1)
+		}
+		if day.Before(cutoff) {
+			at := day.AddDate(0, 0, 1)
+			if cutoff.Before(at) {
+				at = cutoff
+			}
+			scope, remaining := h.before(at)
+			d.Scope, d.Remaining = &scope, &remaining
+		}
+		b.Days = append(b.Days, d)
+	}
+	return b, nil
+}
+
+// GetVelocity returns the points committed and completed in the project's latest completed sprints,
+// replayed from the task history
+func (db *DB) GetVelocity(ctx context.Context, projectID, limit int) (*projectmodel.Velocity, error) {
+	if limit <= 0 {
+		limit = defaultVelocitySprints
+	}
+	if limit > maxVelocitySprints {
+		limit = maxVelocitySprints
+	}
+	rows, err := db.Pool.Query(ctx, `
+		SELECT s.id, s.name, s.started_at, s.completed_at
+		FROM project_sprints s
+		JOIN project_project p ON p.id = s.project_id
+		WHERE s.project_id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2 AND s.state = 'completed'
+		ORDER BY s.completed_at DESC
+		LIMIT $3`, projectID, workspaceArg(ctx), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sprints: %w", err)
+	}
+	type completedSprint struct {
+		projectmodel.SprintVelocity
+		startedAt time.Time
+	}
+	sprints, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (completedSprint, error) {
+		var s completedSprint
+		err := row.Scan(&s.SprintID, &s.Name, &s.startedAt, &s.EndedAt)
+		return s, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan sprints: %w", err)
+	}
+
+	v := &projectmodel.Velocity{Sprints: []projectmodel.SprintVelocity{}}
+	for i := len(sprints) - 1; i >= 0; i-- {
+		s := sprints[i]
+		h, err := loadSprintHistory(ctx, db.Pool, s.SprintID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query sprint history: %w", err)
+		}
+		s.Committed, _ = h.before(s.startedAt.Add(time.Microsecond))
+		// Незавершённые задачи переносятся в той же транзакции, что и завершение, поэтому
+		// состояние берётся на момент перед ней
+		scope, remaining := h.before(s.EndedAt)
+		s.Completed = scope - remaining
+		v.Sprints = append(v.Sprints, s.SprintVelocity)
+		v.Average += float64(s.Completed)
+	}
+	if len(v.Sprints) > 0 {
+		v.Average /= float64(len(v.Sprints))
+	}
+	return v, nil
+}
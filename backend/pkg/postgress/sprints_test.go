@@ -0,0 +1,115 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	sprintStart = time.Date(2025, 5, 12, 10, 0, 0, 0, time.UTC)
+	sprintMid   = sprintStart.Add(24 * time.Hour)
+	sprintLate  = sprintStart.Add(48 * time.Hour)
+	sprintEnd   = sprintStart.Add(72 * time.Hour)
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// sprintTasks describes tasks of sprint 1, each with its changes newest first
+func sprintTasks() map[string]db.SprintTask {
+	planned := db.SprintChange{Field: "sprint_id", OldValue: nil, ChangedAt: sprintStart.Add(-time.Hour)}
+	return map[string]db.SprintTask{
+		"completed": {SprintID: ptr(1), Points: 3, Done: true, Changes: []db.SprintChange{
+			{Field: "completed_at", OldValue: nil, ChangedAt: sprintMid},
+			planned,
+		}},
+		// not completed by the end and carried over to sprint 2 when sprint 1 was completed
+		"carried over": {SprintID: ptr(2), Points: 5, Changes: []db.SprintChange{
+			{Field: "sprint_id", OldValue: ptr("1"), ChangedAt: sprintEnd},
+			planned,
+		}},
+		"added after start": {SprintID: ptr(1), Points: 2, Changes: []db.SprintChange{
+			{Field: "sprint_id", OldValue: nil, ChangedAt: sprintMid},
+		}},
+		// created directly inside the sprint: the insert trigger records its sprint and points
+		"created in sprint": {SprintID: ptr(1), Points: 4, Changes: []db.SprintChange{
+			{Field: "sprint_id", OldValue: nil, ChangedAt: sprintMid},
+			{Field: "story_points", OldValue: nil, ChangedAt: sprintMid},
+		}},
+		"points changed": {SprintID: ptr(1), Points: 8, Changes: []db.SprintChange{
+			{Field: "story_points", OldValue: ptr("1"), ChangedAt: sprintLate},
+			planned,
+		}},
+		"reopened": {SprintID: ptr(1), Points: 3, Changes: []db.SprintChange{
+			{Field: "completed_at", OldValue: ptr(sprintMid.Format(time.RFC3339)), ChangedAt: sprintLate},
+			{Field: "completed_at", OldValue: nil, ChangedAt: sprintMid},
+			planned,
+		}},
+	}
+}
+
+func TestSprintTaskBefore(t *testing.T) {
+	tasks := sprintTasks()
+	tests := []struct {
+		name     string
+		task     string
+		at       time.Time
+		sprintID *int
+		points   int
+		done     bool
+	}{
+		{"before planning", "completed", sprintStart.Add(-2 * time.Hour), nil, 3, false},
+		{"completed mid-sprint", "completed", sprintMid.Add(time.Microsecond), ptr(1), 3, true},
+		{"carried over at completion", "carried over", sprintEnd, ptr(1), 5, false},
+		{"after carry-over", "carried over", sprintEnd.Add(time.Microsecond), ptr(2), 5, false},
+		{"not yet added at start", "added after start", sprintStart.Add(time.Microsecond), nil, 2, false},
+		{"added", "added after start", sprintMid.Add(time.Microsecond), ptr(1), 2, false},
+		{"not yet created at start", "created in sprint", sprintStart.Add(time.Microsecond), nil, 0, false},
+		{"created", "created in sprint", sprintMid.Add(time.Microsecond), ptr(1), 4, false},
+		{"points at start", "points changed", sprintStart.Add(time.Microsecond), ptr(1), 1, false},
+		{"points after change", "points changed", sprintLate.Add(time.Microsecond), ptr(1), 8, false},
+		{"open at start", "reopened", sprintStart.Add(time.Microsecond), ptr(1), 3, false},
+		{"done before reopening", "reopened", sprintMid.Add(time.Microsecond), ptr(1), 3, true},
+		{"reopened", "reopened", sprintLate.Add(time.Microsecond), ptr(1), 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sprintID, points, done := db.SprintTaskBefore(tasks[tt.task], tt.at)
+			require.Equal(t, tt.sprintID, sprintID)
+			require.Equal(t, tt.points, points)
+			require.Equal(t, tt.done, done)
+		})
+	}
+}
+
+func TestSprintHistoryBefore(t *testing.T) {
+	var tasks []db.SprintTask
+	for _, task := range sprintTasks() {
+		tasks = append(tasks, task)
+	}
+	tests := []struct {
+		name      string
+		at        time.Time
+		scope     int
+		remaining int
+	}{
+		// committed: completed 3, carried over 5, points changed 1, reopened 3
+		{"committed at start", sprintStart.Add(time.Microsecond), 12, 12},
+		// added and created tasks join the scope; completed and reopened are done
+		{"mid-sprint", sprintMid.Add(time.Microsecond), 18, 12},
+		// points raised to 8 and the reopened task counts as remaining again
+		{"late in sprint", sprintLate.Add(time.Microsecond), 25, 22},
+		// the carried over task still belongs to the sprint at its completion
+		{"at completion", sprintEnd, 25, 22},
+		{"after completion", sprintEnd.Add(time.Microsecond), 20, 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, remaining := db.SprintHistoryBefore(1, tasks, tt.at)
+			require.Equal(t, tt.scope, scope)
+			require.Equal(t, tt.remaining, remaining)
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+var (
+	// ErrWIPLimit is returned when a stage has no room left under its WIP limit
+	ErrWIPLimit = errors.New("stage WIP limit reached")
+	// ErrStageNotEmpty is returned when a stage with tasks is deleted without a target stage
+	ErrStageNotEmpty = errors.New("stage has tasks, choose a stage to move them to")
+	// ErrLastStage is returned when the only stage of a project is deleted
+	ErrLastStage = errors.New("project must keep at least one stage")
+	// ErrStageMismatch is returned when a stage belongs to a different project than the task
+	ErrStageMismatch = errors.New("stage belongs to another project")
+)
+
+// defaultStages are created for every new project
+var defaultStages = []StageInput{
+	{Title: "К выполнению"},
+	{Title: "В процессе"},
+	{Title: "Сделано", IsDone: true},
+}
+
+// StageInput carries the editable fields of a stage
+type StageInput struct {
+	Title    string
+	WIPLimit *int // nil means no limit
+	IsDone   bool // tasks in a done stage count as completed
+}
+
+// createStages appends stages to the end of a project's board
+func createStages(ctx context.Context, q querier, projectID int, stages []StageInput) ([]int, error) {
+	ids := make([]int, 0, len(stages))
+	for _, s := range stages {
+		var id int
+		err := q.QueryRow(ctx, `
+			INSERT INTO project_stages (project_id, title, position, wip_limit, is_done)
+			SELECT $1, $2, COALESCE(MAX(position) + 1, 0), $3, $4
+			FROM project_stages WHERE project_id = $1
+			RETURNING id`, projectID, s.Title, s.WIPLimit, s.IsDone).Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// stageProject returns the project of a stage in the active workspace
+func stageProject(ctx context.Context, q querier, stageID int) (int, error) {
+	var projectID int
+	err := q.QueryRow(ctx, `
+		SELECT s.project_id FROM project_stages s
+		JOIN project_project p ON p.id = s.project_id
+		WHERE s.id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2`, stageID, workspaceArg(ctx)).Scan(&projectID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("stage %d not found: %w", stageID, err)
+	}
+	return projectID, err
+}
+
+// reserveStage locks the stage row and checks that incoming more tasks fit under its WIP limit; archived tasks do not count.
+// The lock serializes concurrent moves into the same stage until the transaction ends.
+func reserveStage(ctx context.Context, tx pgx.Tx, stageID, incoming int) error {
+	var limit *int
+	err := tx.QueryRow(ctx, `SELECT wip_limit FROM project_stages WHERE id = $1 FOR UPDATE`, stageID).Scan(&limit)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("stage %d not found: %w", stageID, err)
+		}
+		return err
+	}
+	if limit == nil {
+		return nil
+	}
+	var count int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM task_task WHERE stage_id = $1 AND archived_at IS NULL`, stageID).Scan(&count); err != nil {
+		return err
+	}
+	if count+incoming > *limit {
+		return fmt.Errorf("%w: %d of %d tasks", ErrWIPLimit, count, *limit)
+	}
+	return nil
+}
+
+// GetStages returns the project's stages in board order with their task counts
+func (db *DB) GetStages(ctx context.Context, projectID int) ([]projectmodel.Stage, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT s.id, s.project_id, s.title, s.position, s.wip_limit, s.is_done,
+		       (SELECT COUNT(*) FROM task_task t WHERE t.stage_id = s.id AND t.archived_at IS NULL)
+		FROM project_stages s
+		JOIN project_project p ON p.id = s.project_id
+		WHERE s.project_id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2
+		ORDER BY s.position, s.id`, projectID, workspaceArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stages: %w", err)
+	}
+	stages, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.Stage, error) {
+		var s projectmodel.Stage
+		err := row.Scan(&s.ID, &s.ProjectID, &s.Title, &s.Position, &s.WIPLimit, &s.IsDone, &s.TaskCount)
+		return s, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan stages: %w", err)
+	}
+	return stages, nil
+}
+
+// CreateStage adds a stage at the end of the project's board
+func (db *DB) CreateStage(ctx context.Context, projectID int, input StageInput) (int, error) {
+	input.Title = strings.TrimSpace(input.Title)
+	var id int
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		// Блокировка проекта не даёт двум колонкам получить одну позицию
+		err := tx.QueryRow(ctx, `
+			SELECT id FROM project_project
+			WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $2
+			FOR UPDATE`, projectID, workspaceArg(ctx)).Scan(&id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("project not found: %w", err)
+			}
+			return err
+		}
+		ids, err := createStages(ctx, tx, projectID, []StageInput{input})
+		if err != nil {
+			return err
+		}
+		id = ids[0]
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create stage: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateStage changes a stage's title, WIP limit and done flag.
+// Switching the done flag completes or reopens the tasks in the stage.
+func (db *DB) UpdateStage(ctx context.Context, projectID, stageID int, input StageInput) error {
+	input.Title = strings.TrimSpace(input.Title)
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var wasDone bool
+		err := tx.QueryRow(ctx, `
+			SELECT s.is_done FROM project_stages s
+			JOIN project_project p ON p.id = s.project_id
+			WHERE s.id = $1 AND s.project_id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3
+			FOR UPDATE OF s`, stageID, projectID, workspaceArg(ctx)).Scan(&wasDone)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("stage %d not found: %w", stageID, err)
+			}
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE project_stages SET title = $2, wip_limit = $3, is_done = $4
+			WHERE id = $1`, stageID, input.Title, input.WIPLimit, input.IsDone)
+		if err != nil {
+			return err
+		}
+		if wasDone != input.IsDone {
+			// Повторная запись stage_id запускает триггер, который пересчитывает completed_at
+			if _, err := tx.Exec(ctx, `UPDATE task_task SET stage_id = stage_id WHERE stage_id = $1`, stageID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update stage: %w", err)
+	}
+	return nil
+}
+
+// ReorderStages sets the board order to the order of stageIDs.
+// Stages missing from stageIDs keep their relative order after the listed ones.
+func (db *DB) ReorderStages(ctx context.Context, projectID int, stageIDs []int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := lockProject(ctx, tx, projectID); err != nil {
+			return err
+		}
+		if err := checkProjectIDs(ctx, tx, "project_stages", "stage", projectID, stageIDs); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `
+			WITH listed AS (
+				SELECT stage_id, ord FROM unnest($2::int[]) WITH ORDINALITY AS u(stage_id, ord)
+			), ordered AS (
+				SELECT s.id,
+				       ROW_NUMBER() OVER (ORDER BY l.ord NULLS LAST, s.position, s.id) - 1 AS position
+				FROM project_stages s
+				LEFT JOIN listed l ON l.stage_id = s.id
+				WHERE s.project_id = $1
+			)
+			UPDATE project_stages s SET position = o.position
+			FROM ordered o
+			WHERE s.id = o.id`, projectID, stageIDs)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("project %d has no stages: %w", projectID, pgx.ErrNoRows)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reorder stages: %w", err)
+	}
+	return nil
+}
+
+// DeleteStage deletes a stage, first moving its tasks to moveTo.
+// moveTo may be zero only when the stage is empty.
+func (db *DB) DeleteStage(ctx context.Context, projectID, stageID, moveTo int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var stages, tasks int
+		err := tx.QueryRow(ctx, `
+			SELECT (SELECT COUNT(*) FROM project_stages WHERE project_id = s.project_id),
+			       (SELECT COUNT(*) FROM task_task WHERE stage_id = s.id)
+			FROM project_stages s
+			JOIN project_project p ON p.id = s.project_id
+			WHERE s.id = $1 AND s.project_id = $2 AND p.workspace_id IS NOT DISTINCT FROM $3`,
+			stageID, projectID, workspaceArg(ctx)).Scan(&stages, &tasks)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("stage %d not found: %w", stageID, err)
+			}
+			return err
+		}
+		if stages <= 1 {
+			return ErrLastStage
+		}
+
+		if tasks > 0 {
+			if moveTo == 0 {
+				return ErrStageNotEmpty
+			}
+			if moveTo == stageID {
+				return fmt.Errorf("cannot move tasks to the stage being deleted: %w", ErrStageNotEmpty)
+			}
+			target, err := stageProject(ctx, tx, moveTo)
+			if err != nil {
+				return err
+			}
+			if target != projectID {
+				return ErrStageMismatch
+			}
+			if err := reserveStage(ctx, tx, moveTo, tasks); err != nil {
+				return err
+			}
+			rows, err := tx.Query(ctx, `SELECT id FROM task_task WHERE stage_id = $1 ORDER BY rank, id`, stageID)
+			if err != nil {
+				return err
+			}
+			moved, err := pgx.CollectRows(rows, pgx.RowTo[int])
+			if err != nil {
+				return err
+			}
+			// По одной задаче, чтобы они встали в конец новой колонки в прежнем порядке
+			for _, taskID := range moved {
+				if _, err := tx.Exec(ctx, `UPDATE task_task SET stage_id = $2 WHERE id = $1`, taskID, moveTo); err != nil {
+					return err
+				}
+				err := recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskMoved, map[string]any{
+					"task_id":    taskID,
+					"from_stage": stageID,
+					"to_stage":   moveTo,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM project_stages WHERE id = $1`, stageID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE project_stages s SET position = o.position
+			FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY position, id) - 1 AS position
+			      FROM project_stages WHERE project_id = $1) o
+			WHERE s.id = o.id`, projectID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete stage: %w", err)
+	}
+	return nil
+}
+
+// MoveTaskToStage puts a task at the end of another stage of its project, respecting the stage's WIP limit
+func (db *DB) MoveTaskToStage(ctx context.Context, projectID, taskID, stageID int) error {
+	return db.MoveTask(ctx, projectID, taskID, TaskPlacement{StageID: stageID})
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// maxStatsDays limits the window of the daily series
+const maxStatsDays = 365
+
+// statsTasks leaves archived tasks and recurrence templates, whose occurrences are counted instead, out of the statistics
+const statsTasks = `t.archived_at IS NULL AND NOT EXISTS (SELECT 1 FROM task_recurrences r WHERE r.task_id = t.id)`
+
+// GetProjectStats aggregates the project's tasks in SQL; days is the window of the daily series
+func (db *DB) GetProjectStats(ctx context.Context, projectID, days int) (*projectmodel.ProjectStats, error) {
+	if days <= 0 || days > maxStatsDays {
+		days = 30
+	}
+	stats := projectmodel.ProjectStats{ProjectID: projectID, Days: days}
+
+	err := db.Pool.QueryRow(ctx, `
+		SELECT COUNT(t.id),
+		       COUNT(t.completed_at),
+		       AVG(EXTRACT(EPOCH FROM t.completed_at - t.created_at) / 3600),
+		       COUNT(t.id) FILTER (WHERE t.completed_at IS NULL AND t.deadline < NOW())
+		FROM project_project p
+		LEFT JOIN task_task t ON t.project_id = p.id AND `+statsTasks+`
+		WHERE p.id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2
+		GROUP BY p.id`, projectID, workspaceArg(ctx)).Scan(
+		&stats.TotalTasks, &stats.CompletedTasks, &stats.AvgCompletionHours, &stats.OverdueTasks,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("project not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to query project stats: %w", err)
+	}
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT s.id, s.title, COUNT(t.id), s.wip_limit
+		FROM project_stages s
+		LEFT JOIN task_task t ON t.stage_id = s.id AND `+statsTasks+`
+		WHERE s.project_id = $1
+		GROUP BY s.id
+		ORDER BY s.position, s.id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stage stats: %w", err)
+	}
+	stats.ByStage, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.StageTaskStats, error) {
+		var s projectmodel.StageTaskStats
+		err := row.Scan(&s.StageID, &s.Title, &s.Tasks, &s.WIPLimit)
+		return s, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan stage stats: %w", err)
+	}
+
+	rows, err = db.Pool.Query(ctx, `
+		SELECT t.priority, COUNT(*) FROM task_task t
+		WHERE t.project_id = $1 AND `+statsTasks+`
+		GROUP BY t.priority`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query priority stats: %w", err)
+	}
+	stats.ByPriority = make(map[string]int, len(projectmodel.Priorities))
+	for _, p := range projectmodel.Priorities {
+		stats.ByPriority[p] = 0
+	}
+	for rows.Next() {
+		var priority string
+		var n int
+		if err := rows.Scan(&priority, &n); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan priority stats: %w", err)
+		}
+		stats.ByPriority[priority] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read priority stats: %w", err)
+	}
+
+	rows, err = db.Pool.Query(ctx, `
+		SELECT u.id, u.username, COUNT(*), COUNT(t.completed_at),
+		       COUNT(*) FILTER (WHERE t.completed_at IS NULL AND t.deadline < NOW())
+		FROM task_assignees a
+		JOIN task_task t ON t.id = a.task_id
+		JOIN user_user u ON u.id = a.user_id
+		WHERE t.project_id = $1 AND `+statsTasks+`
+		GROUP BY u.id
+		ORDER BY COUNT(*) DESC, u.username`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assignee stats: %w", err)
+	}
+	stats.ByAssignee, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.AssigneeStats, error) {
+		var a projectmodel.AssigneeStats
+		err := row.Scan(&a.UserID, &a.Username, &a.Tasks, &a.Completed, &a.Overdue)
+		return a, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan assignee stats: %w", err)
+	}
+	err = db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM task_task t
+		WHERE t.project_id = $1 AND `+statsTasks+`
+		  AND NOT EXISTS (SELECT 1 FROM task_assignees a WHERE a.task_id = t.id)`,
+		projectID).Scan(&stats.UnassignedTasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unassigned tasks: %w", err)
+	}
+
+	rows, err = db.Pool.Query(ctx, `
+		WITH days AS (
+			SELECT generate_series(CURRENT_DATE - ($2::int - 1), CURRENT_DATE, INTERVAL '1 day')::date AS day
+		), created AS (
+			SELECT t.created_at::date AS day, COUNT(*) AS n
+			FROM task_task t
+			WHERE t.project_id = $1 AND t.created_at >= CURRENT_DATE - ($2::int - 1) AND `+statsTasks+`
+			GROUP BY 1
+		), completed AS (
+			SELECT t.completed_at::date AS day, COUNT(*) AS n
+			FROM task_task t
+			WHERE t.project_id = $1 AND t.completed_at >= CURRENT_DATE - ($2::int - 1) AND `+statsTasks+`
+			GROUP BY 1
+		)
+		SELECT to_char(d.day, 'YYYY-MM-DD'), COALESCE(c.n, 0), COALESCE(f.n, 0)
+		FROM days d
+		LEFT JOIN created c ON c.day = d.day
+		LEFT JOIN completed f ON f.day = d.day
+		ORDER BY d.day`, projectID, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats.Daily = []projectmodel.DailyTaskStats{}
+	for rows.Next() {
+		var d projectmodel.DailyTaskStats
+		if err := rows.Scan(&d.Date, &d.Created, &d.Completed); err != nil {
+			return nil, fmt.Errorf("failed to scan daily stats: %w", err)
+		}
+		stats.Daily = append(stats.Daily, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read daily stats: %w", err)
+	}
+	return &stats, nil
+}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+var (
+	// ErrSubtaskDepth is returned when a subtask would be nested deeper than the project allows
+	ErrSubtaskDepth = errors.New("subtask nesting is deeper than the project allows")
+	// ErrSubtaskCycle is returned when a task would become a subtask of itself or of its own subtask
+	ErrSubtaskCycle = errors.New("task cannot be nested under itself or its subtasks")
+	// ErrHasSubtasks is returned when a task with subtasks is deleted without choosing what happens to them
+	ErrHasSubtasks = errors.New("task has subtasks, choose to cascade or promote them")
+)
+
+// SubtaskMode decides what happens to the subtasks of a deleted task
+type SubtaskMode string
+
+const (
+	// SubtasksRefuse fails the deletion of a task that has subtasks
+	SubtasksRefuse SubtaskMode = ""
+	// SubtasksCascade deletes the whole subtree
+	SubtasksCascade SubtaskMode = "cascade"
+	// SubtasksPromote moves the direct subtasks one level up, to the deleted task's parent
+	SubtasksPromote SubtaskMode = "promote"
+)
+
+// lockTaskTree checks that the task belongs to the project and locks the project,
+// serializing changes of its task tree
+func lockTaskTree(ctx context.Context, tx pgx.Tx, projectID, taskID int) error {
+	if err := lockProject(ctx, tx, projectID); err != nil {
+		return err
+	}
+	return checkTask(ctx, tx, projectID, taskID)
+}
+
+// checkParent verifies that taskID (zero for a new task) can be nested under parentID:
+// the parent is in the same project, it is not in the task's own subtree,
+// and the deepest subtask stays within the project's max_subtask_depth.
+// The caller holds the project row lock, which serializes changes of the task tree.
+func checkParent(ctx context.Context, tx pgx.Tx, projectID, taskID, parentID int) error {
+	var parentProject int
+	err := tx.QueryRow(ctx, `SELECT project_id FROM task_task WHERE id = $1`, parentID).Scan(&parentProject)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("parent task %d not found: %w", parentID, err)
+	}
+	if err != nil {
+		return err
+	}
+	if parentProject != projectID {
+		return fmt.Errorf("%w: parent task %d is in another project", ErrInvalidTask, parentID)
+	}
+
+	// Число предков вместе с родителем — глубина задачи после переноса
+	var (
+		depth int
+		cycle bool
+	)
+	err = tx.QueryRow(ctx, `
+		WITH RECURSIVE up AS (
+			SELECT id, parent_id, 1 AS depth FROM task_task WHERE id = $1
+			UNION ALL
+			SELECT t.id, t.parent_id, up.depth + 1 FROM task_task t JOIN up ON t.id = up.parent_id
+		)
+		SELECT MAX(depth), COALESCE(bool_or(id = $2), FALSE) FROM up`, parentID, taskID).Scan(&depth, &cycle)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return ErrSubtaskCycle
+	}
+
+	height := 0
+	if taskID != 0 {
+		err = tx.QueryRow(ctx, `
+			WITH RECURSIVE down AS (
+				SELECT id, 0 AS height FROM task_task WHERE id = $1
+				UNION ALL
+				SELECT t.id, down.height + 1 FROM task_task t JOIN down ON t.parent_id = down.id
+			)
+			SELECT MAX(height) FROM down`, taskID).Scan(&height)
+		if err != nil {
+			return err
+		}
+	}
+
+	var maxDepth int
+	if err := tx.QueryRow(ctx, `SELECT max_subtask_depth FROM project_project WHERE id = $1`, projectID).Scan(&maxDepth); err != nil {
+		return err
+	}
+	if depth+height > maxDepth {
+		return fmt.Errorf("%w: %d levels, the limit is %d", ErrSubtaskDepth, depth+height, maxDepth)
+	}
+	return nil
+}
+
+// SetTaskParent makes a task a subtask of parentID, or a top-level task when parentID is nil
+func (db *DB) SetTaskParent(ctx context.Context, projectID, taskID int, parentID *int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := lockTaskTree(ctx, tx, projectID, taskID); err != nil {
+			return err
+		}
+		if parentID != nil {
+			if *parentID == taskID {
+				return ErrSubtaskCycle
+			}
+			if err := checkParent(ctx, tx, projectID, taskID, *parentID); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(ctx, `UPDATE task_task SET parent_id = $2 WHERE id = $1`, taskID, parentID); err != nil {
+			return err
+		}
+		return recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskUpdated, map[string]any{
+			"task_id":   taskID,
+			"fields":    []string{"parent_id"},
+			"parent_id": parentID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set task parent: %w", err)
+	}
+	return nil
+}
+
+// GetSubtasks returns the direct subtasks of a task in board order
+func (db *DB) GetSubtasks(ctx context.Context, taskID int) ([]projectmodel.Task, error) {
+	task, err := db.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetTasks(ctx, task.ProjectID, TaskFilter{ParentID: &taskID, Sort: []string{"rank"}})
+}
+
+// DeleteTaskWithSubtasks deletes a task, cascading to or promoting its subtasks as mode says
+func (db *DB) DeleteTaskWithSubtasks(ctx context.Context, projectID, id int, mode SubtaskMode) error {
+	switch mode {
+	case SubtasksRefuse, SubtasksCascade, SubtasksPromote:
+	default:
+		return fmt.Errorf("%w: unknown subtask mode %q", ErrInvalidTask, mode)
+	}
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		return deleteTask(ctx, tx, projectID, id, mode)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	return nil
+}
+
+func deleteTask(ctx context.Context, tx pgx.Tx, projectID, id int, mode SubtaskMode) error {
+	err := lockTaskTree(ctx, tx, projectID, id)
+	if err != nil {
+		return err
+	}
+	var subtasks, descendants int
+	err = tx.QueryRow(ctx, `
+		WITH RECURSIVE down AS (
+			SELECT id, 0 AS level FROM task_task WHERE parent_id = $1
+			UNION ALL
+			SELECT t.id, down.level + 1 FROM task_task t JOIN down ON t.parent_id = down.id
+		)
+		SELECT COUNT(*) FILTER (WHERE level = 0), COUNT(*) FROM down`, id).Scan(&subtasks, &descendants)
+	if err != nil {
+		return err
+	}
+	payload := map[string]any{"task_id": id}
+	if subtasks > 0 {
+		switch mode {
+		case SubtasksRefuse:
+			return ErrHasSubtasks
+		case SubtasksPromote:
+			_, err := tx.Exec(ctx, `
+				UPDATE task_task SET parent_id = (SELECT parent_id FROM task_task WHERE id = $1)
+				WHERE parent_id = $1`, id)
+			if err != nil {
+				return err
+			}
+			payload["promoted_subtasks"] = subtasks
+		case SubtasksCascade:
+			payload["deleted_subtasks"] = descendants
+		}
+	}
+
+	var title string
+	if err := tx.QueryRow(ctx, `DELETE FROM task_task WHERE id = $1 RETURNING title`, id).Scan(&title); err != nil {
+		return err
+	}
+	payload["title"] = title
+	return recordActivity(ctx, tx, projectID, projectmodel.ActivityTaskDeleted, payload)
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+const (
+	// maxStatusLen limits the free-form task status
+	maxStatusLen = 64
+	// maxStoryPoints limits a task estimate
+	maxStoryPoints = 1000
+)
+
+// ErrInvalidTask is returned when task fields or task list parameters fail validation
+var ErrInvalidTask = errors.New("invalid task")
+
+// Optional is a patch value: Set tells "change to Value" apart from "leave as is".
+// Decoded from JSON, a present key sets it, including an explicit null.
+type Optional[T any] struct {
+	Set   bool
+	Value T
+}
+
+// Set returns an Optional that changes the field to v
+func Set[T any](v T) Optional[T] {
+	return Optional[T]{Set: true, Value: v}
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+// TaskInput holds the fields of a new task
+type TaskInput struct {
+	Title           string
+	Description     string
+	FullDescription string
+	Status          string
+	Priority        string // one of projectmodel.Priorities, medium by default
+	StartDate       *time.Time
+	Deadline        *time.Time
+	StageID         *int  // first stage of the project by default
+	ParentID        *int  // parent task when the task is a subtask
+	AssigneeIDs     []int // project members to assign on creation
+	StoryPoints     *int
+	SprintID        *int // planned or active sprint of the project, backlog when nil
+}
+
+func (in *TaskInput) validate() error {
+	in.Title = strings.TrimSpace(in.Title)
+	in.Status = strings.TrimSpace(in.Status)
+	if in.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidTask)
+	}
+	if utf8.RuneCountInString(in.Status) > maxStatusLen {
+		return fmt.Errorf("%w: status is longer than %d characters", ErrInvalidTask, maxStatusLen)
+	}
+	if !slices.Contains(projectmodel.Priorities, in.Priority) {
+		return fmt.Errorf("%w: priority must be one of %s", ErrInvalidTask, strings.Join(projectmodel.Priorities, ", "))
+	}
+	if in.StartDate != nil && in.Deadline != nil && in.StartDate.After(*in.Deadline) {
+		return fmt.Errorf("%w: start_date is after deadline", ErrInvalidTask)
+	}
+	if in.StoryPoints != nil && (*in.StoryPoints < 0 || *in.StoryPoints > maxStoryPoints) {
+		return fmt.Errorf("%w: story_points must be 0-%d", ErrInvalidTask, maxStoryPoints)
+	}
+	return nil
+}
+
+// TaskPatch lists task field changes; unset fields are left as they are
+type TaskPatch struct {
+	Title           Optional[string]     `json:"title"`
+	Description     Optional[string]     `json:"description"`
+	FullDescription Optional[string]     `json:"full_description"`
+	Status          Optional[string]     `json:"status"`
+	Priority        Optional[string]     `json:"priority"`
+	StartDate       Optional[*time.Time] `json:"start_date"`
+	Deadline        Optional[*time.Time] `json:"deadline"`
+	StoryPoints     Optional[*int]       `json:"story_points"`
+	SprintID        Optional[*int]       `json:"sprint_id"`
+}
+
+// apply returns in with the patch's set fields replaced
+func (p TaskPatch) apply(in TaskInput) TaskInput {
+	if p.Title.Set {
+		in.Title = p.Title.Value
+	}
+	if p.Description.Set {
+		in.Description = p.Description.Value
+	}
+	if p.FullDescription.Set {
+		in.FullDescription = p.FullDescription.Value
+	}
+	if p.Status.Set {
+		in.Status = p.Status.Value
+	}
+	if p.Priority.Set {
+		in.Priority = p.Priority.Value
+	}
+	if p.StartDate.Set {
+		in.StartDate = p.StartDate.Value
+	}
+	if p.Deadline.Set {
+		in.Deadline = p.Deadline.Value
+	}
+	if p.StoryPoints.Set {
+		in.StoryPoints = p.StoryPoints.Value
+	}
+	if p.SprintID.Set {
+		in.SprintID = p.SprintID.Value
+	}
+	return in
+}
+
+// fields returns the JSON names of the fields set in the patch
+func (p TaskPatch) fields() []string {
+	var fields []string
+	for name, set := range map[string]bool{
+		"title":            p.Title.Set,
+		"description":      p.Description.Set,
+		"full_description": p.FullDescription.Set,
+		"status":           p.Status.Set,
+		"priority":         p.Priority.Set,
+		"start_date":       p.StartDate.Set,
+		"deadline":         p.Deadline.Set,
+		"story_points":     p.StoryPoints.Set,
+		"sprint_id":        p.SprintID.Set,
+	} {
+		if set {
+			fields = append(fields, name)
+		}
+	}
+	slices.Sort(fields)
+	return fields
+}
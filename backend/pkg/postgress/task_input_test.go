@@ -0,0 +1,51 @@
+package db_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskPatch(t *testing.T) {
+	deadline := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	base := db.TaskInput{
+		Title:       "Вёрстка",
+		Description: "Главная",
+		Priority:    projectmodel.PriorityMedium,
+		Deadline:    &deadline,
+		StoryPoints: ptr(3),
+		SprintID:    ptr(7),
+	}
+	tests := []struct {
+		name   string
+		body   string
+		want   func(in *db.TaskInput)
+		fields []string
+	}{
+		{"empty", `{}`, func(*db.TaskInput) {}, nil},
+		{"title", `{"title": "Вёрстка подвала"}`, func(in *db.TaskInput) { in.Title = "Вёрстка подвала" }, []string{"title"}},
+		{"explicit null clears", `{"deadline": null, "story_points": null, "sprint_id": null}`, func(in *db.TaskInput) {
+			in.Deadline, in.StoryPoints, in.SprintID = nil, nil, nil
+		}, []string{"deadline", "sprint_id", "story_points"}},
+		{"empty string is set", `{"description": ""}`, func(in *db.TaskInput) { in.Description = "" }, []string{"description"}},
+		{"several fields", `{"priority": "high", "status": "review", "story_points": 5}`, func(in *db.TaskInput) {
+			in.Priority, in.Status, in.StoryPoints = projectmodel.PriorityHigh, "review", ptr(5)
+		}, []string{"priority", "status", "story_points"}},
+		{"unknown field ignored", `{"assignee_ids": [1]}`, func(*db.TaskInput) {}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch db.TaskPatch
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &patch))
+
+			want := base
+			tt.want(&want)
+			require.Equal(t, want, db.ApplyTaskPatch(patch, base))
+			require.Equal(t, tt.fields, db.TaskPatchFields(patch))
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+// ErrNotTemplate is returned when a project used as a template is not marked as one
+var ErrNotTemplate = errors.New("project is not a template")
+
+// CloneOptions controls what CloneProject copies from the source project
+type CloneOptions struct {
+	Title        string // title of the copy; defaults to "<source title> (copy)"
+	UserID       int    // owner of the copy; defaults to the source owner
+	IncludeTasks bool   // copy tasks with fresh IDs and timestamps
+	IncludeFiles bool   // copy project attachments and, with IncludeTasks, task attachments
+	AsTemplate   bool   // mark the copy as a reusable template
+}
+
+// CloneProject deep-copies a project in a single transaction and returns the new project ID
+func (db *DB) CloneProject(ctx context.Context, srcID int, opts CloneOptions) (int, error) {
+	var newID int
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		id, err := cloneProject(ctx, tx, srcID, opts)
+		newID = id
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to clone project: %w", err)
+	}
+	return newID, nil
+}
+
+// CreateProjectFromTemplate creates a regular project from a template, including its tasks and files
+func (db *DB) CreateProjectFromTemplate(ctx context.Context, templateID int, title string, userID int) (int, error) {
+	var newID int
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var isTemplate bool
+		err := tx.QueryRow(ctx, `
+			SELECT is_template FROM project_project
+			WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $2`, templateID, workspaceArg(ctx)).Scan(&isTemplate)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("template not found: %w", err)
+			}
+			return err
+		}
+		if !isTemplate {
+			return ErrNotTemplate
+		}
+		newID, err = cloneProject(ctx, tx, templateID, CloneOptions{
+			Title:        title,
+			UserID:       userID,
+			IncludeTasks: true,
+			IncludeFiles: true,
+		})
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create project from template: %w", err)
+	}
+	return newID, nil
+}
+
+// ListTemplates returns the templates of the active workspace the user is a member of
+func (db *DB) ListTemplates(ctx context.Context, userID int) ([]projectmodel.Project, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT p.id, p.title, p.user_id, p.workspace_id
+		FROM project_project p
+		JOIN project_members m ON m.project_id = p.id AND m.user_id = $2
+		WHERE p.is_template AND p.workspace_id IS NOT DISTINCT FROM $1
+		ORDER BY p.title`, workspaceArg(ctx), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []projectmodel.Project{}
+	for rows.Next() {
+		var p projectmodel.Project
+		if err := rows.Scan(&p.ID, &p.Title, &p.UserID, &p.WorkspaceID); err != nil {
+			return nil, fmt.Errorf("failed to scan template: %w", err)
+		}
+		templates = append(templates, p)
+	}
+	return templates, rows.Err()
+}
+
+// DeleteTemplate deletes a template; regular projects are left untouched
+func (db *DB) DeleteTemplate(ctx context.Context, id int) error {
+	tag, err := db.Pool.Exec(ctx, `
+		DELETE FROM project_project
+		WHERE id = $1 AND is_template AND workspace_id IS NOT DISTINCT FROM $2`, id, workspaceArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("template with id %d not found", id)
+	}
+	return nil
+}
+
+// cloneProject copies the project row and the requested parts of it inside tx.
+// Both the source and the copy belong to the active workspace.
+func cloneProject(ctx context.Context, tx pgx.Tx, srcID int, opts CloneOptions) (int, error) {
+	var title string
+	var userID int
+	err := tx.QueryRow(ctx, `
+		SELECT title, user_id FROM project_project
+		WHERE id = $1 AND workspace_id IS NOT DISTINCT FROM $2`, srcID, workspaceArg(ctx)).Scan(&title, &userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("project not found: %w", err)
+		}
+		return 0, err
+	}
+	if opts.Title == "" {
+		opts.Title = title + " (copy)"
+	}
+	if opts.UserID <= 0 {
+		opts.UserID = userID
+	}
+
+	var newID int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO project_project (title, user_id, is_template, workspace_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`, opts.Title, opts.UserID, opts.AsTemplate, workspaceArg(ctx)).Scan(&newID)
+	if err != nil {
+		return 0, err
+	}
+	if err := addProjectMember(ctx, tx, newID, opts.UserID, projectmodel.RoleOwner); err != nil {
+		return 0, err
+	}
+	if _, err := assignProjectKey(ctx, tx, newID, DeriveProjectKey(opts.Title), false); err != nil {
+		return 0, err
+	}
+	stages, err := cloneStages(ctx, tx, srcID, newID)
+	if err != nil {
+		return 0, err
+	}
+	labels, err := cloneLabels(ctx, tx, srcID, newID)
+	if err != nil {
+		return 0, err
+	}
+
+	if opts.IncludeFiles {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO project_files (project_id, file)
+			SELECT $2, file FROM project_files WHERE project_id = $1`, srcID, newID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if opts.IncludeTasks {
+		tasks, err := cloneTasks(ctx, tx, srcID, newID, stages, opts.IncludeFiles)
+		if err != nil {
+			return 0, err
+		}
+		if err := cloneTaskLabels(ctx, tx, tasks, labels); err != nil {
+			return 0, err
+		}
+		if err := cloneTaskTree(ctx, tx, newID, tasks); err != nil {
+			return 0, err
+		}
+	}
+
+	err = recordActivity(ctx, tx, newID, projectmodel.ActivityProjectCreated, map[string]any{
+		"title":       opts.Title,
+		"cloned_from": srcID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return newID, nil
+}
+
+// cloneStages copies the board stages of srcID into dstID and returns the old-to-new stage ID mapping
+func cloneStages(ctx context.Context, tx pgx.Tx, srcID, dstID int) (map[int]int, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT id, title, wip_limit, is_done
+		FROM project_stages WHERE project_id = $1 ORDER BY position, id`, srcID)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		srcIDs []int
+		stages []StageInput
+	)
+	for rows.Next() {
+		var (
+			id int
+			s  StageInput
+		)
+		if err := rows.Scan(&id, &s.Title, &s.WIPLimit, &s.IsDone); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		srcIDs = append(srcIDs, id)
+		stages = append(stages, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(stages) == 0 {
+		stages = defaultStages
+	}
+
+	newIDs, err := createStages(ctx, tx, dstID, stages)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int]int, len(srcIDs))
+	for i, id := range srcIDs {
+		ids[id] = newIDs[i]
+	}
+	return ids, nil
+}
+
+// cloneLabels copies the label catalog of srcID into dstID and returns the old-to-new label ID mapping
+func cloneLabels(ctx context.Context, tx pgx.Tx, srcID, dstID int) (map[int]int, error) {
+	rows, err := tx.Query(ctx, `
+		INSERT INTO project_labels (project_id, name, color)
+		SELECT $2, name, color FROM project_labels WHERE project_id = $1
+		RETURNING id, (SELECT id FROM project_labels s WHERE s.project_id = $1 AND lower(s.name) = lower(project_labels.name))`,
+		srcID, dstID)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[int]int{}
+	for rows.Next() {
+		var newID, oldID int
+		if err := rows.Scan(&newID, &oldID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids[oldID] = newID
+	}
+	rows.Close()
+	return ids, rows.Err()
+}
+
+// cloneTaskLabels links the copied tasks to the copied labels using the old-to-new ID mappings
+func cloneTaskLabels(ctx context.Context, tx pgx.Tx, tasks, labels map[int]int) error {
+	var oldTasks, newTasks, oldLabels, newLabels []int
+	for oldID, newID := range tasks {
+		oldTasks, newTasks = append(oldTasks, oldID), append(newTasks, newID)
+	}
+	for oldID, newID := range labels {
+		oldLabels, newLabels = append(oldLabels, oldID), append(newLabels, newID)
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO task_labels (task_id, label_id)
+		SELECT t.new_id, l.new_id
+		FROM task_labels tl
+		JOIN unnest($1::int[], $2::int[]) AS t(old_id, new_id) ON t.old_id = tl.task_id
+		JOIN unnest($3::int[], $4::int[]) AS l(old_id, new_id) ON l.old_id = tl.label_id`,
+		oldTasks, newTasks, oldLabels, newLabels)
+	return err
+}
+
+// cloneTaskTree restores the subtask links and copies the checklists of the copied tasks.
+// Checklist assignees are kept only when they are members of dstID.
+func cloneTaskTree(ctx context.Context, tx pgx.Tx, dstID int, tasks map[int]int) error {
+	var oldIDs, newIDs []int
+	for oldID, newID := range tasks {
+		oldIDs, newIDs = append(oldIDs, oldID), append(newIDs, newID)
+	}
+	_, err := tx.Exec(ctx, `
+		WITH ids AS (SELECT * FROM unnest($1::int[], $2::int[]) AS m(old_id, new_id))
+		UPDATE task_task t SET parent_id = parent.new_id
+		FROM task_task src
+		JOIN ids self ON self.old_id = src.id
+		JOIN ids parent ON parent.old_id = src.parent_id
+		WHERE t.id = self.new_id`, oldIDs, newIDs)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_checklist_items (task_id, text, done, position, assignee_id, done_at)
+		SELECT m.new_id, i.text, i.done, i.position,
+		       (SELECT user_id FROM project_members WHERE project_id = $3 AND user_id = i.assignee_id), i.done_at
+		FROM task_checklist_items i
+		JOIN unnest($1::int[], $2::int[]) AS m(old_id, new_id) ON m.old_id = i.task_id
+		ORDER BY i.task_id, i.position, i.id`, oldIDs, newIDs, dstID)
+	return err
+}
+
+// cloneTasks copies every task of srcID into dstID and returns the old-to-new task ID mapping.
+// stages maps the source stages to the stages of dstID.
+func cloneTasks(ctx context.Context, tx pgx.Tx, srcID, dstID int, stages map[int]int, withFiles bool) (map[int]int, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT id, stage_id, title, description, full_description, status, priority
+		FROM task_task WHERE project_id = $1 ORDER BY id`, srcID)
+	if err != nil {
+		return nil, err
+	}
+	type srcTask struct {
+		id                    int
+		stageID               *int
+		title, desc, fullDesc string
+		status, priority      string
+	}
+	var tasks []srcTask
+	for rows.Next() {
+		var t srcTask
+		if err := rows.Scan(&t.id, &t.stageID, &t.title, &t.desc, &t.fullDesc, &t.status, &t.priority); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make(map[int]int, len(tasks))
+	for _, t := range tasks {
+		var stageID *int
+		if t.stageID != nil {
+			if id, ok := stages[*t.stageID]; ok {
+				stageID = &id
+			}
+		}
+		var newID int
+		err := tx.QueryRow(ctx, `
+			INSERT INTO task_task (project_id, stage_id, title, description, full_description, status, priority, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+			RETURNING id`, dstID, stageID, t.title, t.desc, t.fullDesc, t.status, t.priority).Scan(&newID)
+		if err != nil {
+			return nil, err
+		}
+		ids[t.id] = newID
+
+		if withFiles {
+			_, err = tx.Exec(ctx, `
+				INSERT INTO task_files (task_id, file)
+				SELECT $2, file FROM task_files WHERE task_id = $1`, t.id, newID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,378 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	projectmodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/project_models"
+)
+
+const (
+	// maxWorklogMinutes limits a manually logged entry to one day
+	maxWorklogMinutes = 24 * 60
+	// maxWorklogNoteLen limits the note of a worklog
+	maxWorklogNoteLen = 1000
+)
+
+var (
+	// ErrInvalidWorklog is returned for a worklog with a duration, date or note out of range
+	ErrInvalidWorklog = errors.New("invalid worklog")
+	// ErrNotWorklogOwner is returned when someone other than its user changes a worklog
+	ErrNotWorklogOwner = errors.New("only the user who logged the time can change it")
+	// ErrTimerRunning is returned when a user starts a timer while another one is running
+	ErrTimerRunning = errors.New("a timer is already running, stop it first")
+	// ErrInvalidReport is returned for an unknown time report grouping
+	ErrInvalidReport = errors.New("invalid time report")
+)
+
+// WorklogInput holds the editable fields of a worklog
+type WorklogInput struct {
+	Minutes int
+	Date    time.Time // only the calendar date is used
+	Note    string
+}
+
+func (in *WorklogInput) validate() error {
+	if in.Minutes <= 0 || in.Minutes > maxWorklogMinutes {
+		return fmt.Errorf("%w: minutes must be 1-%d", ErrInvalidWorklog, maxWorklogMinutes)
+	}
+	if in.Date.IsZero() {
+		return fmt.Errorf("%w: date is required", ErrInvalidWorklog)
+	}
+	in.Note = strings.TrimSpace(in.Note)
+	if utf8.RuneCountInString(in.Note) > maxWorklogNoteLen {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrInvalidWorklog, maxWorklogNoteLen)
+	}
+	return nil
+}
+
+// GetWorklogs returns the time logged on a task, latest date first
+func (db *DB) GetWorklogs(ctx context.Context, taskID int) ([]projectmodel.Worklog, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT w.id, w.task_id, u.id, u.username, w.minutes, to_char(w.work_date, 'YYYY-MM-DD'),
+		       w.note, w.from_timer, w.created_at
+		FROM task_worklogs w
+		JOIN user_user u ON u.id = w.user_id
+		WHERE w.task_id = $1
+		ORDER BY w.work_date DESC, w.id DESC`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query worklogs: %w", err)
+	}
+	worklogs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.Worklog, error) {
+		var w projectmodel.Worklog
+		err := row.Scan(&w.ID, &w.TaskID, &w.User.ID, &w.User.Username, &w.Minutes, &w.Date,
+			&w.Note, &w.FromTimer, &w.CreatedAt)
+		return w, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan worklogs: %w", err)
+	}
+	return worklogs, nil
+}
+
+// addWorklog inserts a worklog of a project member
+func addWorklog(ctx context.Context, tx pgx.Tx, projectID, taskID, userID int, in WorklogInput, fromTimer bool) (int, error) {
+	if err := checkTask(ctx, tx, projectID, taskID); err != nil {
+		return 0, err
+	}
+	if err := checkMembers(ctx, tx, projectID, []int{userID}); err != nil {
+		return 0, err
+	}
+	var id int
+	err := tx.QueryRow(ctx, `
+		INSERT INTO task_worklogs (task_id, user_id, minutes, work_date, note, from_timer)
+		VALUES ($1, $2, $3, $4::date, $5, $6)
+		RETURNING id`, taskID, userID, in.Minutes, in.Date.Format(time.DateOnly), in.Note, fromTimer).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, recordActivity(ctx, tx, projectID, projectmodel.ActivityTimeLogged, map[string]any{
+		"task_id":    taskID,
+		"worklog_id": id,
+		"user_id":    userID,
+		"minutes":    in.Minutes,
+	})
+}
+
+// AddWorklog logs time the user spent on a task; the user must be a project member
+func (db *DB) AddWorklog(ctx context.Context, projectID, taskID, userID int, in WorklogInput) (int, error) {
+	if err := in.validate(); err != nil {
+		return 0, err
+	}
+	var id int
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var err error
+		id, err = addWorklog(ctx, tx, projectID, taskID, userID, in, false)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to log time: %w", err)
+	}
+	return id, nil
+}
+
+// lockWorklog locks a worklog of the project's task and checks that the user may change it
+func lockWorklog(ctx context.Context, tx pgx.Tx, projectID, taskID, worklogID, userID int, moderate bool) error {
+	var ownerID int
+	err := tx.QueryRow(ctx, `
+		SELECT w.user_id FROM task_worklogs w
+		JOIN task_task t ON t.id = w.task_id
+		JOIN project_project p ON p.id = t.project_id
+		WHERE w.id = $2 AND w.task_id = $1 AND t.project_id = $3 AND p.workspace_id IS NOT DISTINCT FROM $4
+		FOR UPDATE OF w`, taskID, worklogID, projectID, workspaceArg(ctx)).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("worklog %d not found: %w", worklogID, err)
+	}
+	if err != nil {
+		return err
+	}
+	if !moderate && ownerID != userID {
+		return ErrNotWorklogOwner
+	}
+	return nil
+}
+
+// UpdateWorklog replaces a worklog; unless moderate is set only its user may do that
+func (db *DB) UpdateWorklog(ctx context.Context, projectID, taskID, worklogID, userID int, moderate bool, in WorklogInput) error {
+	if err := in.validate(); err != nil {
+		return err
+	}
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := lockWorklog(ctx, tx, projectID, taskID, worklogID, userID, moderate); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			UPDATE task_worklogs SET minutes = $2, work_date = $3::date, note = $4
+			WHERE id = $1`, worklogID, in.Minutes, in.Date.Format(time.DateOnly), in.Note)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update worklog: %w", err)
+	}
+	return nil
+}
+
+// DeleteWorklog deletes a worklog; unless moderate is set only its user may do that
+func (db *DB) DeleteWorklog(ctx context.Context, projectID, taskID, worklogID, userID int, moderate bool) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := lockWorklog(ctx, tx, projectID, taskID, worklogID, userID, moderate); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM task_worklogs WHERE id = $1`, worklogID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete worklog: %w", err)
+	}
+	return nil
+}
+
+// GetTimer returns the user's running timer in the active workspace, or nil when none is running
+func (db *DB) GetTimer(ctx context.Context, userID int) (*projectmodel.Timer, error) {
+	var t projectmodel.Timer
+	err := db.Pool.QueryRow(ctx, `
+		SELECT t.id, t.project_id, COALESCE(p.key || '-' || t.number, ''), t.title, t.completed_at IS NOT NULL,
+		       tm.started_at
+		FROM task_timers tm
+		JOIN task_task t ON t.id = tm.task_id
+		JOIN project_project p ON p.id = t.project_id
+		WHERE tm.user_id = $1 AND p.workspace_id IS NOT DISTINCT FROM $2`, userID, workspaceArg(ctx)).Scan(&t.Task.ID, &t.Task.ProjectID, &t.Task.Key, &t.Task.Title, &t.Task.Done, &t.StartedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timer: %w", err)
+	}
+	return &t, nil
+}
+
+// StartTimer starts the user's timer on a task. A user has at most one running timer.
+func (db *DB) StartTimer(ctx context.Context, projectID, taskID, userID int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := checkTask(ctx, tx, projectID, taskID); err != nil {
+			return err
+		}
+		if err := checkMembers(ctx, tx, projectID, []int{userID}); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO task_timers (user_id, task_id) VALUES ($1, $2)
+			ON CONFLICT (user_id) DO NOTHING`, userID, taskID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrTimerRunning
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start timer: %w", err)
+	}
+	return nil
+}
+
+// StopTimer stops the user's running timer in the active workspace and logs the elapsed time, rounded up to whole minutes
+// and capped at a day, on the date the timer was started in loc. It returns the new worklog.
+func (db *DB) StopTimer(ctx context.Context, userID int, note string, loc *time.Location) (taskID, worklogID int, err error) {
+	err = db.beginFunc(ctx, func(tx pgx.Tx) error {
+		var startedAt, now time.Time
+		err := tx.QueryRow(ctx, `
+			DELETE FROM task_timers tm
+			USING task_task t, project_project p
+			WHERE tm.user_id = $1 AND t.id = tm.task_id AND p.id = t.project_id
+			  AND p.workspace_id IS NOT DISTINCT FROM $2
+			RETURNING tm.task_id, tm.started_at, NOW()`, userID, workspaceArg(ctx)).Scan(&taskID, &startedAt, &now)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no running timer: %w", err)
+		}
+		if err != nil {
+			return err
+		}
+		// Забытый таймер засчитывается не больше чем за сутки
+		minutes := int((now.Sub(startedAt) + time.Minute - 1) / time.Minute)
+		in := WorklogInput{
+			Minutes: min(max(minutes, 1), maxWorklogMinutes),
+			Date:    startedAt.In(loc),
+			Note:    note,
+		}
+		if err := in.validate(); err != nil {
+			return err
+		}
+		projectID, err := taskProject(ctx, tx, taskID)
+		if err != nil {
+			return err
+		}
+		worklogID, err = addWorklog(ctx, tx, projectID, taskID, userID, in, true)
+		return err
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to stop timer: %w", err)
+	}
+	return taskID, worklogID, nil
+}
+
+// CancelTimer discards the user's running timer in the active workspace without logging time
+func (db *DB) CancelTimer(ctx context.Context, userID int) error {
+	tag, err := db.Pool.Exec(ctx, `
+		DELETE FROM task_timers tm
+		USING task_task t, project_project p
+		WHERE tm.user_id = $1 AND t.id = tm.task_id AND p.id = t.project_id
+		  AND p.workspace_id IS NOT DISTINCT FROM $2`, userID, workspaceArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to cancel timer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("no running timer: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// timeReportGroups maps a report grouping to its selected columns, grouping keys and ordering
+var timeReportGroups = map[string]struct{ columns, groupBy, orderBy string }{
+	"user":    {"u.id, u.username", "u.id", "u.username"},
+	"project": {"p.id, p.title", "p.id", "p.title"},
+	"task":    {"t.id, COALESCE(p.key || '-' || t.number, ''), t.title", "t.id, p.id", "t.id"},
+	"week": {
+		"to_char(date_trunc('week', w.work_date), 'YYYY-MM-DD')",
+		"date_trunc('week', w.work_date)",
+		"date_trunc('week', w.work_date)",
+	},
+}
+
+// timeReportNulls stand in for the columns of a grouping that was not requested
+var timeReportNulls = map[string]string{
+	"user":    "NULL::int, ''",
+	"project": "NULL::int, ''",
+	"task":    "NULL::int, '', ''",
+	"week":    "''",
+}
+
+// TimeReportFilter selects the worklogs of a time report and how they are grouped
+type TimeReportFilter struct {
+	ProjectID *int       // only this project
+	MemberID  *int       // only projects this user is a member of
+	UserID    *int       // only time logged by this user
+	From      *time.Time // first included date
+	To        *time.Time // last included date
+	GroupBy   []string   // any of user, project, task, week, in order
+}
+
+// TimeReport sums logged time of the active workspace over the requested groups
+func (db *DB) TimeReport(ctx context.Context, f TimeReportFilter) ([]projectmodel.TimeReportRow, error) {
+	if len(f.GroupBy) == 0 {
+		return nil, fmt.Errorf("%w: group by at least one of user, project, task, week", ErrInvalidReport)
+	}
+	grouped := map[string]bool{}
+	var groupBy, orderBy []string
+	for _, g := range f.GroupBy {
+		group, ok := timeReportGroups[g]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown grouping %q", ErrInvalidReport, g)
+		}
+		if grouped[g] {
+			continue
+		}
+		grouped[g] = true
+		groupBy = append(groupBy, group.groupBy)
+		orderBy = append(orderBy, group.orderBy)
+	}
+	var columns []string
+	for _, g := range []string{"user", "project", "task", "week"} {
+		if grouped[g] {
+			columns = append(columns, timeReportGroups[g].columns)
+		} else {
+			columns = append(columns, timeReportNulls[g])
+		}
+	}
+
+	var from, to *string
+	if f.From != nil {
+		d := f.From.Format(time.DateOnly)
+		from = &d
+	}
+	if f.To != nil {
+		d := f.To.Format(time.DateOnly)
+		to = &d
+	}
+	query := `
+		SELECT ` + strings.Join(columns, ", ") + `, SUM(w.minutes)
+		FROM task_worklogs w
+		JOIN user_user u ON u.id = w.user_id
+		JOIN task_task t ON t.id = w.task_id
+		JOIN project_project p ON p.id = t.project_id
+		WHERE p.workspace_id IS NOT DISTINCT FROM @workspace
+		  AND (@project::int IS NULL OR p.id = @project)
+		  AND (@member::int IS NULL OR EXISTS (
+		      SELECT 1 FROM project_members m WHERE m.project_id = p.id AND m.user_id = @member))
+		  AND (@user::int IS NULL OR w.user_id = @user)
+		  AND (@from::date IS NULL OR w.work_date >= @from::date)
+		  AND (@to::date IS NULL OR w.work_date <= @to::date)
+		GROUP BY ` + strings.Join(groupBy, ", ") + `
+		ORDER BY ` + strings.Join(orderBy, ", ")
+	rows, err := db.Pool.Query(ctx, query, pgx.NamedArgs{
+		"workspace": workspaceArg(ctx),
+		"project":   f.ProjectID,
+		"member":    f.MemberID,
+		"user":      f.UserID,
+		"from":      from,
+		"to":        to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time report: %w", err)
+	}
+	report, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (projectmodel.TimeReportRow, error) {
+		var r projectmodel.TimeReportRow
+		err := row.Scan(&r.UserID, &r.Username, &r.ProjectID, &r.ProjectTitle, &r.TaskID, &r.TaskKey, &r.TaskTitle, &r.Week, &r.Minutes)
+		return r, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan time report: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,227 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	workspacemodel "github.com/nais2008/hackanet2025/backend/pkg/postgress/models/workspace_model"
+)
+
+// ErrLastAdmin is returned when a change would leave a workspace without admins
+var ErrLastAdmin = errors.New("workspace must keep at least one admin")
+
+type workspaceCtxKey struct{}
+
+// WithWorkspace returns a context whose project queries are scoped to the given workspace
+func WithWorkspace(ctx context.Context, workspaceID int) context.Context {
+	return context.WithValue(ctx, workspaceCtxKey{}, workspaceID)
+}
+
+// WorkspaceFromContext returns the active workspace stored by WithWorkspace
+func WorkspaceFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(workspaceCtxKey{}).(int)
+	return id, ok
+}
+
+// workspaceArg is the value compared with project_project.workspace_id.
+// Without an active workspace only projects outside of any workspace are visible.
+func workspaceArg(ctx context.Context) *int {
+	if id, ok := WorkspaceFromContext(ctx); ok {
+		return &id
+	}
+	return nil
+}
+
+// CreateWorkspace creates a workspace and makes ownerID its first admin
+func (db *DB) CreateWorkspace(ctx context.Context, title string, ownerID int) (int, error) {
+	var id int
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO workspace_workspace (title)
+			VALUES ($1)
+			RETURNING id`, title).Scan(&id); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO workspace_members (workspace_id, user_id, role)
+			VALUES ($1, $2, $3)`, id, ownerID, workspacemodel.RoleAdmin)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	return id, nil
+}
+
+// GetWorkspace retrieves a workspace by ID
+func (db *DB) GetWorkspace(ctx context.Context, id int) (*workspacemodel.Workspace, error) {
+	var ws workspacemodel.Workspace
+	err := db.Pool.QueryRow(ctx, `
+		SELECT id, title, created_at
+		FROM workspace_workspace WHERE id = $1`, id).Scan(&ws.ID, &ws.Title, &ws.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("workspace not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to query workspace: %w", err)
+	}
+	return &ws, nil
+}
+
+// GetUserWorkspaces lists the workspaces a user belongs to together with the user's role
+func (db *DB) GetUserWorkspaces(ctx context.Context, userID int) ([]workspacemodel.Workspace, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT w.id, w.title, w.created_at, m.role
+		FROM workspace_workspace w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.title`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := []workspacemodel.Workspace{}
+	for rows.Next() {
+		var ws workspacemodel.Workspace
+		if err := rows.Scan(&ws.ID, &ws.Title, &ws.CreatedAt, &ws.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		workspaces = append(workspaces, ws)
+	}
+	return workspaces, rows.Err()
+}
+
+// UpdateWorkspaceTitle renames a workspace
+func (db *DB) UpdateWorkspaceTitle(ctx context.Context, id int, title string) error {
+	tag, err := db.Pool.Exec(ctx, `UPDATE workspace_workspace SET title = $1 WHERE id = $2`, title, id)
+	if err != nil {
+		return fmt.Errorf("failed to update workspace: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("workspace with id %d not found", id)
+	}
+	return nil
+}
+
+// DeleteWorkspace deletes a workspace together with its projects
+func (db *DB) DeleteWorkspace(ctx context.Context, id int) error {
+	tag, err := db.Pool.Exec(ctx, `DELETE FROM workspace_workspace WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete workspace: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("workspace with id %d not found", id)
+	}
+	return nil
+}
+
+// WorkspaceRole returns the user's role in the workspace, or pgx.ErrNoRows if the user is not a member
+func (db *DB) WorkspaceRole(ctx context.Context, workspaceID, userID int) (string, error) {
+	var role string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT role FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("user %d is not a member of workspace %d: %w", userID, workspaceID, err)
+		}
+		return "", fmt.Errorf("failed to query workspace role: %w", err)
+	}
+	return role, nil
+}
+
+// GetWorkspaceMembers lists the members of a workspace
+func (db *DB) GetWorkspaceMembers(ctx context.Context, workspaceID int) ([]workspacemodel.Member, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT m.workspace_id, m.user_id, u.username, m.role, m.joined_at
+		FROM workspace_members m
+		JOIN user_user u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY u.username`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspace members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []workspacemodel.Member{}
+	for rows.Next() {
+		var m workspacemodel.Member
+		if err := rows.Scan(&m.WorkspaceID, &m.UserID, &m.Username, &m.Role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// SetWorkspaceMember adds a user to a workspace or changes the role of an existing member.
+// Demoting the last admin fails with ErrLastAdmin.
+func (db *DB) SetWorkspaceMember(ctx context.Context, workspaceID, userID int, role string) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if role != workspacemodel.RoleAdmin {
+			if err := checkLastAdmin(ctx, tx, workspaceID, userID); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO workspace_members (workspace_id, user_id, role)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role`, workspaceID, userID, role)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set workspace member: %w", err)
+	}
+	return nil
+}
+
+// RemoveWorkspaceMember removes a user from a workspace; removing the last admin fails with ErrLastAdmin
+func (db *DB) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID int) error {
+	err := db.beginFunc(ctx, func(tx pgx.Tx) error {
+		if err := checkLastAdmin(ctx, tx, workspaceID, userID); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `
+			DELETE FROM workspace_members
+			WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("user %d is not a member of workspace %d: %w", userID, workspaceID, pgx.ErrNoRows)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove workspace member: %w", err)
+	}
+	return nil
+}
+
+// checkLastAdmin locks the workspace so that concurrent role changes are serialized,
+// then fails with ErrLastAdmin if userID is its only admin
+func checkLastAdmin(ctx context.Context, tx pgx.Tx, workspaceID, userID int) error {
+	var id int
+	err := tx.QueryRow(ctx, `SELECT id FROM workspace_workspace WHERE id = $1 FOR UPDATE`, workspaceID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("workspace not found: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+	var lastAdmin bool
+	err = tx.QueryRow(ctx, `
+		SELECT bool_or(user_id = $2) AND count(*) = 1
+		FROM workspace_members
+		WHERE workspace_id = $1 AND role = $3`, workspaceID, userID, workspacemodel.RoleAdmin).Scan(&lastAdmin)
+	if err != nil {
+		return err
+	}
+	if lastAdmin {
+		return ErrLastAdmin
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Package rank orders items with lexicographic ranks: a rank between any two
+// neighbours can always be generated, so moving an item rewrites only that item.
+//
+// Ranks are strings over 0-9a-z compared bytewise (COLLATE "C" in PostgreSQL).
+// A rank never ends in '0', which keeps a free slot below every rank.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// ErrInvalid is returned for malformed ranks or bounds that are out of order
+var ErrInvalid = errors.New("invalid rank")
+
+// Valid reports whether r is a well-formed rank
+func Valid(r string) bool {
+	if r == "" || r[len(r)-1] == '0' {
+		return false
+	}
+	for i := 0; i < len(r); i++ {
+		if strings.IndexByte(digits, r[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Between returns a rank strictly between a and b.
+// An empty a means before the first item, an empty b means after the last one.
+func Between(a, b string) (string, error) {
+	if (a != "" && !Valid(a)) || (b != "" && !Valid(b)) {
+		return "", ErrInvalid
+	}
+	if a != "" && b != "" && a >= b {
+		return "", ErrInvalid
+	}
+	return midpoint(a, b), nil
+}
+
+// After returns a rank after a; After("") is the rank of the first item in an empty list
+func After(a string) (string, error) {
+	return Between(a, "")
+}
+
+// midpoint implements Between for valid, ordered bounds
+func midpoint(a, b string) string {
+	if b != "" {
+		// Общий префикс переносится как есть, середина ищется в остатке
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	lo := 0
+	if a != "" {
+		lo = strings.IndexByte(digits, a[0])
+	}
+	hi := base
+	if b != "" {
+		hi = strings.IndexByte(digits, b[0])
+	}
+	if hi-lo > 1 {
+		return string(digits[(lo+hi)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[lo]) + midpoint(rest, "")
+}
+
+// digitAt returns the i-th digit of r, treating missing digits as '0'
+func digitAt(r string, i int) byte {
+	if i < len(r) {
+		return r[i]
+	}
+	return digits[0]
+}
+
+// Spread returns n increasing ranks spaced evenly over the key space,
+// leaving room for insertions between and around them
+func Spread(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	width, space := 1, base
+	for space < (n+1)*base {
+		width++
+		space *= base
+	}
+	step := space / (n + 1)
+
+	ranks := make([]string, n)
+	buf := make([]byte, width)
+	for i := range ranks {
+		v := (i + 1) * step
+		for j := width - 1; j >= 0; j-- {
+			buf[j] = digits[v%base]
+			v /= base
+		}
+		ranks[i] = strings.TrimRight(string(buf), "0")
+	}
+	return ranks
+}
@@ -0,0 +1,87 @@
+package rank_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/nais2008/hackanet2025/backend/pkg/rank"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBetween(t *testing.T) {
+	cases := []struct{ a, b string }{
+		{"", ""},
+		{"", "1"},
+		{"i", ""},
+		{"z", ""},
+		{"zz", ""},
+		{"a", "b"},
+		{"a", "a1"},
+		{"a1", "a2"},
+		{"az", "b"},
+		{"1", "101"},
+	}
+	for _, c := range cases {
+		r, err := rank.Between(c.a, c.b)
+		require.NoError(t, err, "%q..%q", c.a, c.b)
+		require.True(t, rank.Valid(r), "%q..%q gave %q", c.a, c.b, r)
+		require.Greater(t, r, c.a)
+		if c.b != "" {
+			require.Less(t, r, c.b)
+		}
+	}
+}
+
+func TestBetweenInvalid(t *testing.T) {
+	for _, c := range []struct{ a, b string }{{"b", "a"}, {"a", "a"}, {"a0", ""}, {"A", ""}, {"", "-"}} {
+		_, err := rank.Between(c.a, c.b)
+		require.ErrorIs(t, err, rank.ErrInvalid, "%q..%q", c.a, c.b)
+	}
+}
+
+func TestRandomInsertsKeepOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ranks := []string{}
+	for i := 0; i < 2000; i++ {
+		pos := rng.Intn(len(ranks) + 1)
+		lo, hi := "", ""
+		if pos > 0 {
+			lo = ranks[pos-1]
+		}
+		if pos < len(ranks) {
+			hi = ranks[pos]
+		}
+		r, err := rank.Between(lo, hi)
+		require.NoError(t, err)
+		ranks = slices.Insert(ranks, pos, r)
+	}
+	require.True(t, slices.IsSorted(ranks))
+	require.Len(t, slices.Compact(slices.Clone(ranks)), len(ranks))
+}
+
+func TestAppendGrowsSlowly(t *testing.T) {
+	r := ""
+	for i := 0; i < 100; i++ {
+		next, err := rank.After(r)
+		require.NoError(t, err)
+		require.Greater(t, next, r)
+		r = next
+	}
+	require.LessOrEqual(t, len(r), 25)
+}
+
+func TestSpread(t *testing.T) {
+	require.Nil(t, rank.Spread(0))
+	for _, n := range []int{1, 5, 35, 36, 1000} {
+		ranks := rank.Spread(n)
+		require.Len(t, ranks, n)
+		require.True(t, slices.IsSorted(ranks))
+		for i, r := range ranks {
+			require.True(t, rank.Valid(r), r)
+			if i > 0 {
+				require.NotEqual(t, ranks[i-1], r)
+			}
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// Package rrule implements the subset of iCalendar recurrence rules (RFC 5545 RRULE)
+// used by recurring tasks: FREQ=DAILY, FREQ=WEEKLY with BYDAY and FREQ=MONTHLY with
+// BYMONTHDAY, each with INTERVAL and an optional COUNT or UNTIL.
+//
+// Occurrences keep the wall-clock time of the first one in its location, so a task
+// scheduled for 09:00 stays at 09:00 across daylight saving changes. As in RFC 5545,
+// days that do not exist in a month (BYMONTHDAY=31 in April) are skipped.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is how often a rule repeats
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+const (
+	// maxInterval limits INTERVAL
+	maxInterval = 1000
+	// maxCount limits COUNT
+	maxCount = 10000
+	// maxPeriods bounds the search for the next occurrence of rules that rarely or never match
+	maxPeriods = 50000
+)
+
+// ErrInvalid is returned for rules outside of the supported subset
+var ErrInvalid = errors.New("invalid recurrence rule")
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// Rule is a parsed recurrence rule
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday // WEEKLY only; the weekday of the first occurrence when empty
+	ByMonthDay []int          // MONTHLY only, 1..31 or -31..-1 from the end; the day of the first occurrence when empty
+	Count      int            // total number of occurrences, 0 for no limit
+	Until      time.Time      // last possible occurrence, zero for no limit
+	// UntilDate is set when UNTIL was a date: the whole day is included, in the location of the first occurrence
+	UntilDate bool
+}
+
+// Parse parses a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"; an "RRULE:" prefix is allowed
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	r := &Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalid, part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %s is repeated", ErrInvalid, key)
+		}
+		seen[key] = true
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			if r.Freq != Daily && r.Freq != Weekly && r.Freq != Monthly {
+				return nil, fmt.Errorf("%w: FREQ must be DAILY, WEEKLY or MONTHLY", ErrInvalid)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > maxInterval {
+				return nil, fmt.Errorf("%w: INTERVAL must be 1-%d", ErrInvalid, maxInterval)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > maxCount {
+				return nil, fmt.Errorf("%w: COUNT must be 1-%d", ErrInvalid, maxCount)
+			}
+			r.Count = n
+		case "UNTIL":
+			if t, err := time.Parse("20060102T150405Z", value); err == nil {
+				r.Until = t
+			} else if t, err := time.Parse("20060102", value); err == nil {
+				r.Until, r.UntilDate = t, true
+			} else {
+				return nil, fmt.Errorf("%w: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ", ErrInvalid)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				wd, ok := weekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("%w: unknown BYDAY %q", ErrInvalid, day)
+				}
+				if !slices.Contains(r.ByDay, wd) {
+					r.ByDay = append(r.ByDay, wd)
+				}
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("%w: BYMONTHDAY must be 1..31 or -31..-1", ErrInvalid)
+				}
+				if !slices.Contains(r.ByMonthDay, n) {
+					r.ByMonthDay = append(r.ByMonthDay, n)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s is not supported", ErrInvalid, key)
+		}
+	}
+	switch {
+	case r.Freq == "":
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalid)
+	case r.Count > 0 && !r.Until.IsZero():
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalid)
+	case len(r.ByDay) > 0 && r.Freq != Weekly:
+		return nil, fmt.Errorf("%w: BYDAY is only supported with FREQ=WEEKLY", ErrInvalid)
+	case len(r.ByMonthDay) > 0 && r.Freq != Monthly:
+		return nil, fmt.Errorf("%w: BYMONTHDAY is only supported with FREQ=MONTHLY", ErrInvalid)
+	}
+	slices.SortFunc(r.ByDay, func(a, b time.Weekday) int { return mondayIndex(a) - mondayIndex(b) })
+	return r, nil
+}
+
+// String returns the rule in its canonical form
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			days[i] = strings.ToUpper(wd.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if r.UntilDate {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// After returns the first occurrence strictly after t of the rule starting at start,
+// or false when the rule has no more occurrences
+func (r *Rule) After(start, t time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	r.each(start, func(o time.Time) bool {
+		if o.After(t) {
+			next, found = o, true
+			return false
+		}
+		return true
+	})
+	return next, found
+}
+
+// Next returns up to n occurrences after t, in order
+func (r *Rule) Next(start, t time.Time, n int) []time.Time {
+	var next []time.Time
+	if n <= 0 {
+		return next
+	}
+	r.each(start, func(o time.Time) bool {
+		if o.After(t) {
+			next = append(next, o)
+		}
+		return len(next) < n
+	})
+	return next
+}
+
+// Occurs reports whether o is an occurrence of the rule starting at start
+func (r *Rule) Occurs(start, o time.Time) bool {
+	found := false
+	r.each(start, func(c time.Time) bool {
+		found = c.Equal(o)
+		return c.Before(o)
+	})
+	return found
+}
+
+// each calls yield with the occurrences in order until it returns false or the rule ends
+func (r *Rule) each(start time.Time, yield func(time.Time) bool) {
+	loc := start.Location()
+	until := r.Until
+	if r.UntilDate {
+		until = time.Date(r.Until.Year(), r.Until.Month(), r.Until.Day()+1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+	}
+	clock := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, start.Hour(), start.Minute(), start.Second(), 0, loc)
+	}
+
+	count := 0
+	emit := func(o time.Time) bool {
+		if o.Before(start) {
+			return true
+		}
+		if !until.IsZero() && o.After(until) {
+			return false
+		}
+		count++
+		if !yield(o) {
+			return false
+		}
+		return r.Count == 0 || count < r.Count
+	}
+
+	for k := 0; k < maxPeriods; k++ {
+		switch r.Freq {
+		case Daily:
+			if !emit(clock(start.Year(), start.Month(), start.Day()+k*r.Interval)) {
+				return
+			}
+		case Weekly:
+			days := r.ByDay
+			if len(days) == 0 {
+				days = []time.Weekday{start.Weekday()}
+			}
+			monday := start.Day() - mondayIndex(start.Weekday()) + 7*k*r.Interval
+			for _, wd := range days {
+				if !emit(clock(start.Year(), start.Month(), monday+mondayIndex(wd))) {
+					return
+				}
+			}
+		case Monthly:
+			first := time.Date(start.Year(), start.Month()+time.Month(k*r.Interval), 1, 0, 0, 0, 0, loc)
+			for _, d := range monthDays(r.ByMonthDay, start.Day(), first) {
+				if !emit(clock(first.Year(), first.Month(), d)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// monthDays resolves the days of the month starting at first, in order, skipping days the month lacks
+func monthDays(byMonthDay []int, startDay int, first time.Time) []int {
+	if len(byMonthDay) == 0 {
+		byMonthDay = []int{startDay}
+	}
+	last := first.AddDate(0, 1, -1).Day()
+	var days []int
+	for _, d := range byMonthDay {
+		if d < 0 {
+			d = last + 1 + d
+		}
+		if d >= 1 && d <= last && !slices.Contains(days, d) {
+			days = append(days, d)
+		}
+	}
+	slices.Sort(days)
+	return days
+}
+
+// mondayIndex numbers weekdays from Monday, as weeks start on Monday (WKST=MO)
+func mondayIndex(wd time.Weekday) int {
+	return (int(wd) + 6) % 7
+}
@@ -0,0 +1,109 @@
+package rrule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nais2008/hackanet2025/backend/pkg/rrule"
+	"github.com/stretchr/testify/require"
+)
+
+func dates(ts []time.Time) []string {
+	out := make([]string, len(ts))
+	for i, t := range ts {
+		out[i] = t.Format("2006-01-02 15:04 Mon")
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct{ src, want string }{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"rrule:freq=daily;interval=1", "FREQ=DAILY"},
+		{"FREQ=WEEKLY;BYDAY=FR,MO,WE;INTERVAL=2", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR"},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1,15;COUNT=6", "FREQ=MONTHLY;BYMONTHDAY=-1,15;COUNT=6"},
+		{"FREQ=DAILY;UNTIL=20250601", "FREQ=DAILY;UNTIL=20250601"},
+		{"FREQ=DAILY;UNTIL=20250601T090000Z", "FREQ=DAILY;UNTIL=20250601T090000Z"},
+	}
+	for _, c := range cases {
+		r, err := rrule.Parse(c.src)
+		require.NoError(t, err, c.src)
+		require.Equal(t, c.want, r.String(), c.src)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20250601",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;UNTIL=tomorrow",
+	} {
+		_, err := rrule.Parse(src)
+		require.ErrorIs(t, err, rrule.ErrInvalid, src)
+	}
+}
+
+func TestDaily(t *testing.T) {
+	r, err := rrule.Parse("FREQ=DAILY;INTERVAL=2;COUNT=3")
+	require.NoError(t, err)
+	start := time.Date(2025, 5, 30, 9, 0, 0, 0, time.UTC)
+	require.Equal(t, []string{"2025-05-30 09:00 Fri", "2025-06-01 09:00 Sun", "2025-06-03 09:00 Tue"},
+		dates(r.Next(start, start.Add(-time.Second), 10)))
+
+	next, ok := r.After(start, start)
+	require.True(t, ok)
+	require.Equal(t, "2025-06-01 09:00 Sun", dates([]time.Time{next})[0])
+	_, ok = r.After(start, time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC))
+	require.False(t, ok)
+}
+
+func TestWeekly(t *testing.T) {
+	r, err := rrule.Parse("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH")
+	require.NoError(t, err)
+	// Первое вхождение — среда, поэтому понедельник этой недели пропускается
+	start := time.Date(2025, 5, 14, 10, 30, 0, 0, time.UTC)
+	require.Equal(t, []string{
+		"2025-05-15 10:30 Thu", "2025-05-26 10:30 Mon", "2025-05-29 10:30 Thu", "2025-06-09 10:30 Mon",
+	}, dates(r.Next(start, start, 4)))
+
+	r, err = rrule.Parse("FREQ=WEEKLY;UNTIL=20250528")
+	require.NoError(t, err)
+	require.Equal(t, []string{"2025-05-14 10:30 Wed", "2025-05-21 10:30 Wed", "2025-05-28 10:30 Wed"},
+		dates(r.Next(start, start.Add(-time.Second), 10)))
+}
+
+func TestMonthly(t *testing.T) {
+	r, err := rrule.Parse("FREQ=MONTHLY;BYMONTHDAY=31")
+	require.NoError(t, err)
+	start := time.Date(2025, 1, 31, 8, 0, 0, 0, time.UTC)
+	require.Equal(t, []string{"2025-01-31 08:00 Fri", "2025-03-31 08:00 Mon", "2025-05-31 08:00 Sat"},
+		dates(r.Next(start, start.Add(-time.Second), 3)))
+
+	r, err = rrule.Parse("FREQ=MONTHLY;BYMONTHDAY=1,-1")
+	require.NoError(t, err)
+	start = time.Date(2025, 2, 1, 8, 0, 0, 0, time.UTC)
+	require.Equal(t, []string{"2025-02-01 08:00 Sat", "2025-02-28 08:00 Fri", "2025-03-01 08:00 Sat"},
+		dates(r.Next(start, start.Add(-time.Second), 3)))
+	require.True(t, r.Occurs(start, time.Date(2025, 2, 28, 8, 0, 0, 0, time.UTC)))
+	require.False(t, r.Occurs(start, time.Date(2025, 2, 27, 8, 0, 0, 0, time.UTC)))
+}
+
+func TestKeepsWallClock(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	r, err := rrule.Parse("FREQ=DAILY")
+	require.NoError(t, err)
+	start := time.Date(2025, 3, 29, 9, 0, 0, 0, loc)
+	next := r.Next(start, start, 2)
+	require.Equal(t, []string{"2025-03-30 09:00 Sun", "2025-03-31 09:00 Mon"}, dates(next))
+	require.Equal(t, 23*time.Hour, next[0].Sub(start))
+}
@@ -0,0 +1,70 @@
+// Package search turns what a user types into PostgreSQL full-text queries and the
+// ts_headline snippets of the matches into safe HTML.
+//
+// Every term of the query matches as a prefix, so results appear while the last word
+// is still being typed. Highlighted fragments are delimited by private-use characters
+// that ts_headline inserts; Snippet escapes the text and only then turns them into
+// <mark> tags, so nothing from the indexed text reaches the HTML unescaped.
+package search
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxTerms limits the words of a query that are searched for
+	MaxTerms = 8
+	// maxTermLen limits the length of a searched word in characters
+	maxTermLen = 64
+
+	startSel = "\ue000"
+	stopSel  = "\ue001"
+)
+
+// TitleOptions are ts_headline options that mark every match in a short text such as a title
+const TitleOptions = "StartSel=" + startSel + ", StopSel=" + stopSel + ", HighlightAll=true"
+
+// SnippetOptions are ts_headline options that cut up to two fragments around the matches of a long text
+const SnippetOptions = "StartSel=" + startSel + ", StopSel=" + stopSel +
+	`, MaxWords=20, MinWords=8, ShortWord=2, MaxFragments=2, FragmentDelimiter=" … "`
+
+// Terms returns the distinct lowercase words of a query, at most MaxTerms of them.
+// Anything but letters and digits separates words, so the terms are safe inside a tsquery.
+func Terms(q string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if runes := []rune(word); len(runes) > maxTermLen {
+			word = string(runes[:maxTermLen])
+		}
+		if !slices.Contains(terms, word) {
+			terms = append(terms, word)
+		}
+		if len(terms) == MaxTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// PrefixQuery builds a to_tsquery expression matching documents that contain words starting
+// with every term of q, e.g. "отчёт:* & sql:*". It returns "" when q has no words.
+func PrefixQuery(q string) string {
+	terms := Terms(q)
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// Snippet converts a ts_headline result produced with TitleOptions or SnippetOptions to HTML:
+// the text is escaped, whitespace is collapsed and matches are wrapped in <mark>
+func Snippet(headline string) string {
+	s := html.EscapeString(strings.Join(strings.Fields(headline), " "))
+	s = strings.ReplaceAll(s, startSel, "<mark>")
+	return strings.ReplaceAll(s, stopSel, "</mark>")
+}
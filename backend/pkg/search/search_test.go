@@ -0,0 +1,39 @@
+package search_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nais2008/hackanet2025/backend/pkg/search"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixQuery(t *testing.T) {
+	cases := []struct{ q, want string }{
+		{"", ""},
+		{"  !!  ", ""},
+		{"Отчёт", "отчёт:*"},
+		{"отчёт по SQL-запросам", "отчёт:* & по:* & sql:* & запросам:*"},
+		{"bug bug BUG", "bug:*"},
+		{"a'b & c | !d:*", "a:* & b:* & c:* & d:*"},
+		{"HACK-42", "hack:* & 42:*"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, search.PrefixQuery(c.q), c.q)
+	}
+}
+
+func TestTermsLimits(t *testing.T) {
+	terms := search.Terms("a b c d e f g h i j")
+	require.Len(t, terms, search.MaxTerms)
+	require.Equal(t, "a", terms[0])
+
+	long := strings.Repeat("я", 100)
+	require.Equal(t, []string{strings.Repeat("я", 64)}, search.Terms(long))
+}
+
+func TestSnippet(t *testing.T) {
+	require.Equal(t, "Починить <mark>отчёт</mark> &lt;b&gt; и <mark>экспорт</mark>",
+		search.Snippet("Починить \ue000отчёт\ue001 <b>\n\n и  \ue000экспорт\ue001"))
+	require.Equal(t, "", search.Snippet(""))
+}
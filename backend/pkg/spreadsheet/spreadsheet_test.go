@@ -0,0 +1,97 @@
+package spreadsheet_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nais2008/hackanet2025/backend/pkg/spreadsheet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := spreadsheet.New("csv", &buf, "Tasks")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow([]string{"ID", "Название"}))
+	require.NoError(t, w.WriteRow([]string{"1", "a, \"b\""}))
+	require.NoError(t, w.Close())
+
+	require.Equal(t, "\ufeffID,Название\n1,\"a, \"\"b\"\"\"\n", buf.String())
+}
+
+func TestCSVFormulaInjection(t *testing.T) {
+	tests := []struct {
+		cell, want string
+	}{
+		{"=1+2", "'=1+2"},
+		{"+7 999", "'+7 999"},
+		{"-5", "'-5"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "\"'\rcmd\""},
+		{"a=b", "a=b"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w, err := spreadsheet.NewCSV(&buf)
+		require.NoError(t, err)
+		require.NoError(t, w.WriteRow([]string{tt.cell}))
+		require.NoError(t, w.Close())
+		require.Equal(t, "\ufeff"+tt.want+"\n", buf.String(), tt.cell)
+	}
+}
+
+func TestXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := spreadsheet.New("xlsx", &buf, "Tasks & more")
+	require.NoError(t, err)
+
+	header := make([]string, 28)
+	for i := range header {
+		header[i] = "col"
+	}
+	require.NoError(t, w.WriteRow(header))
+	require.NoError(t, w.WriteRow([]string{"<tag> & \"quotes\"", "=HYPERLINK(\"http://x\")"}))
+	require.NoError(t, w.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(data)
+
+		// Every part must be well-formed XML
+		dec := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, f.Name)
+		}
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	require.Contains(t, sheet, `r="AB1"`)
+	require.Contains(t, sheet, `&lt;tag&gt; &amp; &#34;quotes&#34;`)
+	require.Equal(t, 28, strings.Count(sheet, `s="1"`))
+	require.Contains(t, sheet, `<c r="B2" t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(`)
+	require.NotContains(t, sheet, `<f>`)
+	require.Contains(t, files["xl/workbook.xml"], `name="Tasks &amp; more"`)
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := spreadsheet.New("ods", io.Discard, "Tasks")
+	require.Error(t, err)
+}
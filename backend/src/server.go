@@ -6,13 +6,24 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/nais2008/hackanet2025/backend/pkg/api"
+	"github.com/nais2008/hackanet2025/backend/pkg/events"
 	db "github.com/nais2008/hackanet2025/backend/pkg/postgress"
 	users "github.com/nais2008/hackanet2025/backend/pkg/users"
 )
 
+const (
+	// rankRebalanceInterval задаёт, как часто проверяются ранги задач
+	rankRebalanceInterval = 10 * time.Minute
+	// maxRankLength — длина ранга, после которой колонка перебалансируется
+	maxRankLength = 24
+	// recurrenceInterval задаёт, как часто создаются задачи по правилам повторения
+	recurrenceInterval = time.Minute
+)
+
 func main() {
 	// Загружаем переменные окружения из .env файла
 	err := godotenv.Load()
@@ -28,6 +39,34 @@ func main() {
 	}
 	defer dbInstance.Close()
 
+	// Применяем миграции схемы
+	if err := dbInstance.Migrate(ctx); err != nil {
+		log.Fatalf("Не удалось применить миграции: %v", err)
+	}
+
+	// Периодически выравниваем ранги задач, чтобы они не разрастались после многих перемещений
+	go runPeriodically(ctx, rankRebalanceInterval, func(ctx context.Context) error {
+		n, err := dbInstance.RebalanceRanks(ctx, maxRankLength)
+		if n > 0 {
+			log.Printf("Ранги задач выровнены в %d колонках", n)
+		}
+		return err
+	})
+
+	// Создаём повторяющиеся задачи; несколько экземпляров сервера не создадут одну задачу дважды
+	go runPeriodically(ctx, recurrenceInterval, func(ctx context.Context) error {
+		n, err := dbInstance.RunRecurrences(ctx)
+		if n > 0 {
+			log.Printf("Создано повторяющихся задач: %d", n)
+		}
+		return err
+	})
+
+	// Журналируем смену исполнителей и упоминания; другие подсистемы подписываются на dbInstance.Events так же
+	dbInstance.Events.Subscribe(func(_ context.Context, e events.Event) {
+		log.Printf("Задача %d: %s, пользователь %d", e.TaskID, e.Type, e.UserID)
+	}, events.TaskAssigned, events.TaskUnassigned, events.CommentMentioned)
+
 	usersDBInstance, err := users.New(ctx)
 	if err != nil {
 		log.Fatalf("Не удалось инициализировать базу данных пользователей: %v", err)
@@ -50,3 +89,19 @@ func main() {
 		log.Fatalf("Сервер не запустился: %v", err)
 	}
 }
+
+// runPeriodically calls job every interval until ctx is done, logging its errors
+func runPeriodically(ctx context.Context, interval time.Duration, job func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job(ctx); err != nil {
+				log.Printf("Фоновая задача завершилась с ошибкой: %v", err)
+			}
+		}
+	}
+}